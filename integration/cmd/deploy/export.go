@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/ethrx"
+)
+
+// runExport writes a point-in-time dump of every Ethrx token: export [flags]
+func runExport(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	address := fs.String("address", "", "Ethrx contract address")
+	block := fs.Uint64("block", 0, "block number to read at (default: latest)")
+	out := fs.String("out", "", "output directory (default: exports/<network>-<block>)")
+	chunkSize := fs.Int("chunk-size", 100, "initial tokens per batched call, halved on node limits")
+	workers := fs.Int("workers", 8, "concurrent RPC calls")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deploy export [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *address == "" || fs.NArg() != 0 {
+		exitUsage(fs)
+	}
+
+	cfg, logger := loadConfig()
+	client := dial(ctx, cfg, logger)
+
+	contract, err := utils.HexToFelt(*address)
+	if err != nil {
+		logger.Fatalf("❌ Invalid contract address: %s", err)
+	}
+
+	setLogField("contract", contract.String())
+	logger.Infof("📸 Exporting Ethrx %s", contract.String())
+	snapshot, err := ethrx.TakeSnapshot(ctx, client, contract, ethrx.SnapshotOptions{
+		Block:     *block,
+		ChunkSize: *chunkSize,
+		Workers:   *workers,
+		Logger:    logger,
+	})
+	if err != nil {
+		logger.Fatalf("❌ Export failed: %s", err)
+	}
+
+	dir := *out
+	if dir == "" {
+		dir = filepath.Join("exports", fmt.Sprintf("%s-%d", cfg.Network.Name, snapshot.BlockNumber))
+	}
+	manifest, err := ethrx.WriteExport(dir, cfg.Network.Name, snapshot)
+	if err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+
+	logger.Info("🎉 Export completed successfully!")
+	logger.Info("📋 Summary:")
+	logger.Infof("   Block: %d (%s)", manifest.BlockNumber, manifest.BlockHash)
+	logger.Infof("   Class Hash: %s", manifest.ClassHash)
+	logger.Infof("   Tokens: %d", manifest.Tokens)
+	logger.Infof("   Output: %s", dir)
+	printResult(exportResult{Output: dir, Manifest: manifest})
+}
+
+// exportResult is the JSON result of the export command
+type exportResult struct {
+	Output string `json:"output"`
+	*ethrx.Manifest
+}
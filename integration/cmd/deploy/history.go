@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/ethrx"
+)
+
+// runHistory lists the engravings, wipes and transfers of an Ethrx token:
+// history [flags] <token_id>
+func runHistory(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	address := fs.String("address", "", "Ethrx contract address")
+	fromBlock := fs.Uint64("from-block", 0, "first block scanned, e.g. the deployment block")
+	toBlock := fs.Uint64("to-block", 0, "last block scanned (default: latest)")
+	out := fs.String("out", "", "also write the history as JSON to this file")
+	workers := fs.Int("workers", 8, "concurrent RPC calls")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deploy history [flags] <token_id>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *address == "" || fs.NArg() != 1 {
+		exitUsage(fs)
+	}
+	tokenID, ok := new(big.Int).SetString(fs.Arg(0), 0)
+	if !ok || tokenID.Sign() <= 0 {
+		fail("Invalid token ID: %s", fs.Arg(0))
+	}
+
+	cfg, logger := loadConfig()
+	client := dial(ctx, cfg, logger)
+
+	contract, err := utils.HexToFelt(*address)
+	if err != nil {
+		logger.Fatalf("❌ Invalid contract address: %s", err)
+	}
+
+	setLogField("contract", contract.String())
+	logger.Infof("📜 Reading the history of token %s of Ethrx %s", tokenID, contract.String())
+	history, err := ethrx.ReadTokenHistory(ctx, client, contract, tokenID, ethrx.HistoryOptions{
+		FromBlock: *fromBlock,
+		ToBlock:   *toBlock,
+		Workers:   *workers,
+		Logger:    logger,
+		Metrics:   startMetrics(ctx, cfg, logger),
+	})
+	if err != nil {
+		logger.Fatalf("❌ Failed to read the token history: %s", err)
+	}
+
+	for _, entry := range history.Entries {
+		logger.Infof("📋 Block %d (%s), tx %s", entry.BlockNumber, entry.Timestamp.Format("2006-01-02 15:04:05 UTC"), entry.TxHash)
+		switch entry.Kind {
+		case ethrx.ChangeMint:
+			logger.Infof("   🪙 Minted to %s", entry.To)
+		case ethrx.ChangeWipe:
+			logger.Infof("   🧹 Artifact wiped by transfer %s -> %s", entry.From, entry.To)
+		case ethrx.ChangeTransfer:
+			logger.Infof("   📦 Transferred %s -> %s, artifact kept", entry.From, entry.To)
+		case ethrx.ChangeEngrave:
+			logger.Infof("   ✍️  %s engraved by %s", entry.Tag, entry.Caller)
+			for _, line := range strings.Split(strings.TrimSuffix(entry.Diff, "\n"), "\n") {
+				logger.Infof("      %s", line)
+			}
+		}
+	}
+
+	logger.Info("📋 Summary:")
+	logger.Infof("   Blocks: %d-%d", history.FromBlock, history.ToBlock)
+	logger.Infof("   Engravings: %d", history.Engravings)
+	logger.Infof("   Wipes: %d", history.Wipes)
+
+	if *out != "" {
+		if err := ethrx.WriteTokenHistory(*out, history); err != nil {
+			logger.Fatalf("❌ %s", err)
+		}
+		logger.Infof("📝 History written to %s", *out)
+	}
+	printResult(history)
+}
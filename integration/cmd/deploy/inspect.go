@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/ethrx"
+)
+
+// runInspect shows the owner and artifact of Ethrx tokens, optionally at a past block:
+// inspect [flags] <token_id>...
+func runInspect(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	address := fs.String("address", "", "Ethrx contract address")
+	atBlock := fs.Uint64("at-block", 0, "block number to read at (default: latest)")
+	fromBlock := fs.Uint64("from-block", 0, "first block scanned for events when the state at --at-block is pruned, e.g. the deployment block")
+	out := fs.String("out", "", "also write the tokens as JSON to this file")
+	workers := fs.Int("workers", 8, "concurrent RPC calls")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deploy inspect [flags] <token_id>...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *address == "" || fs.NArg() == 0 {
+		exitUsage(fs)
+	}
+
+	tokenIDs := make([]*big.Int, fs.NArg())
+	for i, arg := range fs.Args() {
+		id, ok := new(big.Int).SetString(arg, 0)
+		if !ok || id.Sign() <= 0 {
+			fail("Invalid token ID: %s", arg)
+		}
+		tokenIDs[i] = id
+	}
+
+	cfg, logger := loadConfig()
+	client := dial(ctx, cfg, logger)
+
+	contract, err := utils.HexToFelt(*address)
+	if err != nil {
+		logger.Fatalf("❌ Invalid contract address: %s", err)
+	}
+
+	setLogField("contract", contract.String())
+
+	block := *atBlock
+	if block == 0 {
+		if block, err = client.BlockNumber(ctx); err != nil {
+			logger.Fatalf("❌ Failed to get the latest block: %s", err)
+		}
+	}
+
+	logger.Infof("🔎 Inspecting %d tokens of Ethrx %s at block %d", len(tokenIDs), contract.String(), block)
+	tokens, err := ethrx.TokensAt(ctx, client, contract, tokenIDs, block, ethrx.AtBlockOptions{
+		FromBlock: *fromBlock,
+		Batch:     ethrx.BatchOptions{Workers: *workers},
+		Logger:    logger,
+		Metrics:   startMetrics(ctx, cfg, logger),
+	})
+	if err != nil {
+		logger.Fatalf("❌ Inspection failed: %s", err)
+	}
+
+	for _, token := range tokens {
+		logger.Infof("📋 Token %s (read from %s):", token.TokenID, token.Source)
+		logger.Infof("   Owner: %s", token.Owner)
+		logger.Infof("   Artifact ID: %s", token.ArtifactID)
+		for _, engraving := range token.Artifact {
+			text := engraving.Text()
+			if text == "" && len(engraving.Data) > 0 {
+				text = fmt.Sprintf("0x%x", engraving.Data)
+			}
+			logger.Infof("   %s (nonce %d): %q", engraving.Tag, token.TagNonces[engraving.Tag], text)
+		}
+	}
+
+	if *out != "" {
+		data, err := json.MarshalIndent(tokens, "", "  ")
+		if err != nil {
+			logger.Fatalf("❌ Failed to encode tokens: %s", err)
+		}
+		if err := os.WriteFile(*out, data, 0644); err != nil {
+			logger.Fatalf("❌ Failed to write %s: %s", *out, err)
+		}
+		logger.Infof("📝 Tokens written to %s", *out)
+	}
+	printResult(tokens)
+}
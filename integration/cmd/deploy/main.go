@@ -1,87 +1,268 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
 
+	"github.com/NethermindEth/starknet.go/client"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
 	"github.com/sirupsen/logrus"
 
 	"github.com/NovemberFork/etheracts/integration/pkg/config"
 	"github.com/NovemberFork/etheracts/integration/pkg/contracts"
 	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+	"github.com/NovemberFork/etheracts/integration/pkg/keystore"
+	"github.com/NovemberFork/etheracts/integration/pkg/metrics"
+	"github.com/NovemberFork/etheracts/integration/pkg/provider"
+	"github.com/NovemberFork/etheracts/integration/pkg/signer"
 )
 
 func main() {
+	// Determine the command (defaults to deploying a contract)
+	command, args := getCommand()
+
+	// Cancel in-flight RPC calls and receipt waits on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch command {
+	case "build":
+		runBuild(ctx, args)
+		return
+	case "sign":
+		runSign(ctx, args)
+		return
+	case "broadcast":
+		runBroadcast(ctx, args)
+		return
+	case "propose":
+		runPropose(ctx, args)
+		return
+	case "sign-proposal":
+		runSignProposal(ctx, args)
+		return
+	case "submit-proposal":
+		runSubmitProposal(ctx, args)
+		return
+	case "contracts":
+		listContracts()
+		return
+	case "export":
+		runExport(ctx, args)
+		return
+	case "inspect":
+		runInspect(ctx, args)
+		return
+	case "history":
+		runHistory(ctx, args)
+		return
+	case "report":
+		runReport(ctx, args)
+		return
+	case "watch":
+		runWatch(ctx, args)
+		return
+	case "migrate":
+		runMigrate(ctx, args)
+		return
+	}
+
+	if command != "plan" {
+		if _, ok := contracts.Lookup(command); !ok {
+			fail("Unknown contract type: %s (run `deploy contracts` to list them)", command)
+		}
+	}
+
+	cfg, logger := loadSignerConfig()
+
+	// Create the transaction signer (local key, encrypted keystore or external process)
+	txSigner, err := newSigner(cfg, logger)
+	if err != nil {
+		logger.Fatalf("❌ Failed to create signer: %s", err)
+	}
+	defer txSigner.Close()
+
+	pool := newPool(ctx, cfg, logger)
+	deployer := connectPool(ctx, cfg, pool, txSigner, logger)
+
+	switch command {
+	case "plan":
+		runPlan(ctx, deployer, cfg, logger, args)
+	default:
+		deployContract(ctx, deployer, cfg, logger, command, args)
+	}
+}
+
+// loadConfig loads and validates the configuration, then sets up logging
+func loadConfig() (*config.Config, *logrus.Logger) {
+	return readConfig(false)
+}
+
+// loadSignerConfig is loadConfig for commands that sign, which also require a deployer key
+// or external signer
+func loadSignerConfig() (*config.Config, *logrus.Logger) {
+	return readConfig(true)
+}
+
+func readConfig(signing bool) (*config.Config, *logrus.Logger) {
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		fmt.Printf("❌ Failed to load configuration: %s\n", err)
-		os.Exit(1)
+		fail("Failed to load configuration: %s", err)
 	}
 
 	// Validate configuration
 	if err := cfg.ValidateConfig(); err != nil {
-		fmt.Printf("❌ Configuration validation failed: %s\n", err)
-		os.Exit(1)
+		fail("Configuration validation failed: %s", err)
+	}
+	if signing {
+		if err := cfg.ValidateSigner(); err != nil {
+			fail("Configuration validation failed: %s", err)
+		}
 	}
 
 	// Setup logging
 	logger := setupLogger(cfg)
 	logger.Info("🚀 NovemberFork Deployment Tool")
-	logger.Info("================================")
+	if output == outputText {
+		logger.Info("================================")
+	}
 
 	// Print configuration summary
 	printConfigSummary(cfg, logger)
 
-	// Create deployer
+	return cfg, logger
+}
+
+// connect creates the deployer; txSigner may be nil for commands that never sign
+func connect(ctx context.Context, cfg *config.Config, txSigner signer.Signer, logger *logrus.Logger) *deploy.Deployer {
+	return connectPool(ctx, cfg, newPool(ctx, cfg, logger), txSigner, logger)
+}
+
+// connectPool creates the deployer on an existing endpoint pool
+func connectPool(ctx context.Context, cfg *config.Config, pool *provider.Pool, txSigner signer.Signer, logger *logrus.Logger) *deploy.Deployer {
 	deployer, err := deploy.NewDeployer(
-		cfg.GetRPCURL(),
+		ctx,
+		pool.URL(),
 		cfg.Network.Name,
+		cfg.GetChainID(),
 		cfg.Deployer.Address,
-		cfg.Deployer.PrivateKey,
-		cfg.Deployer.PublicKey,
-		logger,
+		deploy.WithSigner(txSigner),
+		deploy.WithLogger(logger),
+		deploy.WithHTTPClient(pool.HTTPClient()),
+		deploy.WithDeployDelay(cfg.Deployment.DeclarationDelay),
+		deploy.WithWaitOptions(waitOptions(cfg)),
+		deploy.WithNonceTracking(nonceStatePath(cfg), cfg.Deployment.MaxInFlight),
+		deploy.WithProgress(newProgressReporter(logger)),
+		deploy.WithMetrics(startMetrics(ctx, cfg, logger)),
 	)
 	if err != nil {
 		logger.Fatalf("❌ Failed to create deployer: %s", err)
 	}
 
-	logger.Info("✅ Connected to Starknet RPC")
+	logger.Info("✅ Connected to Starknet RPC (chain ID verified)")
+	return deployer
+}
 
-	// Determine which contract to deploy
-	contractType := getContractType()
+// dial creates an RPC client for read-only commands that need no deployer account
+func dial(ctx context.Context, cfg *config.Config, logger *logrus.Logger) *rpc.Provider {
+	return dialPool(ctx, cfg, newPool(ctx, cfg, logger), logger)
+}
 
-	switch contractType {
-	case "ethrx":
-		deployEthrx(deployer, cfg, logger)
-	default:
-		logger.Fatalf("❌ Unknown contract type: %s", contractType)
+// dialPool creates an RPC client on an existing endpoint pool
+func dialPool(ctx context.Context, cfg *config.Config, pool *provider.Pool, logger *logrus.Logger) *rpc.Provider {
+	rpcClient, err := rpc.NewProvider(pool.URL(), client.WithHTTPClient(pool.HTTPClient()))
+	if err != nil {
+		logger.Fatalf("❌ Error connecting to RPC provider: %s", err)
 	}
+	if err := deploy.VerifyChainID(ctx, rpcClient, cfg.Network.Name, cfg.GetChainID()); err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+
+	logger.Info("✅ Connected to Starknet RPC (chain ID verified)")
+	return rpcClient
 }
 
-func setupLogger(cfg *config.Config) *logrus.Logger {
-	logger := logrus.New()
-	logger.SetLevel(cfg.GetLogLevel())
+// newPool spreads requests over the configured endpoints with failover and rate limiting
+func newPool(ctx context.Context, cfg *config.Config, logger *logrus.Logger) *provider.Pool {
+	poolOpts := provider.DefaultOptions()
+	poolOpts.RequestsPerSecond = cfg.Network.RPCRateLimit
+	poolOpts.MaxRetries = cfg.Network.RPCMaxRetries
+	poolOpts.Metrics = startMetrics(ctx, cfg, logger)
+	pool, err := provider.NewPool(ctx, cfg.GetRPCURLs(), poolOpts, logger)
+	if err != nil {
+		logger.Fatalf("❌ Failed to set up RPC endpoints: %s", err)
+	}
+	go pool.Run(ctx)
+	return pool
+}
 
-	// Set formatter
-	if cfg.IsVerbose() {
-		logger.SetFormatter(&logrus.TextFormatter{
-			FullTimestamp: true,
-			ForceColors:   true,
-		})
-	} else {
-		logger.SetFormatter(&logrus.TextFormatter{
-			DisableTimestamp: true,
-			ForceColors:      true,
-		})
+var (
+	// runMetrics are the metrics of this run, served when METRICS_ADDR is set
+	runMetrics  *metrics.Metrics
+	metricsOnce sync.Once
+)
+
+// startMetrics serves the Prometheus metrics on the first call; it returns nil when they
+// are disabled
+func startMetrics(ctx context.Context, cfg *config.Config, logger *logrus.Logger) *metrics.Metrics {
+	metricsOnce.Do(func() {
+		if cfg.Logging.MetricsAddr == "" {
+			return
+		}
+		m := metrics.New()
+		addr, err := m.Serve(ctx, cfg.Logging.MetricsAddr)
+		if err != nil {
+			logger.Fatalf("❌ %s", err)
+		}
+		logger.Infof("📈 Serving metrics on http://%s/metrics", addr)
+		runMetrics = m
+	})
+	return runMetrics
+}
+
+// waitOptions builds the transaction waiting settings from the deployment configuration
+func waitOptions(cfg *config.Config) deploy.WaitOptions {
+	opts := deploy.DefaultWaitOptions()
+	opts.Timeout = cfg.Deployment.ReceiptTimeout
+	opts.InitialInterval = cfg.Deployment.PollInterval
+	opts.MaxInterval = cfg.Deployment.MaxPollInterval
+	if cfg.Deployment.WaitForL1 {
+		opts.Finality = rpc.TxnStatus_Accepted_On_L1
 	}
+	return opts
+}
 
-	return logger
+// nonceStatePath returns the file tracking in-flight transactions of the deployer account
+func nonceStatePath(cfg *config.Config) string {
+	return filepath.Join(cfg.Deployment.NonceStateDir, fmt.Sprintf("%s-%s.json", cfg.Network.Name, cfg.Deployer.Address))
+}
+
+// setupLogger creates the logger for the configured level and the --output format
+func setupLogger(cfg *config.Config) *logrus.Logger {
+	setLogField("network", cfg.Network.Name)
+	return newLogger(cfg.GetLogLevel(), cfg.IsVerbose())
+}
+
+// defaultLogger returns a logger for commands that run without configuration
+func defaultLogger() *logrus.Logger {
+	return newLogger(logrus.InfoLevel, false)
 }
 
 func printConfigSummary(cfg *config.Config, logger *logrus.Logger) {
 	logger.Infof("📋 Network: %s", cfg.Network.Name)
 	logger.Infof("📋 RPC URL: %s", cfg.Network.RPCURL)
+	if len(cfg.Network.FallbackRPCURLs) > 0 {
+		logger.Infof("📋 Fallback RPC URLs: %d", len(cfg.Network.FallbackRPCURLs))
+	}
+	logger.Infof("📋 Chain ID: %s", utils.HexToShortStr(cfg.Network.ChainID))
 	logger.Infof("📋 Account: %s", cfg.Deployer.Address)
 
 	if cfg.IsVerbose() {
@@ -99,26 +280,77 @@ func printConfigSummary(cfg *config.Config, logger *logrus.Logger) {
 	}
 }
 
-func getContractType() string {
-	if len(os.Args) < 2 {
-		return "ethrx" // default contract
+// newSigner creates the signer configured for the deployer account
+func newSigner(cfg *config.Config, logger *logrus.Logger) (signer.Signer, error) {
+	switch {
+	case cfg.Deployer.SignerSocket != "":
+		logger.Infof("🔏 Using external signer socket: %s", cfg.Deployer.SignerSocket)
+		return signer.NewSocketSigner(cfg.Deployer.PublicKey, cfg.Deployer.SignerSocket, cfg.Deployer.SignerTimeout)
+	case len(cfg.Deployer.SignerCommand) > 0:
+		logger.Infof("🔏 Using external signer command: %q", cfg.Deployer.SignerCommand)
+		return signer.NewCommandSigner(cfg.Deployer.PublicKey, cfg.Deployer.SignerCommand, cfg.Deployer.SignerTimeout)
+	case cfg.UsesKeystore():
+		logger.Infof("🔐 Unlocking keystore: %s", cfg.Deployer.KeystorePath)
+		privKey, err := keystore.Unlock(cfg.Deployer.KeystorePath, cfg.Deployer.PasswordFile)
+		if err != nil {
+			return nil, err
+		}
+		// Keystores only hold the private key, so the public key is derived when not configured
+		return signer.NewLocalSigner(privKey, cfg.Deployer.PublicKey)
+	case cfg.Deployer.PrivateKey != "":
+		return signer.NewLocalSignerFromHex(cfg.Deployer.PrivateKey, cfg.Deployer.PublicKey)
+	default:
+		return nil, cfg.ValidateSigner()
 	}
-	return os.Args[1]
 }
 
-func deployEthrx(deployer *deploy.Deployer, cfg *config.Config, logger *logrus.Logger) {
-	// Create Ethrx deployer
-	ethrxDeployer := contracts.NewEthrxDeployer(deployer, &cfg.Contracts.Ethrx, logger)
+// getCommand returns the command name and its arguments
+func getCommand() (string, []string) {
+	format, args, err := extractOutputFlag(os.Args[1:])
+	if err != nil {
+		fail("%s", err)
+	}
+	output = format
+	// Packages logging through the standard logger, such as config, follow --output too
+	configureLogger(logrus.StandardLogger(), logrus.InfoLevel, false)
+
+	commandName = "ethrx" // default contract
+	if len(args) == 0 {
+		return commandName, nil
+	}
+	commandName = args[0]
+	return args[0], args[1:]
+}
+
+// deployContract deploys a registered contract type. Arguments are key=value pairs
+// overriding its configuration:
+//
+//	deploy generic sierra_path=... casm_path=... owner=0x123 name=Token
+func deployContract(ctx context.Context, deployer *deploy.Deployer, cfg *config.Config, logger *logrus.Logger, name string, args []string) {
+	overrides := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			logger.Fatalf("❌ Invalid argument %q: expected key=value", arg)
+		}
+		overrides[key] = value
+	}
+
+	contractDeployer, err := contracts.NewRegisteredDeployer(deployer, cfg, name, overrides, logger)
+	if err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+	contractName := contractDeployer.GetContractName()
 
 	// Validate configuration
-	if err := ethrxDeployer.ValidateConfig(); err != nil {
-		logger.Fatalf("❌ Ethrx configuration validation failed: %s", err)
+	if err := contractDeployer.ValidateConfig(); err != nil {
+		logger.Fatalf("❌ %s configuration validation failed: %s", contractName, err)
 	}
 
 	// Deploy the contract
-	result, err := ethrxDeployer.Deploy()
+	result, err := contractDeployer.Deploy(ctx)
 	if err != nil {
-		logger.Fatalf("❌ Ethrx deployment failed: %s", err)
+		logger.Fatalf("❌ %s deployment failed: %s", contractName, err)
 	}
 
 	// Log deployment to history file
@@ -138,4 +370,41 @@ func deployEthrx(deployer *deploy.Deployer, cfg *config.Config, logger *logrus.L
 	logger.Infof("   Deployed Address: %s", result.DeployedAddress)
 	logger.Infof("   Transaction Hash: %s", result.TransactionHash)
 	logger.Infof("   Deployment Time: %s", result.DeploymentTime.Format("2006-01-02 15:04:05"))
+	printResult(result)
+}
+
+// contractType is a registered contract type in the JSON output of the contracts command
+type contractType struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Keys        []contracts.ConfigField `json:"keys"`
+}
+
+// listContracts prints the registered contract types and their configuration keys
+func listContracts() {
+	if output == outputJSON {
+		var types []contractType
+		for _, r := range contracts.Registered() {
+			keys := append([]contracts.ConfigField{}, r.Schema...)
+			keys = append(keys,
+				contracts.ConfigField{Key: contracts.KeySierraPath, Description: "sierra contract class file"},
+				contracts.ConfigField{Key: contracts.KeyCasmPath, Description: "compiled casm file"})
+			types = append(types, contractType{Name: r.Name, Description: r.Description, Keys: keys})
+		}
+		printResult(types)
+		return
+	}
+
+	for _, r := range contracts.Registered() {
+		fmt.Printf("%s\t%s\n", r.Name, r.Description)
+		for _, field := range r.Schema {
+			required := ""
+			if field.Required {
+				required = " (required)"
+			}
+			fmt.Printf("    %-14s %s%s\n", field.Key, field.Description, required)
+		}
+		fmt.Printf("    %-14s %s\n", contracts.KeySierraPath, "sierra contract class file")
+		fmt.Printf("    %-14s %s\n", contracts.KeyCasmPath, "compiled casm file")
+	}
 }
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/NethermindEth/starknet.go/utils"
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/abi"
+	"github.com/NovemberFork/etheracts/integration/pkg/config"
+	"github.com/NovemberFork/etheracts/integration/pkg/contracts"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+	"github.com/NovemberFork/etheracts/integration/pkg/ethrx"
+)
+
+// runMigrate replays an Ethrx snapshot into a newly deployed contract: migrate [flags]
+func runMigrate(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	snapshotPath := fs.String("snapshot", "", "snapshot.json written by export")
+	from := fs.String("from", "", "old Ethrx address to snapshot instead of --snapshot")
+	block := fs.Uint64("block", 0, "block to snapshot --from at (default: latest)")
+	to := fs.String("to", "", "existing new Ethrx address to migrate into or resume (default: deploy one)")
+	batch := fs.Int("batch", 25, "tokens per transaction")
+	transferOwnership := fs.Bool("transfer-ownership", false, "transfer the new contract to the snapshot owner")
+	report := fs.String("report", "migration-report.json", "reconciliation report file")
+	chunkSize := fs.Int("chunk-size", 100, "initial tokens per batched call, halved on node limits")
+	workers := fs.Int("workers", 8, "concurrent RPC calls")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deploy migrate (--snapshot <file> | --from <address>) [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if (*snapshotPath == "") == (*from == "") || fs.NArg() != 0 {
+		exitUsage(fs)
+	}
+
+	cfg, logger := loadSignerConfig()
+	ethrxABI, err := abi.Load(cfg.Contracts.Ethrx.SierraPath)
+	if err != nil {
+		logger.Fatalf("❌ Failed to load the Ethrx ABI: %s", err)
+	}
+
+	txSigner, err := newSigner(cfg, logger)
+	if err != nil {
+		logger.Fatalf("❌ Failed to create signer: %s", err)
+	}
+	defer txSigner.Close()
+
+	pool := newPool(ctx, cfg, logger)
+	deployer := connectPool(ctx, cfg, pool, txSigner, logger)
+	client := dialPool(ctx, cfg, pool, logger)
+
+	readOpts := ethrx.SnapshotOptions{ChunkSize: *chunkSize, Workers: *workers, Logger: logger}
+
+	var snapshot *ethrx.Snapshot
+	if *snapshotPath != "" {
+		snapshot, err = ethrx.LoadSnapshot(*snapshotPath)
+	} else {
+		source, parseErr := utils.HexToFelt(*from)
+		if parseErr != nil {
+			logger.Fatalf("❌ Invalid --from address: %s", parseErr)
+		}
+		logger.Infof("📸 Snapshotting Ethrx %s", source.String())
+		opts := readOpts
+		opts.Block = *block
+		snapshot, err = ethrx.TakeSnapshot(ctx, client, source, opts)
+	}
+	if err != nil {
+		logger.Fatalf("❌ Failed to load the snapshot: %s", err)
+	}
+	logger.Infof("📋 Snapshot of %s at block %d: %d tokens", snapshot.Contract, snapshot.BlockNumber, len(snapshot.Tokens))
+
+	target := *to
+	if target == "" {
+		target = deployMigrationTarget(ctx, deployer, cfg, logger, snapshot)
+	}
+	targetAddress, err := utils.HexToFelt(target)
+	if err != nil {
+		logger.Fatalf("❌ Invalid --to address: %s", err)
+	}
+
+	setLogField("contract", targetAddress.String())
+	logger.Infof("🔁 Migrating into %s", targetAddress.String())
+	result, err := ethrx.Migrate(ctx, client, deployer, snapshot, targetAddress, ethrx.MigrateOptions{
+		ABI:               ethrxABI,
+		BatchSize:         *batch,
+		TransferOwnership: *transferOwnership,
+		Read:              readOpts,
+	})
+	if err != nil {
+		logger.Fatalf("❌ Migration failed: %s (rerun with --to %s to resume)", err, targetAddress.String())
+	}
+
+	if err := ethrx.WriteReconciliation(*report, result); err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+
+	logger.Info("📋 Reconciliation:")
+	logger.Infof("   Target: %s", result.Target)
+	logger.Infof("   Tokens: %d (%d matched, %d mismatched)", result.Tokens, result.Matched, result.Mismatched)
+	logger.Infof("   Transactions: %d", len(result.Transactions))
+	logger.Infof("   Report: %s", *report)
+	for _, difference := range result.Settings {
+		logger.Warnf("⚠️  Setting differs: %s", difference)
+	}
+	for _, check := range result.Checks {
+		if check.Status != ethrx.StatusOK {
+			logger.Warnf("⚠️  Token %s: %v", check.TokenID, check.Differences)
+		}
+	}
+	if !result.OK() {
+		logger.Fatal("❌ Migrated collection does not match the snapshot")
+	}
+	logger.Info("🎉 Migration completed successfully!")
+	printResult(result)
+}
+
+// deployMigrationTarget deploys a new Ethrx owned by the deployer account, with the mint
+// token and max supply of the snapshot and free mints; Migrate restores the mint price
+func deployMigrationTarget(ctx context.Context, deployer *deploy.Deployer, cfg *config.Config, logger *logrus.Logger, snapshot *ethrx.Snapshot) string {
+	contractDeployer, err := contracts.NewRegisteredDeployer(deployer, cfg, "ethrx", map[string]string{
+		"owner":      deployer.GetAccountAddress(),
+		"mint_token": snapshot.MintToken,
+		"mint_price": "0",
+		"max_supply": snapshot.MaxSupply,
+	}, logger)
+	if err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+	if err := contractDeployer.ValidateConfig(); err != nil {
+		logger.Fatalf("❌ Ethrx configuration validation failed: %s", err)
+	}
+
+	result, err := contractDeployer.Deploy(ctx)
+	if err != nil {
+		logger.Fatalf("❌ Ethrx deployment failed: %s", err)
+	}
+	if err := deploy.NewDeploymentHistory().LogDeployment(result); err != nil {
+		logger.Warnf("⚠️  Failed to log deployment to history: %s", err)
+	} else {
+		logger.Info("📝 Deployment logged to history file")
+	}
+	return result.DeployedAddress
+}
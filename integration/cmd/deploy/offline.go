@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/abi"
+	"github.com/NovemberFork/etheracts/integration/pkg/contracts"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+	"github.com/NovemberFork/etheracts/integration/pkg/keystore"
+	"github.com/NovemberFork/etheracts/integration/pkg/signer"
+)
+
+// runBuild builds an unsigned transaction bundle: build <declare|deploy|invoke> [flags] [args...]
+func runBuild(ctx context.Context, args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "usage: deploy build <declare|deploy|invoke> [flags]")
+		if output == outputJSON {
+			fail("invalid arguments for build")
+		}
+		os.Exit(1)
+	}
+	kind, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("build "+kind, flag.ExitOnError)
+	out := fs.String("out", "bundle.json", "path of the unsigned bundle to write")
+	contractName := fs.String("contract", "ethrx", "contract to declare or deploy")
+	classHashFlag := fs.String("class-hash", "", "class hash to deploy (default: computed from the sierra file)")
+	address := fs.String("address", "", "Ethrx contract address for invoke bundles")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: deploy build %s [flags]", kind)
+		if kind == "invoke" {
+			fmt.Fprintf(fs.Output(), " <function> [args...]\n\nfunctions:\n  %s", strings.Join(contracts.EthrxAdminFunctions(), "\n  "))
+		}
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	cfg, logger := loadConfig()
+	deployer := connect(ctx, cfg, nil, logger)
+
+	if *address != "" {
+		setLogField("contract", *address)
+	}
+	if *contractName != "ethrx" {
+		logger.Fatalf("❌ Unknown contract type: %s", *contractName)
+	}
+	ethrxDeployer := contracts.NewEthrxDeployer(deployer, &cfg.Contracts.Ethrx, logger)
+
+	var bundle *deploy.TransactionBundle
+	var err error
+	switch kind {
+	case "declare":
+		bundle, err = deployer.BuildDeclareBundle(ctx, ethrxDeployer.GetContractName(), cfg.Contracts.Ethrx.SierraPath, cfg.Contracts.Ethrx.CasmPath)
+	case "deploy":
+		if err := ethrxDeployer.ValidateConfig(); err != nil {
+			logger.Fatalf("❌ Ethrx configuration validation failed: %s", err)
+		}
+		var classHash *felt.Felt
+		if *classHashFlag != "" {
+			classHash, err = utils.HexToFelt(*classHashFlag)
+		} else {
+			classHash, err = deploy.ClassHashFromFile(cfg.Contracts.Ethrx.SierraPath)
+		}
+		if err != nil {
+			logger.Fatalf("❌ Invalid class hash: %s", err)
+		}
+		var constructorArgs []*felt.Felt
+		constructorArgs, err = ethrxDeployer.ConstructorCalldata()
+		if err != nil {
+			logger.Fatalf("❌ Failed to build constructor arguments: %s", err)
+		}
+		bundle, err = deployer.BuildDeployBundle(ctx, ethrxDeployer.GetContractName(), classHash, constructorArgs)
+	case "invoke":
+		if *address == "" || fs.NArg() < 1 {
+			exitUsage(fs)
+		}
+		ethrxABI, abiErr := abi.Load(cfg.Contracts.Ethrx.SierraPath)
+		if abiErr != nil {
+			logger.Fatalf("❌ Failed to load the Ethrx ABI: %s", abiErr)
+		}
+		call, callErr := contracts.BuildEthrxAdminCall(ethrxABI, *address, fs.Arg(0), fs.Args()[1:])
+		if callErr != nil {
+			logger.Fatalf("❌ %s", callErr)
+		}
+		description := fmt.Sprintf("Ethrx %s(%s)", fs.Arg(0), strings.Join(fs.Args()[1:], ", "))
+		bundle, err = deployer.BuildInvokeBundle(ctx, description, []rpc.InvokeFunctionCall{call})
+	default:
+		logger.Fatalf("❌ Unknown bundle type: %s (expected declare, deploy or invoke)", kind)
+	}
+	if err != nil {
+		logger.Fatalf("❌ Failed to build bundle: %s", err)
+	}
+
+	if err := deploy.SaveBundle(*out, bundle); err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+
+	printBundle(bundle, logger)
+	logger.Infof("📝 Unsigned bundle written to %s", *out)
+	logger.Info("➡️  Sign it offline with: deploy sign " + *out)
+	printResult(fileResult{Path: *out, Content: bundle})
+}
+
+// runSign signs a bundle without any network access: sign [flags] <bundle.json>
+func runSign(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	keystorePath := fs.String("keystore", "", "encrypted keystore to sign with (default: configured deployer signer)")
+	passwordFile := fs.String("password-file", "", "file containing the keystore passphrase (default: prompt)")
+	out := fs.String("out", "", "path of the signed bundle (default: overwrite the input)")
+	yes := fs.Bool("yes", false, "sign without asking for confirmation")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deploy sign [flags] <bundle.json>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		exitUsage(fs)
+	}
+	path := fs.Arg(0)
+	if *out == "" {
+		*out = path
+	}
+
+	txSigner, logger := offlineSigner(*keystorePath, *passwordFile)
+	defer txSigner.Close()
+
+	bundle, err := deploy.LoadBundle(path)
+	if err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+	if err := bundle.Verify(); err != nil {
+		logger.Fatalf("❌ Bundle verification failed: %s", err)
+	}
+
+	printBundle(bundle, logger)
+	if !*yes && !confirm("Sign this transaction?") {
+		logger.Fatal("❌ Signing cancelled")
+	}
+
+	if err := bundle.Sign(ctx, txSigner); err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+	if err := deploy.SaveBundle(*out, bundle); err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+
+	logger.Infof("✅ Signed bundle written to %s", *out)
+	logger.Info("➡️  Broadcast it with: deploy broadcast " + *out)
+	printResult(fileResult{Path: *out, Content: bundle})
+}
+
+// offlineSigner returns the signer for offline commands: the given keystore, or the
+// configured deployer signer when no keystore is passed
+func offlineSigner(keystorePath, passwordFile string) (signer.Signer, *logrus.Logger) {
+	if keystorePath != "" {
+		// Air-gapped machines may only have the keystore, so skip loading the full configuration
+		logger := defaultLogger()
+		privKey, err := keystore.Unlock(keystorePath, passwordFile)
+		if err != nil {
+			logger.Fatalf("❌ Failed to unlock keystore: %s", err)
+		}
+		txSigner, err := signer.NewLocalSigner(privKey, "")
+		if err != nil {
+			logger.Fatalf("❌ Failed to create signer: %s", err)
+		}
+		return txSigner, logger
+	}
+
+	cfg, logger := loadSignerConfig()
+	txSigner, err := newSigner(cfg, logger)
+	if err != nil {
+		logger.Fatalf("❌ Failed to create signer: %s", err)
+	}
+	return txSigner, logger
+}
+
+// runBroadcast submits a signed bundle and waits for its receipt: broadcast [flags] <bundle.json>
+func runBroadcast(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("broadcast", flag.ExitOnError)
+	noWait := fs.Bool("no-wait", false, "return after submission without waiting for the receipt")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deploy broadcast [flags] <bundle.json>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		exitUsage(fs)
+	}
+
+	cfg, logger := loadConfig()
+	deployer := connect(ctx, cfg, nil, logger)
+
+	bundle, err := deploy.LoadBundle(fs.Arg(0))
+	if err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+	if err := bundle.Verify(); err != nil {
+		logger.Fatalf("❌ Bundle verification failed: %s", err)
+	}
+	printBundle(bundle, logger)
+
+	txHash, err := deployer.BroadcastBundle(ctx, bundle)
+	if err != nil {
+		logger.Fatalf("❌ Broadcast failed: %s", err)
+	}
+	logger.WithField("tx_hash", txHash.String()).Infof("📤 Transaction submitted: %s", txHash.String())
+
+	if *noWait {
+		printResult(txResult{TransactionHash: txHash.String()})
+		return
+	}
+
+	logger.Info("⏳ Waiting for transaction confirmation...")
+	receipt, err := deployer.WaitForReceipt(ctx, txHash)
+	if err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+	logger.Infof("✅ Transaction confirmed (%s, block %d)", receipt.FinalityStatus, receipt.BlockNumber)
+	result := txResult{TransactionHash: txHash.String(), FinalityStatus: string(receipt.FinalityStatus), BlockNumber: uint64(receipt.BlockNumber)}
+
+	// Deployments are recorded in the history like direct deploys
+	if bundle.ExpectedAddress != "" {
+		deployment := &deploy.DeploymentResult{
+			ContractName:    bundle.ContractName,
+			ClassHash:       bundle.ClassHash,
+			DeployedAddress: bundle.ExpectedAddress,
+			TransactionHash: txHash.String(),
+			DeploymentTime:  time.Now(),
+			Network:         bundle.Network,
+		}
+		history := deploy.NewDeploymentHistory()
+		if err := history.LogDeployment(deployment); err != nil {
+			logger.Warnf("⚠️  Failed to log deployment to history: %s", err)
+		} else {
+			logger.Info("📝 Deployment logged to history file")
+		}
+		logger.Infof("   Deployed Address: %s", deployment.DeployedAddress)
+		result.DeployedAddress = deployment.DeployedAddress
+	}
+	printResult(result)
+}
+
+// printBundle logs a human readable summary of what a bundle does
+func printBundle(bundle *deploy.TransactionBundle, logger *logrus.Logger) {
+	logger.Info("📋 Transaction Bundle:")
+	logger.Infof("   Description: %s", bundle.Description)
+	logger.Infof("   Network: %s (%s)", bundle.Network, utils.HexToShortStr(bundle.ChainID))
+	logger.Infof("   Account: %s", bundle.Account)
+	logger.Infof("   Type: %s", bundle.Type)
+	logger.Infof("   Nonce: %s", bundle.Nonce().String())
+	logger.Infof("   Transaction Hash: %s", bundle.TransactionHash)
+	if bundle.ClassHash != "" {
+		logger.Infof("   Class Hash: %s", bundle.ClassHash)
+	}
+	if bundle.ExpectedAddress != "" {
+		logger.Infof("   Expected Address: %s", bundle.ExpectedAddress)
+	}
+	for i, call := range bundle.Calls {
+		logger.Infof("   Call %d: %s.%s", i, call.ContractAddress, call.Entrypoint)
+		logger.Infof("      Calldata: [%s]", strings.Join(call.Calldata, ", "))
+	}
+	logger.Infof("   Signed: %t", bundle.IsSigned())
+}
+
+// confirm asks a yes/no question on the terminal
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s (y/N) ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	return answer == "y" || answer == "Y"
+}
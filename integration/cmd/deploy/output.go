@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Output formats selected with --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var (
+	// output is the --output format of this run
+	output = outputText
+	// commandName is the running command, reported in JSON results
+	commandName string
+
+	// logFields are added to every JSON log entry, such as the network once it is known
+	logFields = &fieldsHook{fields: logrus.Fields{}}
+
+	// results prints the JSON result of this run on stdout
+	results = &resultWriter{out: os.Stdout}
+
+	fatalMessage string
+)
+
+// commandResult is the single JSON document printed on stdout by a command in JSON mode
+type commandResult struct {
+	Command string `json:"command"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Result  any    `json:"result,omitempty"`
+}
+
+// fileResult is the JSON result of commands that write a bundle or proposal file
+type fileResult struct {
+	Path    string `json:"path"`
+	Content any    `json:"content"`
+}
+
+// txResult is the JSON result of commands that submit a transaction
+type txResult struct {
+	TransactionHash string `json:"transaction_hash"`
+	FinalityStatus  string `json:"finality_status,omitempty"`
+	BlockNumber     uint64 `json:"block_number,omitempty"`
+	DeployedAddress string `json:"deployed_address,omitempty"`
+}
+
+// extractOutputFlag reads --output <format> or --output=<format> from the flags before
+// the command name. The command's own arguments are left to its flag set.
+func extractOutputFlag(args []string) (string, []string, error) {
+	format := outputText
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(args[0], "=")
+		if name != "--output" && name != "-output" {
+			break
+		}
+		args = args[1:]
+		if !hasValue {
+			if len(args) == 0 {
+				return "", nil, errors.New("--output needs a value: text or json")
+			}
+			value, args = args[0], args[1:]
+		}
+		if value != outputText && value != outputJSON {
+			return "", nil, fmt.Errorf("invalid --output %q: expected text or json", value)
+		}
+		format = value
+	}
+	return format, args, nil
+}
+
+// newLogger creates the command logger on stderr
+func newLogger(level logrus.Level, verbose bool) *logrus.Logger {
+	logger := logrus.New()
+	configureLogger(logger, level, verbose)
+	return logger
+}
+
+// configureLogger sets up a logger for the --output format. JSON mode logs one JSON
+// object per line; text mode uses colors and emoji only when stderr is a terminal.
+func configureLogger(logger *logrus.Logger, level logrus.Level, verbose bool) {
+	logger.SetOutput(os.Stderr)
+	logger.SetLevel(level)
+	logger.ExitFunc = exit
+	logger.AddHook(fatalHook{})
+
+	tty := isTerminal(os.Stderr)
+	var formatter logrus.Formatter
+	if output == outputJSON {
+		formatter = &logrus.JSONFormatter{}
+		logger.AddHook(logFields)
+	} else {
+		formatter = &logrus.TextFormatter{
+			FullTimestamp:    verbose,
+			DisableTimestamp: !verbose,
+			ForceColors:      tty,
+			DisableColors:    !tty,
+		}
+	}
+	if output == outputJSON || !tty {
+		formatter = plainFormatter{formatter}
+	}
+	logger.SetFormatter(formatter)
+}
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// setLogField adds a field to every following JSON log entry
+func setLogField(key string, value any) {
+	logFields.mu.Lock()
+	defer logFields.mu.Unlock()
+	logFields.fields[key] = value
+}
+
+// fieldsHook adds common fields to log entries without overriding the entry's own
+type fieldsHook struct {
+	mu     sync.Mutex
+	fields logrus.Fields
+}
+
+func (h *fieldsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *fieldsHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for key, value := range h.fields {
+		if _, ok := entry.Data[key]; !ok {
+			entry.Data[key] = value
+		}
+	}
+	return nil
+}
+
+// fatalHook keeps the message of a fatal entry for the error result
+type fatalHook struct{}
+
+func (fatalHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.FatalLevel, logrus.PanicLevel}
+}
+
+func (fatalHook) Fire(entry *logrus.Entry) error {
+	fatalMessage = stripEmoji(entry.Message)
+	return nil
+}
+
+// plainFormatter strips emoji from messages for logs read by machines or CI
+type plainFormatter struct {
+	logrus.Formatter
+}
+
+func (f plainFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	plain := *entry
+	plain.Message = stripEmoji(entry.Message)
+	return f.Formatter.Format(&plain)
+}
+
+// stripEmoji removes the decorative emoji at the start of a message, with the spaces
+// following it; the indentation before it and the rest of the message are kept
+func stripEmoji(message string) string {
+	body := strings.TrimLeft(message, " ")
+	indent := message[:len(message)-len(body)]
+	rest := strings.TrimLeftFunc(body, isEmoji)
+	if rest == body || (rest != "" && rest[0] != ' ') {
+		return message
+	}
+	return indent + strings.TrimLeft(rest, " ")
+}
+
+// isEmoji reports whether r is a symbol, variation selector or joiner of an emoji
+func isEmoji(r rune) bool {
+	return unicode.Is(unicode.So, r) || r == '\uFE0F' || r == '\u200D'
+}
+
+// printResult prints the final result of a successful command in JSON mode. Text mode
+// has already logged a summary, so nothing is printed.
+func printResult(result any) {
+	results.write(commandResult{Command: commandName, Status: "ok", Result: result})
+}
+
+// fail ends a command that has no logger yet, such as on invalid arguments
+func fail(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	switch {
+	case output == outputJSON:
+	case isTerminal(os.Stderr):
+		fmt.Fprintf(os.Stderr, "❌ %s\n", message)
+	default:
+		fmt.Fprintln(os.Stderr, message)
+	}
+	results.write(commandResult{Command: commandName, Status: "error", Error: message})
+	os.Exit(1)
+}
+
+// exitUsage prints the usage of a command and fails
+func exitUsage(fs *flag.FlagSet) {
+	fs.Usage()
+	if output == outputJSON {
+		fail("invalid arguments for %s", fs.Name())
+	}
+	os.Exit(1)
+}
+
+// exit is the logger's exit function: fatal errors end with an error result in JSON mode
+func exit(code int) {
+	results.write(commandResult{Command: commandName, Status: "error", Error: fatalMessage})
+	os.Exit(code)
+}
+
+// resultWriter prints the JSON result of a command once
+type resultWriter struct {
+	out  io.Writer
+	once sync.Once
+}
+
+// write prints the JSON result once in JSON mode
+func (w *resultWriter) write(result commandResult) {
+	if output != outputJSON {
+		return
+	}
+	w.once.Do(func() {
+		encoder := json.NewEncoder(w.out)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(result); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode result: %s\n", err)
+		}
+	})
+}
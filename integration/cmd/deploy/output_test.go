@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExtractOutputFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantFlag string
+		wantArgs []string
+		wantErr  string
+	}{
+		{name: "no flag", args: []string{"inspect", "42"}, wantFlag: outputText, wantArgs: []string{"inspect", "42"}},
+		{name: "separate value", args: []string{"--output", "json", "ethrx"}, wantFlag: outputJSON, wantArgs: []string{"ethrx"}},
+		{name: "inline value", args: []string{"--output=json", "plan", "launch.json"}, wantFlag: outputJSON, wantArgs: []string{"plan", "launch.json"}},
+		{name: "single dash", args: []string{"-output=text", "contracts"}, wantFlag: outputText, wantArgs: []string{"contracts"}},
+		{name: "last one wins", args: []string{"--output=json", "--output", "text", "ethrx"}, wantFlag: outputText, wantArgs: []string{"ethrx"}},
+		{name: "no command", args: []string{"--output", "json"}, wantFlag: outputJSON, wantArgs: []string{}},
+		{
+			// Arguments after the command name are the command's own, even when they look like --output
+			name:     "after the command",
+			args:     []string{"generic", "--output", "json", "name=Token"},
+			wantFlag: outputText,
+			wantArgs: []string{"generic", "--output", "json", "name=Token"},
+		},
+		{name: "other flag first", args: []string{"--help", "--output", "json"}, wantFlag: outputText, wantArgs: []string{"--help", "--output", "json"}},
+		{name: "missing value", args: []string{"--output"}, wantErr: "needs a value"},
+		{name: "invalid value", args: []string{"--output", "yaml", "ethrx"}, wantErr: `invalid --output "yaml"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, args, err := extractOutputFlag(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != tt.wantFlag || !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("extractOutputFlag = %q, %q; want %q, %q", format, args, tt.wantFlag, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestStripEmoji(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"✅ Connected", "Connected"},
+		{"⚠️  Failed to log deployment", "Failed to log deployment"},
+		{"   🔗 Transaction: 0x1", "   Transaction: 0x1"},
+		{"👩‍💻 Signer ready", "Signer ready"},
+		{"🎉", ""},
+		{"Engraved TITLE ★ first", "Engraved TITLE ★ first"},
+		{"Minted © 2025", "Minted © 2025"},
+		{"©2025 notice", "©2025 notice"},
+		{"Plain message", "Plain message"},
+	}
+	for _, tt := range tests {
+		if got := stripEmoji(tt.message); got != tt.want {
+			t.Errorf("stripEmoji(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestResultWriter(t *testing.T) {
+	defer func(format string) { output = format }(output)
+
+	var out bytes.Buffer
+	w := &resultWriter{out: &out}
+	output = outputText
+	w.write(commandResult{Command: "ethrx", Status: "ok"})
+	if out.Len() != 0 {
+		t.Fatalf("text mode printed a result: %s", out.String())
+	}
+
+	output = outputJSON
+	w.write(commandResult{Command: "inspect", Status: "ok", Result: txResult{TransactionHash: "0x1", BlockNumber: 7}})
+	// Only the first result is printed, such as a success followed by a failed exit
+	w.write(commandResult{Command: "inspect", Status: "error", Error: "late failure"})
+
+	var result struct {
+		Command string
+		Status  string
+		Error   string
+		Result  map[string]any
+	}
+	decoder := json.NewDecoder(&out)
+	if err := decoder.Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if decoder.More() {
+		t.Error("more than one result printed")
+	}
+	if result.Command != "inspect" || result.Status != "ok" || result.Error != "" {
+		t.Errorf("result = %+v", result)
+	}
+	if want := map[string]any{"transaction_hash": "0x1", "block_number": float64(7)}; !maps.Equal(result.Result, want) {
+		t.Errorf("result = %v, want %v", result.Result, want)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/config"
+	"github.com/NovemberFork/etheracts/integration/pkg/contracts"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+)
+
+// runPlan deploys every contract of a plan file in dependency order:
+//
+//	deploy plan plans/ethrx-with-mock.json
+func runPlan(ctx context.Context, deployer *deploy.Deployer, cfg *config.Config, logger *logrus.Logger, args []string) {
+	if len(args) != 1 {
+		logger.Fatal("❌ Usage: deploy plan <plan.json>")
+	}
+
+	plan, err := deploy.LoadPlan(args[0])
+	if err != nil {
+		logger.Fatalf("❌ Failed to load plan: %s", err)
+	}
+
+	steps, err := plan.Order()
+	if err != nil {
+		logger.Fatalf("❌ Invalid plan: %s", err)
+	}
+	logger.Infof("🗺️  Deployment plan: %s", plan.Name)
+	for i, step := range steps {
+		logger.Infof("   %d. %s (%s)", i+1, step.ID, step.Contract)
+	}
+
+	result, err := deployer.ExecutePlan(ctx, plan, planFactory(deployer, cfg, logger))
+	if err != nil {
+		// Contracts deployed before the failure are on chain and must not be lost
+		if result != nil && len(result.Steps) > 0 {
+			for _, step := range result.Steps {
+				logger.Warnf("⚠️  Already deployed: %s at %s", step.ID, step.Result.DeployedAddress)
+			}
+			logPlan(result, logger)
+		}
+		logger.Fatalf("❌ Plan deployment failed: %s", err)
+	}
+
+	logPlan(result, logger)
+
+	logger.Info("🎉 Plan deployment completed successfully!")
+	logger.Info("📋 Final Summary:")
+	logger.Infof("   Plan: %s", result.Name)
+	logger.Infof("   Network: %s", result.Network)
+	for _, step := range result.Steps {
+		logger.Infof("   %s (%s): %s", step.ID, step.Result.ContractName, step.Result.DeployedAddress)
+	}
+	printResult(result)
+}
+
+// logPlan records the deployed plan steps in the network history file
+func logPlan(result *deploy.PlanResult, logger *logrus.Logger) {
+	history := deploy.NewDeploymentHistory()
+	if err := history.LogPlan(result); err != nil {
+		logger.Warnf("⚠️  Failed to log deployment to history: %s", err)
+	} else {
+		logger.Info("📝 Deployment logged to history file")
+	}
+}
+
+// planFactory creates registered contract deployers for plan steps, applying the step
+// configuration on top of the contract defaults
+func planFactory(deployer *deploy.Deployer, cfg *config.Config, logger *logrus.Logger) deploy.StepFactory {
+	return func(step deploy.PlanStep, values map[string]string) (deploy.ContractDeployer, error) {
+		return contracts.NewRegisteredDeployer(deployer, cfg, step.Contract, values, logger)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+)
+
+// progressBarWidth is the number of cells of the live progress bar
+const progressBarWidth = 24
+
+// newProgressReporter returns how deployment progress is shown for the --output format:
+// a live status line on a terminal, progress log entries in JSON mode, and nothing on
+// plain text logs, which already have a line per step
+func newProgressReporter(logger *logrus.Logger) deploy.ProgressFunc {
+	switch {
+	case output == outputJSON:
+		return func(p deploy.DeploymentProgress) { logProgress(logger, p) }
+	case isTerminal(os.Stderr):
+		view := &progressView{out: os.Stderr}
+		logger.AddHook(view)
+		return view.update
+	default:
+		return nil
+	}
+}
+
+// logProgress logs a progress update with its values as fields
+func logProgress(logger *logrus.Logger, p deploy.DeploymentProgress) {
+	entry := logger.WithFields(logrus.Fields{
+		"event":        "progress",
+		"contract":     p.Contract,
+		"status":       p.Status,
+		"step":         p.CurrentStep,
+		"progress":     p.Progress,
+		"elapsed_ms":   p.ElapsedTime.Milliseconds(),
+		"estimated_ms": p.EstimatedTime.Milliseconds(),
+	})
+	if p.TransactionHash != "" {
+		entry = entry.WithField("tx_hash", p.TransactionHash)
+	}
+	entry.Info(p.Message)
+}
+
+// progressView keeps a status line below the logs, redrawn every second so elapsed and
+// remaining time keep moving while a transaction is awaited. It is a logrus hook that
+// clears the line before each log entry is written.
+type progressView struct {
+	mu    sync.Mutex
+	out   io.Writer
+	last  deploy.DeploymentProgress
+	at    time.Time
+	drawn bool
+	stop  chan struct{}
+}
+
+// update shows a new progress report
+func (v *progressView) update(p deploy.DeploymentProgress) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.last, v.at = p, time.Now()
+	switch p.Status {
+	case deploy.StatusCompleted, deploy.StatusFailed:
+		if v.stop != nil {
+			close(v.stop)
+			v.stop = nil
+		}
+		v.draw()
+		fmt.Fprintln(v.out)
+		v.drawn = false
+		return
+	}
+
+	if v.stop == nil {
+		v.stop = make(chan struct{})
+		go v.tick(v.stop)
+	}
+	v.draw()
+}
+
+// tick redraws the line until stop is closed
+func (v *progressView) tick(stop chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			v.mu.Lock()
+			v.draw()
+			v.mu.Unlock()
+		}
+	}
+}
+
+// draw replaces the status line; v.mu must be held
+func (v *progressView) draw() {
+	fmt.Fprintf(v.out, "\r\033[K%s", progressLine(v.last, time.Since(v.at)))
+	v.drawn = true
+}
+
+func (v *progressView) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (v *progressView) Fire(*logrus.Entry) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.drawn {
+		fmt.Fprint(v.out, "\r\033[K")
+		v.drawn = false
+	}
+	return nil
+}
+
+// progressLine renders a progress report, since seconds after it was received
+func progressLine(p deploy.DeploymentProgress, since time.Duration) string {
+	elapsed := (p.ElapsedTime + since).Round(time.Second)
+	switch p.Status {
+	case deploy.StatusCompleted:
+		return fmt.Sprintf("✅ %s deployed in %s", p.Contract, p.ElapsedTime.Round(time.Second))
+	case deploy.StatusFailed:
+		return fmt.Sprintf("❌ %s failed at the %s step after %s", p.Contract, p.CurrentStep, p.ElapsedTime.Round(time.Second))
+	}
+
+	filled := int(p.Progress * progressBarWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+	line := fmt.Sprintf("⏳ %s [%s] %3.0f%% %s · %s elapsed", p.Contract, bar, p.Progress*100, p.Message, elapsed)
+	if left := p.EstimatedTime - since; left > 0 {
+		line += fmt.Sprintf(" · ~%s left", left.Round(time.Second))
+	} else {
+		line += " · almost done"
+	}
+	return line
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/abi"
+	"github.com/NovemberFork/etheracts/integration/pkg/contracts"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+)
+
+// runPropose builds a multisig proposal for an owner-only Ethrx call:
+// propose [flags] <function> [args...]
+func runPropose(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("propose", flag.ExitOnError)
+	out := fs.String("out", "proposal.json", "path of the proposal to write")
+	address := fs.String("address", "", "Ethrx contract address")
+	multisigFlag := fs.String("multisig", "", "multisig account owning the contract (default: configured Ethrx owner)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: deploy propose [flags] <function> [args...]\n\nfunctions:\n  %s\n", strings.Join(contracts.EthrxAdminFunctions(), "\n  "))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *address == "" || fs.NArg() < 1 {
+		exitUsage(fs)
+	}
+
+	cfg, logger := loadConfig()
+	setLogField("contract", *address)
+	deployer := connect(ctx, cfg, nil, logger)
+
+	if *multisigFlag == "" {
+		*multisigFlag = cfg.Contracts.Ethrx.Owner
+	}
+	multisig, err := utils.HexToFelt(*multisigFlag)
+	if err != nil {
+		logger.Fatalf("❌ Invalid multisig address: %s", err)
+	}
+
+	ethrxABI, err := abi.Load(cfg.Contracts.Ethrx.SierraPath)
+	if err != nil {
+		logger.Fatalf("❌ Failed to load the Ethrx ABI: %s", err)
+	}
+	call, err := contracts.BuildEthrxAdminCall(ethrxABI, *address, fs.Arg(0), fs.Args()[1:])
+	if err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+	description := fmt.Sprintf("Ethrx %s(%s)", fs.Arg(0), strings.Join(fs.Args()[1:], ", "))
+
+	proposal, err := deployer.BuildProposal(ctx, multisig, description, []rpc.InvokeFunctionCall{call})
+	if err != nil {
+		logger.Fatalf("❌ Failed to build proposal: %s", err)
+	}
+	if err := deploy.SaveProposal(*out, proposal); err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+
+	printProposal(proposal, logger)
+	logger.Infof("📝 Proposal written to %s", *out)
+	logger.Infof("➡️  Collect %d signature(s) with: deploy sign-proposal %s", proposal.Threshold, *out)
+	printResult(fileResult{Path: *out, Content: proposal})
+}
+
+// runSignProposal adds one signer's approval to a proposal: sign-proposal [flags] <proposal.json>
+func runSignProposal(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("sign-proposal", flag.ExitOnError)
+	keystorePath := fs.String("keystore", "", "encrypted keystore to sign with (default: configured deployer signer)")
+	passwordFile := fs.String("password-file", "", "file containing the keystore passphrase (default: prompt)")
+	yes := fs.Bool("yes", false, "sign without asking for confirmation")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deploy sign-proposal [flags] <proposal.json>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		exitUsage(fs)
+	}
+	path := fs.Arg(0)
+
+	txSigner, logger := offlineSigner(*keystorePath, *passwordFile)
+	defer txSigner.Close()
+
+	proposal, err := deploy.LoadProposal(path)
+	if err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+	if err := proposal.Verify(); err != nil {
+		logger.Fatalf("❌ Proposal verification failed: %s", err)
+	}
+
+	printProposal(proposal, logger)
+	if !*yes && !confirm("Approve this proposal?") {
+		logger.Fatal("❌ Signing cancelled")
+	}
+
+	if err := proposal.Sign(ctx, txSigner); err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+	if err := deploy.SaveProposal(path, proposal); err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+
+	logger.Infof("✅ Signature added (%d of %d)", len(proposal.Signatures), proposal.Threshold)
+	if proposal.ThresholdMet() {
+		logger.Info("➡️  Threshold met, submit it with: deploy submit-proposal " + path)
+	}
+	printResult(fileResult{Path: path, Content: proposal})
+}
+
+// runSubmitProposal submits a proposal once the threshold is met: submit-proposal [flags] <proposal.json>
+func runSubmitProposal(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("submit-proposal", flag.ExitOnError)
+	noWait := fs.Bool("no-wait", false, "return after submission without waiting for the receipt")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deploy submit-proposal [flags] <proposal.json>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		exitUsage(fs)
+	}
+
+	cfg, logger := loadConfig()
+	deployer := connect(ctx, cfg, nil, logger)
+
+	proposal, err := deploy.LoadProposal(fs.Arg(0))
+	if err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+	printProposal(proposal, logger)
+
+	txHash, err := deployer.SubmitProposal(ctx, proposal)
+	if err != nil {
+		logger.Fatalf("❌ Submission failed: %s", err)
+	}
+	logger.WithField("tx_hash", txHash.String()).Infof("📤 Transaction submitted: %s", txHash.String())
+
+	if *noWait {
+		printResult(txResult{TransactionHash: txHash.String()})
+		return
+	}
+
+	logger.Info("⏳ Waiting for transaction confirmation...")
+	receipt, err := deployer.WaitForReceipt(ctx, txHash)
+	if err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+	logger.Infof("✅ Proposal executed (%s, block %d)", receipt.FinalityStatus, receipt.BlockNumber)
+	printResult(txResult{TransactionHash: txHash.String(), FinalityStatus: string(receipt.FinalityStatus), BlockNumber: uint64(receipt.BlockNumber)})
+}
+
+// printProposal logs the decoded proposal and the collected approvals
+func printProposal(proposal *deploy.Proposal, logger *logrus.Logger) {
+	printBundle(&proposal.Transaction, logger)
+	logger.Infof("   Multisig: %s", proposal.Multisig)
+	logger.Infof("   Threshold: %d of %d signers", proposal.Threshold, len(proposal.Signers))
+	for _, s := range proposal.Signers {
+		status := "pending"
+		if proposal.HasSigned(s) {
+			status = "signed"
+		}
+		logger.Infof("      %s (%s)", s, status)
+	}
+}
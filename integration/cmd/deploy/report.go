@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/ethrx"
+)
+
+// runReport writes holder and engraving statistics of an Ethrx collection: report [flags]
+func runReport(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	address := fs.String("address", "", "Ethrx contract address")
+	snapshotPath := fs.String("snapshot", "", "snapshot.json written by export, instead of reading --address")
+	block := fs.Uint64("block", 0, "block number to report at (default: latest)")
+	fromBlock := fs.Uint64("from-block", 0, "first block scanned for events, e.g. the deployment block")
+	out := fs.String("out", "", "output directory (default: reports/<network>-<block>)")
+	top := fs.Int("top", 10, "holders and tokens listed in the rankings")
+	chunkSize := fs.Int("chunk-size", 100, "initial tokens per batched call, halved on node limits")
+	workers := fs.Int("workers", 8, "concurrent RPC calls")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deploy report (--address <ethrx> | --snapshot <file>) [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if (*address == "") == (*snapshotPath == "") || fs.NArg() != 0 {
+		exitUsage(fs)
+	}
+
+	cfg, logger := loadConfig()
+	client := dial(ctx, cfg, logger)
+
+	var snapshot *ethrx.Snapshot
+	var err error
+	if *snapshotPath != "" {
+		snapshot, err = ethrx.LoadSnapshot(*snapshotPath)
+	} else {
+		contract, parseErr := utils.HexToFelt(*address)
+		if parseErr != nil {
+			logger.Fatalf("❌ Invalid contract address: %s", parseErr)
+		}
+		logger.Infof("📸 Snapshotting Ethrx %s", contract.String())
+		snapshot, err = ethrx.TakeSnapshot(ctx, client, contract, ethrx.SnapshotOptions{
+			Block:     *block,
+			ChunkSize: *chunkSize,
+			Workers:   *workers,
+			Logger:    logger,
+		})
+	}
+	if err != nil {
+		logger.Fatalf("❌ Failed to load the snapshot: %s", err)
+	}
+
+	setLogField("contract", snapshot.Contract)
+	logger.Infof("📊 Building the report of %s at block %d", snapshot.Contract, snapshot.BlockNumber)
+	report, err := ethrx.BuildReport(ctx, client, snapshot, ethrx.ReportOptions{
+		Network:   cfg.Network.Name,
+		FromBlock: *fromBlock,
+		Top:       *top,
+		Workers:   *workers,
+		Logger:    logger,
+	})
+	if err != nil {
+		logger.Fatalf("❌ Report failed: %s", err)
+	}
+
+	dir := *out
+	if dir == "" {
+		dir = filepath.Join("reports", fmt.Sprintf("%s-%d", cfg.Network.Name, report.BlockNumber))
+	}
+	if err := ethrx.WriteReport(dir, report); err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+
+	logger.Info("🎉 Report completed successfully!")
+	logger.Info("📋 Summary:")
+	logger.Infof("   Supply: %s of %s (%s remaining)", report.TotalSupply, report.MaxSupply, report.RemainingSupply)
+	logger.Infof("   Holders: %d (owner holds %d tokens, others %d)", report.Holders, report.OwnerTokens, report.OtherTokens)
+	logger.Infof("   Engravings: %d on %d tokens", report.Engravings, report.EngravedTokens)
+	logger.Infof("   Mint Revenue: %s from %d paid mints", report.MintRevenue, report.PaidMints)
+	logger.Infof("   Output: %s", dir)
+	printResult(reportResult{Output: dir, Report: report})
+}
+
+// reportResult is the JSON result of the report command
+type reportResult struct {
+	Output string `json:"output"`
+	*ethrx.Report
+}
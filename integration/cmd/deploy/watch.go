@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/ethrx"
+)
+
+// runWatch polls an Ethrx contract for owner-level changes and raises alerts: watch [flags]
+func runWatch(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	address := fs.String("address", "", "Ethrx contract address")
+	interval := fs.Duration("interval", 30*time.Second, "how often new blocks are scanned for events and settings changes")
+	fromBlock := fs.Uint64("from-block", 0, "first block scanned without saved state (default: latest)")
+	statePath := fs.String("state", "", "file keeping the last known state (default: .watch/<network>-<address>.json)")
+	webhook := fs.String("webhook", "", "URL the alerts are posted to as JSON")
+	alertsFile := fs.String("alerts-file", "", "file the alerts are appended to, one JSON object per line")
+	once := fs.Bool("once", false, "check once and exit, e.g. from cron")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: deploy watch --address <ethrx> [--webhook <url>] [--alerts-file <file>] [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *address == "" || fs.NArg() != 0 || *interval <= 0 {
+		exitUsage(fs)
+	}
+
+	cfg, logger := loadConfig()
+	client := dial(ctx, cfg, logger)
+
+	contract, err := utils.HexToFelt(*address)
+	if err != nil {
+		logger.Fatalf("❌ Invalid contract address: %s", err)
+	}
+	setLogField("contract", contract.String())
+
+	// Named after the flags, which key the undelivered alerts in the state file
+	notifiers := ethrx.Notifiers{}
+	if *webhook != "" {
+		notifiers["webhook"] = &ethrx.WebhookNotifier{URL: *webhook, Client: &http.Client{Timeout: 10 * time.Second}}
+		logger.Infof("🔔 Posting alerts to %s", *webhook)
+	}
+	if *alertsFile != "" {
+		notifiers["alerts-file"] = &ethrx.FileNotifier{Path: *alertsFile}
+		logger.Infof("🔔 Writing alerts to %s", *alertsFile)
+	}
+	if len(notifiers) == 0 {
+		logger.Warn("⚠️  No --webhook or --alerts-file given, alerts are only logged")
+	}
+
+	if *statePath == "" {
+		*statePath = filepath.Join(".watch", fmt.Sprintf("%s-%s.json", cfg.Network.Name, contract.String()))
+	}
+	watcher, err := ethrx.NewWatcher(client, contract, ethrx.WatchOptions{
+		Network:   cfg.Network.Name,
+		FromBlock: *fromBlock,
+		StatePath: *statePath,
+		Logger:    logger,
+		Metrics:   startMetrics(ctx, cfg, logger),
+	})
+	if err != nil {
+		logger.Fatalf("❌ %s", err)
+	}
+
+	var alerts []ethrx.Alert
+	onAlert := func(alert ethrx.Alert) {
+		alerts = append(alerts, alert)
+		entry := logger.WithField("kind", alert.Kind)
+		if alert.TxHash != "" {
+			entry = entry.WithField("tx_hash", alert.TxHash)
+		}
+		entry.Warnf("🚨 %s", alert)
+	}
+
+	if *once {
+		if err := watcher.Check(ctx, notifiers, onAlert); err != nil {
+			logger.Fatalf("❌ Check failed: %s", err)
+		}
+	} else {
+		logger.Infof("👀 Watching Ethrx %s every %s", contract.String(), *interval)
+		watcher.Run(ctx, *interval, notifiers, onAlert)
+		logger.Info("👋 Watcher stopped")
+	}
+
+	logger.Info("📋 Summary:")
+	if state := watcher.State(); state != nil {
+		logger.Infof("   State Read At Block: %d", state.BlockNumber)
+		logger.Infof("   Owner: %s", state.Owner)
+		logger.Infof("   Mint Price: %s (minting %t)", state.MintPrice, state.IsMinting)
+	}
+	logger.Infof("   Alerts: %d", len(alerts))
+	printResult(watchResult{State: watcher.State(), Alerts: alerts})
+}
+
+// watchResult is the JSON result of the watch command
+type watchResult struct {
+	State  *ethrx.OwnerState `json:"state"`
+	Alerts []ethrx.Alert     `json:"alerts"`
+}
@@ -0,0 +1,3 @@
+// Package e2e holds end-to-end tests deploying and exercising Ethrx on starknet-devnet.
+// They are skipped when no devnet is available; see pkg/devnet.
+package e2e
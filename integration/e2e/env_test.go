@@ -0,0 +1,276 @@
+package e2e
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/abi"
+	"github.com/NovemberFork/etheracts/integration/pkg/contracts"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+	"github.com/NovemberFork/etheracts/integration/pkg/devnet"
+	"github.com/NovemberFork/etheracts/integration/pkg/signer"
+)
+
+// mintPrice is the Ethrx mint price in mock token base units
+const mintPrice = 1_000_000
+
+// env is a devnet with a freshly deployed MockERC20 and Ethrx owned by the first account
+type env struct {
+	t   *testing.T
+	ctx context.Context
+
+	devnet   *devnet.Devnet
+	accounts []devnet.Account
+	client   *rpc.Provider
+	owner    *deploy.Deployer
+	user     *deploy.Deployer
+
+	ethrx, token       *felt.Felt
+	ethrxABI, tokenABI *abi.ABI
+	ethrxClassHash     string
+}
+
+func newEnv(t *testing.T) *env {
+	t.Helper()
+	d := devnet.Start(t)
+	ethrxSierra, ethrxCasm := devnet.Artifacts(t, "Ethrx")
+	tokenSierra, tokenCasm := devnet.Artifacts(t, "MockERC20")
+
+	ctx := t.Context()
+	accounts, err := d.Accounts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) < 2 {
+		t.Fatalf("need 2 predeployed accounts, got %d", len(accounts))
+	}
+	chainID, err := d.ChainID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := rpc.NewProvider(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := &env{
+		t:        t,
+		ctx:      ctx,
+		devnet:   d,
+		accounts: accounts,
+		client:   client,
+		owner:    newDeployer(t, d.URL, chainID, accounts[0]),
+		user:     newDeployer(t, d.URL, chainID, accounts[1]),
+	}
+
+	token := e.deploy("mock_erc20", map[string]string{
+		contracts.KeySierraPath: tokenSierra,
+		contracts.KeyCasmPath:   tokenCasm,
+	})
+	ethrx := e.deploy("ethrx", map[string]string{
+		contracts.KeySierraPath: ethrxSierra,
+		contracts.KeyCasmPath:   ethrxCasm,
+		"owner":                 accounts[0].Address,
+		"name":                  "Etheracts",
+		"symbol":                "Ethrx",
+		"base_uri":              "http://localhost/etheracts/",
+		"contract_uri":          "http://localhost/etheracts/contract",
+		"mint_token":            token.DeployedAddress,
+		"mint_price":            big.NewInt(mintPrice).String(),
+		"max_supply":            "200",
+	})
+
+	e.token = feltFromHex(t, token.DeployedAddress)
+	e.ethrx = feltFromHex(t, ethrx.DeployedAddress)
+	e.ethrxClassHash = ethrx.ClassHash
+	if e.tokenABI, err = abi.Load(tokenSierra); err != nil {
+		t.Fatal(err)
+	}
+	if e.ethrxABI, err = abi.Load(ethrxSierra); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func newDeployer(t *testing.T, url, chainID string, account devnet.Account) *deploy.Deployer {
+	t.Helper()
+
+	s, err := signer.NewLocalSignerFromHex(account.PrivateKey, account.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	if !testing.Verbose() {
+		logger.SetOutput(io.Discard)
+	}
+
+	d, err := deploy.NewDeployer(t.Context(), url, "devnet", chainID, account.Address,
+		deploy.WithSigner(s),
+		deploy.WithLogger(logger),
+		deploy.WithWaitOptions(deploy.WaitOptions{
+			Timeout:         time.Minute,
+			InitialInterval: 100 * time.Millisecond,
+			MaxInterval:     time.Second,
+			Multiplier:      1.5,
+			Finality:        rpc.TxnStatus_Accepted_On_L2,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// deploy deploys a registered contract type from the owner account
+func (e *env) deploy(contract string, values map[string]string) *deploy.DeploymentResult {
+	e.t.Helper()
+
+	d, err := contracts.NewRegisteredDeployer(e.owner, nil, contract, values, nil)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	if err := d.ValidateConfig(); err != nil {
+		e.t.Fatal(err)
+	}
+	result, err := d.Deploy(e.ctx)
+	if err != nil {
+		e.t.Fatalf("deploying %s: %s", contract, err)
+	}
+	return result
+}
+
+// invoke sends a transaction calling function with ABI-encoded arguments and waits for it
+func (e *env) invoke(from *deploy.Deployer, contract *felt.Felt, contractABI *abi.ABI, function string, args map[string]any) error {
+	e.t.Helper()
+
+	calldata, err := contractABI.EncodeFunction(function, args)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	txHash, err := from.Invoke(e.ctx, []rpc.InvokeFunctionCall{{
+		ContractAddress: contract,
+		FunctionName:    function,
+		CallData:        calldata,
+	}})
+	if err != nil {
+		return err
+	}
+	_, err = from.WaitForReceipt(e.ctx, txHash)
+	return err
+}
+
+// mustInvoke is invoke failing the test on error
+func (e *env) mustInvoke(from *deploy.Deployer, contract *felt.Felt, contractABI *abi.ABI, function string, args map[string]any) {
+	e.t.Helper()
+	if err := e.invoke(from, contract, contractABI, function, args); err != nil {
+		e.t.Fatalf("%s: %s", function, err)
+	}
+}
+
+// call reads a view function with ABI-encoded arguments
+func (e *env) call(contract *felt.Felt, contractABI *abi.ABI, function string, args map[string]any) []*felt.Felt {
+	e.t.Helper()
+
+	calldata, err := contractABI.EncodeFunction(function, args)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	result, err := e.client.Call(e.ctx, rpc.FunctionCall{
+		ContractAddress:    contract,
+		EntryPointSelector: utils.GetSelectorFromNameFelt(function),
+		Calldata:           calldata,
+	}, rpc.WithBlockTag(rpc.BlockTagLatest))
+	if err != nil {
+		e.t.Fatalf("%s: %s", function, err)
+	}
+	return result
+}
+
+// u256 reads a view function returning a u256
+func (e *env) u256(contract *felt.Felt, contractABI *abi.ABI, function string, args map[string]any) *big.Int {
+	e.t.Helper()
+	result := e.call(contract, contractABI, function, args)
+	if len(result) != 2 {
+		e.t.Fatalf("%s: expected a u256, got %d felts", function, len(result))
+	}
+	high := result[1].BigInt(new(big.Int))
+	return high.Lsh(high, 128).Add(high, result[0].BigInt(new(big.Int)))
+}
+
+// enableMinting opens minting and funds the user with enough mock tokens for amount mints
+func (e *env) enableMinting(amount int64) {
+	e.t.Helper()
+	cost := big.NewInt(amount * mintPrice).String()
+
+	e.mustInvoke(e.owner, e.ethrx, e.ethrxABI, "set_is_minting", map[string]any{"enabled": true})
+	e.mustInvoke(e.owner, e.token, e.tokenABI, "mint", map[string]any{"to": e.user.GetAccountAddress(), "amount": cost})
+	e.mustInvoke(e.user, e.token, e.tokenABI, "approve", map[string]any{"spender": e.ethrx.String(), "amount": cost})
+}
+
+// mintToUser mints one token to the user and returns its id
+func (e *env) mintToUser() *big.Int {
+	e.t.Helper()
+
+	supply := e.u256(e.ethrx, e.ethrxABI, "total_supply", nil)
+	e.mustInvoke(e.user, e.ethrx, e.ethrxABI, "mint", map[string]any{
+		"amounts": []any{"1"},
+		"tos":     []any{e.user.GetAccountAddress()},
+	})
+	return supply.Add(supply, big.NewInt(1))
+}
+
+// artifact builds an Artifact argument with one engraving
+func artifact(tag, data string) map[string]any {
+	return map[string]any{
+		"collection": []any{
+			map[string]any{"tag": "0x" + hex.EncodeToString([]byte(tag)), "data": bytesValue(data)},
+		},
+	}
+}
+
+// bytesValue encodes a string as alexandria Bytes: the byte size and 16-byte big-endian
+// words, the last one padded with zeros on the right
+func bytesValue(s string) map[string]any {
+	data := []byte(s)
+	var words []any
+	for start := 0; start < len(data); start += 16 {
+		word := make([]byte, 16)
+		copy(word, data[start:])
+		words = append(words, "0x"+hex.EncodeToString(word))
+	}
+	return map[string]any{"size": len(data), "data": words}
+}
+
+func feltFromHex(t *testing.T, value string) *felt.Felt {
+	t.Helper()
+	f, err := utils.HexToFelt(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func equalFelts(a, b []*felt.Felt) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+var blockLatest = rpc.WithBlockTag(rpc.BlockTagLatest)
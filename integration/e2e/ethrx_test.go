@@ -0,0 +1,164 @@
+package e2e
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+)
+
+func TestDeploy(t *testing.T) {
+	e := newEnv(t)
+
+	if version := e.call(e.ethrx, e.ethrxABI, "version", nil); len(version) != 1 || version[0].Uint64() != 1 {
+		t.Errorf("version = %v, want 1", version)
+	}
+	if owner := e.call(e.ethrx, e.ethrxABI, "owner", nil); len(owner) != 1 || owner[0].String() != feltFromHex(t, e.owner.GetAccountAddress()).String() {
+		t.Errorf("owner = %v, want %s", owner, e.owner.GetAccountAddress())
+	}
+	if token := e.call(e.ethrx, e.ethrxABI, "mint_token", nil); len(token) != 1 || !token[0].Equal(e.token) {
+		t.Errorf("mint_token = %v, want %s", token, e.token)
+	}
+	if price := e.u256(e.ethrx, e.ethrxABI, "mint_price", nil); price.Int64() != mintPrice {
+		t.Errorf("mint_price = %s, want %d", price, mintPrice)
+	}
+	if supply := e.u256(e.ethrx, e.ethrxABI, "max_supply", nil); supply.Int64() != 200 {
+		t.Errorf("max_supply = %s, want 200", supply)
+	}
+}
+
+func TestMint(t *testing.T) {
+	e := newEnv(t)
+	e.enableMinting(2)
+
+	supply := e.u256(e.ethrx, e.ethrxABI, "total_supply", nil)
+	ownerFunds := e.u256(e.token, e.tokenABI, "balance_of", map[string]any{"account": e.owner.GetAccountAddress()})
+
+	e.mustInvoke(e.user, e.ethrx, e.ethrxABI, "mint", map[string]any{
+		"amounts": []any{"2"},
+		"tos":     []any{e.user.GetAccountAddress()},
+	})
+
+	if got := e.u256(e.ethrx, e.ethrxABI, "total_supply", nil); got.Cmp(new(big.Int).Add(supply, big.NewInt(2))) != 0 {
+		t.Errorf("total_supply = %s, want %s + 2", got, supply)
+	}
+	if got := e.u256(e.ethrx, e.ethrxABI, "balance_of", map[string]any{"account": e.user.GetAccountAddress()}); got.Int64() != 2 {
+		t.Errorf("user balance = %s, want 2", got)
+	}
+
+	// Mint payments go to the contract owner
+	paid := e.u256(e.token, e.tokenABI, "balance_of", map[string]any{"account": e.owner.GetAccountAddress()})
+	if paid.Sub(paid, ownerFunds).Int64() != 2*mintPrice {
+		t.Errorf("owner received %s, want %d", paid, 2*mintPrice)
+	}
+}
+
+func TestMintRequiresMintingEnabled(t *testing.T) {
+	e := newEnv(t)
+
+	err := e.invoke(e.user, e.ethrx, e.ethrxABI, "mint", map[string]any{
+		"amounts": []any{"1"},
+		"tos":     []any{e.user.GetAccountAddress()},
+	})
+	if !errors.Is(err, deploy.ErrReverted) {
+		t.Fatalf("mint with minting disabled: got %v, want a revert", err)
+	}
+}
+
+func TestEngrave(t *testing.T) {
+	e := newEnv(t)
+	e.enableMinting(1)
+	tokenID := e.mintToUser()
+
+	engraving := artifact("TITLE", "Hello from the devnet harness, longer than sixteen bytes")
+	e.mustInvoke(e.user, e.ethrx, e.ethrxABI, "engrave", map[string]any{
+		"token_ids": []any{tokenID.String()},
+		"artifacts": []any{engraving},
+	})
+
+	got := e.call(e.ethrx, e.ethrxABI, "get_artifacts", map[string]any{"token_ids": []any{tokenID.String()}})
+	want := e.encodeArtifacts(engraving)
+	if !equalFelts(got, want) {
+		t.Errorf("get_artifacts = %v, want %v", got, want)
+	}
+
+	// Only the token owner can engrave
+	err := e.invoke(e.owner, e.ethrx, e.ethrxABI, "engrave", map[string]any{
+		"token_ids": []any{tokenID.String()},
+		"artifacts": []any{artifact("TITLE", "not mine")},
+	})
+	if !errors.Is(err, deploy.ErrReverted) {
+		t.Errorf("engrave by non-owner: got %v, want a revert", err)
+	}
+}
+
+func TestTransferAndSaveArtifact(t *testing.T) {
+	e := newEnv(t)
+	e.enableMinting(1)
+	tokenID := e.mintToUser()
+
+	engraving := artifact("TITLE", "kept across transfers")
+	e.mustInvoke(e.user, e.ethrx, e.ethrxABI, "engrave", map[string]any{
+		"token_ids": []any{tokenID.String()},
+		"artifacts": []any{engraving},
+	})
+	artifactIDs := e.call(e.ethrx, e.ethrxABI, "token_ids_to_artifact_ids", map[string]any{"token_ids": []any{tokenID.String()}})
+
+	e.mustInvoke(e.user, e.ethrx, e.ethrxABI, "transfer_and_save_artifact", map[string]any{
+		"froms":     []any{e.user.GetAccountAddress()},
+		"tos":       []any{e.owner.GetAccountAddress()},
+		"token_ids": []any{tokenID.String()},
+	})
+
+	owner := e.call(e.ethrx, e.ethrxABI, "owner_of", map[string]any{"token_id": tokenID.String()})
+	if len(owner) != 1 || !owner[0].Equal(feltFromHex(t, e.owner.GetAccountAddress())) {
+		t.Errorf("owner_of = %v, want %s", owner, e.owner.GetAccountAddress())
+	}
+	if got := e.call(e.ethrx, e.ethrxABI, "token_ids_to_artifact_ids", map[string]any{"token_ids": []any{tokenID.String()}}); !equalFelts(got, artifactIDs) {
+		t.Errorf("artifact id changed from %v to %v", artifactIDs, got)
+	}
+	if got := e.call(e.ethrx, e.ethrxABI, "get_artifacts", map[string]any{"token_ids": []any{tokenID.String()}}); !equalFelts(got, e.encodeArtifacts(engraving)) {
+		t.Errorf("artifact not preserved: %v", got)
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	e := newEnv(t)
+
+	// Only the owner can upgrade
+	err := e.invoke(e.user, e.ethrx, e.ethrxABI, "upgrade_contract", map[string]any{"new_class_hash": e.ethrxClassHash})
+	if !errors.Is(err, deploy.ErrReverted) {
+		t.Fatalf("upgrade by non-owner: got %v, want a revert", err)
+	}
+
+	// Re-applying the current class exercises the upgrade path and bumps the version
+	e.mustInvoke(e.owner, e.ethrx, e.ethrxABI, "upgrade_contract", map[string]any{"new_class_hash": e.ethrxClassHash})
+
+	if version := e.call(e.ethrx, e.ethrxABI, "version", nil); len(version) != 1 || version[0].Uint64() != 2 {
+		t.Errorf("version after upgrade = %v, want 2", version)
+	}
+	classHash, err := e.client.ClassHashAt(e.ctx, blockLatest, e.ethrx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !classHash.Equal(feltFromHex(t, e.ethrxClassHash)) {
+		t.Errorf("class hash = %s, want %s", classHash, e.ethrxClassHash)
+	}
+}
+
+// encodeArtifacts returns the serialized Array<Artifact> get_artifacts should return
+func (e *env) encodeArtifacts(artifacts ...map[string]any) []*felt.Felt {
+	e.t.Helper()
+	items := make([]any, len(artifacts))
+	for i, a := range artifacts {
+		items[i] = a
+	}
+	encoded, err := e.ethrxABI.Encode("core::array::Array::<etheracts::types::engraving::Artifact>", items)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return encoded
+}
@@ -0,0 +1,59 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/ethrx"
+)
+
+func TestExport(t *testing.T) {
+	e := newEnv(t)
+	e.enableMinting(1)
+	minted := e.mintToUser()
+
+	snapshot, err := ethrx.TakeSnapshot(e.ctx, e.client, e.ethrx, ethrx.SnapshotOptions{ChunkSize: 7})
+	if err != nil {
+		t.Fatalf("TakeSnapshot: %v", err)
+	}
+
+	supply := e.u256(e.ethrx, e.ethrxABI, "total_supply", nil)
+	if len(snapshot.Tokens) != int(supply.Int64()) || snapshot.TotalSupply != supply.String() {
+		t.Fatalf("snapshot has %d tokens (total supply %s), want %s", len(snapshot.Tokens), snapshot.TotalSupply, supply)
+	}
+	if snapshot.ClassHash != feltFromHex(t, e.ethrxClassHash).String() {
+		t.Errorf("class hash = %s, want %s", snapshot.ClassHash, e.ethrxClassHash)
+	}
+
+	first := snapshot.Tokens[0]
+	if title, _ := first.Artifact.Get("TITLE"); first.TokenID != "1" || string(title) != "The Southpaw" {
+		t.Errorf("token %s TITLE = %q, want token 1 titled The Southpaw", first.TokenID, title)
+	}
+	last := snapshot.Tokens[len(snapshot.Tokens)-1]
+	if last.TokenID != minted.String() || last.Owner != feltFromHex(t, e.user.GetAccountAddress()).String() {
+		t.Errorf("last token = %s owned by %s, want %s owned by the user", last.TokenID, last.Owner, minted)
+	}
+
+	dir := t.TempDir()
+	manifest, err := ethrx.WriteExport(dir, "devnet", snapshot)
+	if err != nil {
+		t.Fatalf("WriteExport: %v", err)
+	}
+	for _, name := range []string{ethrx.SnapshotFile, ethrx.CSVFile, ethrx.NDJSONFile, ethrx.ManifestFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("missing %s: %v", name, err)
+		}
+	}
+	if manifest.BlockHash != snapshot.BlockHash || len(manifest.Files) != 3 {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	loaded, err := ethrx.LoadSnapshot(filepath.Join(dir, ethrx.SnapshotFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Tokens) != len(snapshot.Tokens) {
+		t.Errorf("reloaded %d tokens, want %d", len(loaded.Tokens), len(snapshot.Tokens))
+	}
+}
@@ -0,0 +1,66 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/contracts"
+	"github.com/NovemberFork/etheracts/integration/pkg/devnet"
+	"github.com/NovemberFork/etheracts/integration/pkg/ethrx"
+)
+
+func TestMigrate(t *testing.T) {
+	e := newEnv(t)
+	e.enableMinting(1)
+	minted := e.mintToUser()
+	e.mustInvoke(e.user, e.ethrx, e.ethrxABI, "engrave", map[string]any{
+		"token_ids": []any{minted.String()},
+		"artifacts": []any{artifact("TITLE", "Migrated with its engraving")},
+	})
+	// A plain transfer wipes token 1, so the new contract must clear its initial engraving
+	e.mustInvoke(e.owner, e.ethrx, e.ethrxABI, "transfer_from", map[string]any{
+		"from":     e.owner.GetAccountAddress(),
+		"to":       e.user.GetAccountAddress(),
+		"token_id": "1",
+	})
+
+	source, err := ethrx.TakeSnapshot(e.ctx, e.client, e.ethrx, ethrx.SnapshotOptions{})
+	if err != nil {
+		t.Fatalf("TakeSnapshot: %v", err)
+	}
+
+	sierra, casm := devnet.Artifacts(t, "Ethrx")
+	target := e.deploy("ethrx", map[string]string{
+		contracts.KeySierraPath: sierra,
+		contracts.KeyCasmPath:   casm,
+		"owner":                 e.owner.GetAccountAddress(),
+		"name":                  "Etheracts",
+		"symbol":                "Ethrx",
+		"mint_token":            source.MintToken,
+		"mint_price":            "0",
+		"max_supply":            source.MaxSupply,
+	})
+
+	report, err := ethrx.Migrate(e.ctx, e.client, e.owner, source, feltFromHex(t, target.DeployedAddress), ethrx.MigrateOptions{ABI: e.ethrxABI, BatchSize: 50})
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if !report.OK() || report.Matched != len(source.Tokens) {
+		t.Fatalf("reconciliation: %d/%d matched, settings %v", report.Matched, report.Tokens, report.Settings)
+	}
+
+	user := feltFromHex(t, e.user.GetAccountAddress()).String()
+	for _, check := range []ethrx.TokenCheck{report.Checks[0], report.Checks[len(report.Checks)-1]} {
+		if check.Owner != user {
+			t.Errorf("token %s owned by %s, want the user", check.TokenID, check.Owner)
+		}
+	}
+
+	// Running it again finds nothing left to do
+	again, err := ethrx.Migrate(e.ctx, e.client, e.owner, source, feltFromHex(t, target.DeployedAddress), ethrx.MigrateOptions{ABI: e.ethrxABI})
+	if err != nil {
+		t.Fatalf("resumed Migrate: %v", err)
+	}
+	if len(again.Transactions) != 0 || !again.OK() {
+		t.Errorf("resumed migration sent %d transactions, ok=%t", len(again.Transactions), again.OK())
+	}
+}
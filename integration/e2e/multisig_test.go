@@ -0,0 +1,119 @@
+package e2e
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/abi"
+	"github.com/NovemberFork/etheracts/integration/pkg/contracts"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+	"github.com/NovemberFork/etheracts/integration/pkg/signer"
+)
+
+// multisigArtifacts returns the Argent multisig class set in ARGENT_MULTISIG_SIERRA and
+// ARGENT_MULTISIG_CASM, e.g. the ArgentMultisigAccount artifacts published with
+// argent-contracts-starknet. The test is skipped when they are not set.
+func multisigArtifacts(t *testing.T) (sierraPath, casmPath string) {
+	t.Helper()
+	sierraPath, casmPath = os.Getenv("ARGENT_MULTISIG_SIERRA"), os.Getenv("ARGENT_MULTISIG_CASM")
+	if sierraPath == "" || casmPath == "" {
+		t.Skip("ARGENT_MULTISIG_SIERRA and ARGENT_MULTISIG_CASM not set")
+	}
+	return sierraPath, casmPath
+}
+
+// deployMultisig deploys a threshold-of-n Argent multisig with the public keys of the
+// given accounts as signers and funds it for fees
+func (e *env) deployMultisig(threshold uint64, signerKeys []string) *felt.Felt {
+	e.t.Helper()
+	sierraPath, casmPath := multisigArtifacts(e.t)
+	multisigABI, err := abi.Load(sierraPath)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+
+	// Multisig 0.2 takes Array<Signer>, each a Starknet variant; 0.1 takes public keys
+	_, guids := multisigABI.Functions["get_signer_guids"]
+	args := []*felt.Felt{new(felt.Felt).SetUint64(threshold), new(felt.Felt).SetUint64(uint64(len(signerKeys)))}
+	for _, key := range signerKeys {
+		if guids {
+			args = append(args, new(felt.Felt))
+		}
+		args = append(args, feltFromHex(e.t, key))
+	}
+
+	result, err := e.owner.DeployContract(e.ctx, deploy.ContractInfo{
+		Name:        "ArgentMultisig",
+		SierraPath:  sierraPath,
+		CasmPath:    casmPath,
+		Constructor: deploy.ConstructorArgs{Args: args},
+	})
+	if err != nil {
+		e.t.Fatalf("deploying the multisig: %s", err)
+	}
+	fees, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	if err := e.devnet.Mint(e.ctx, result.DeployedAddress, fees); err != nil {
+		e.t.Fatal(err)
+	}
+	return feltFromHex(e.t, result.DeployedAddress)
+}
+
+func TestMultisigProposal(t *testing.T) {
+	e := newEnv(t)
+	if len(e.accounts) < 3 {
+		t.Fatalf("need 3 predeployed accounts, got %d", len(e.accounts))
+	}
+
+	var signers []signer.Signer
+	var keys []string
+	for _, account := range e.accounts[:3] {
+		s, err := signer.NewLocalSignerFromHex(account.PrivateKey, account.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers = append(signers, s)
+		keys = append(keys, account.PublicKey)
+	}
+	multisig := e.deployMultisig(2, keys)
+	e.mustInvoke(e.owner, e.ethrx, e.ethrxABI, "transfer_ownership", map[string]any{"new_owner": multisig.String()})
+
+	call, err := contracts.BuildEthrxAdminCall(e.ethrxABI, e.ethrx.String(), "set_mint_price", []string{"5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proposal, err := e.owner.BuildProposal(e.ctx, multisig, "Ethrx set_mint_price(5)", []rpc.InvokeFunctionCall{call})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proposal.Threshold != 2 || len(proposal.Signers) != 3 {
+		t.Fatalf("proposal threshold %d of %d signers, want 2 of 3", proposal.Threshold, len(proposal.Signers))
+	}
+
+	// Signatures are collected out of signer order; the third signer is not needed
+	for _, s := range []signer.Signer{signers[1], signers[0]} {
+		if err := proposal.Sign(e.ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	txHash, err := e.owner.SubmitProposal(e.ctx, proposal)
+	if err != nil {
+		t.Fatalf("submitting the %s proposal: %s", proposal.Layout, err)
+	}
+	if _, err := e.owner.WaitForReceipt(e.ctx, txHash); err != nil {
+		t.Fatal(err)
+	}
+
+	if price := e.u256(e.ethrx, e.ethrxABI, "mint_price", nil); price.Int64() != 5 {
+		t.Errorf("mint_price = %s, want 5", price)
+	}
+	// The owner account can no longer change the settings itself
+	err = e.invoke(e.owner, e.ethrx, e.ethrxABI, "set_mint_price", map[string]any{"new_mint_price": "7"})
+	if !errors.Is(err, deploy.ErrReverted) {
+		t.Errorf("owner call after the transfer: got %v, want a revert", err)
+	}
+}
@@ -0,0 +1,109 @@
+package abi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Param is a named, typed function input or struct member
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Function is a constructor or external function from the ABI
+type Function struct {
+	Name   string  `json:"name"`
+	Inputs []Param `json:"inputs"`
+}
+
+// Struct is a Cairo struct definition
+type Struct struct {
+	Name    string  `json:"name"`
+	Members []Param `json:"members"`
+}
+
+// Enum is a Cairo enum definition; variants are serialized by index
+type Enum struct {
+	Name     string  `json:"name"`
+	Variants []Param `json:"variants"`
+}
+
+// ABI holds the parts of a Sierra contract ABI needed to encode calldata
+type ABI struct {
+	Constructor *Function
+	Functions   map[string]*Function
+	Structs     map[string]*Struct
+	Enums       map[string]*Enum
+}
+
+// entry is a raw ABI item; interfaces nest their functions under items
+type entry struct {
+	Type     string  `json:"type"`
+	Name     string  `json:"name"`
+	Inputs   []Param `json:"inputs"`
+	Members  []Param `json:"members"`
+	Variants []Param `json:"variants"`
+	Items    []entry `json:"items"`
+}
+
+// Load reads the ABI from a Sierra contract class file
+func Load(sierraPath string) (*ABI, error) {
+	data, err := os.ReadFile(sierraPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sierra contract %s: %w", sierraPath, err)
+	}
+
+	var class struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	if err := json.Unmarshal(data, &class); err != nil {
+		return nil, fmt.Errorf("failed to parse sierra contract %s: %w", sierraPath, err)
+	}
+	if len(class.ABI) == 0 {
+		return nil, fmt.Errorf("sierra contract %s has no ABI", sierraPath)
+	}
+
+	// Older compilers store the ABI as a JSON-encoded string
+	raw := []byte(class.ABI)
+	var nested string
+	if json.Unmarshal(raw, &nested) == nil {
+		raw = []byte(nested)
+	}
+
+	return Parse(raw)
+}
+
+// Parse decodes a Sierra ABI JSON array
+func Parse(data []byte) (*ABI, error) {
+	var entries []entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid ABI: %w", err)
+	}
+
+	a := &ABI{
+		Functions: make(map[string]*Function),
+		Structs:   make(map[string]*Struct),
+		Enums:     make(map[string]*Enum),
+	}
+	a.add(entries)
+	return a, nil
+}
+
+func (a *ABI) add(entries []entry) {
+	for _, e := range entries {
+		switch e.Type {
+		case "constructor":
+			a.Constructor = &Function{Name: e.Name, Inputs: e.Inputs}
+		case "function", "l1_handler":
+			a.Functions[e.Name] = &Function{Name: e.Name, Inputs: e.Inputs}
+		case "interface":
+			a.add(e.Items)
+		case "struct":
+			a.Structs[e.Name] = &Struct{Name: e.Name, Members: e.Members}
+		case "enum":
+			a.Enums[e.Name] = &Enum{Name: e.Name, Variants: e.Variants}
+		}
+	}
+}
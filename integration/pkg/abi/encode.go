@@ -0,0 +1,534 @@
+package abi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+// EncodeConstructor serializes constructor arguments keyed by input name.
+// See Encode for the accepted values.
+func (a *ABI) EncodeConstructor(args map[string]any) ([]*felt.Felt, error) {
+	if a.Constructor == nil {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("contract has no constructor but arguments were given")
+		}
+		return nil, nil
+	}
+	return a.EncodeInputs(a.Constructor, args)
+}
+
+// EncodeFunction serializes the arguments of an external function keyed by input name
+func (a *ABI) EncodeFunction(name string, args map[string]any) ([]*felt.Felt, error) {
+	fn, ok := a.Functions[name]
+	if !ok {
+		return nil, fmt.Errorf("function %s not found in ABI", name)
+	}
+	return a.EncodeInputs(fn, args)
+}
+
+// EncodeInputs serializes the inputs of fn from arguments keyed by input name.
+// Missing and unknown inputs are reported by name.
+func (a *ABI) EncodeInputs(fn *Function, args map[string]any) ([]*felt.Felt, error) {
+	calldata, err := a.encodeMembers(fn.Inputs, args, "argument")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name, err)
+	}
+	return calldata, nil
+}
+
+// Encode serializes a value of the given Cairo type. Values are Go values or decoded JSON:
+//   - felt252, ContractAddress, ClassHash and integers: decimal or 0x-prefixed hex strings,
+//     JSON numbers, Go integers, *big.Int or *felt.Felt
+//   - bool: true/false; ByteArray: string; bytes31: string of at most 31 bytes
+//   - Array, Span and tuples: arrays; structs: objects keyed by member name
+//   - enums: the variant name, or {"Variant": value}; Option: null for None, else the value
+//
+// Strings holding a JSON array, object or null are accepted for composite types, so
+// arguments can come from key/value configuration.
+func (a *ABI) Encode(typ string, value any) ([]*felt.Felt, error) {
+	var e encoder
+	e.abi = a
+	if err := e.encode(typ, value, ""); err != nil {
+		return nil, err
+	}
+	return e.calldata, nil
+}
+
+// EncodeError reports a value that does not match its Cairo type. Path names the
+// offending field, such as args.items[2].amount.
+type EncodeError struct {
+	Path string
+	Type string
+	Err  error
+}
+
+func (e *EncodeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %s", shortType(e.Type), e.Err)
+	}
+	return fmt.Sprintf("%s (%s): %s", e.Path, shortType(e.Type), e.Err)
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// encoder appends the serialization of values to calldata
+type encoder struct {
+	abi      *ABI
+	calldata []*felt.Felt
+}
+
+func (a *ABI) encodeMembers(members []Param, values map[string]any, kind string) ([]*felt.Felt, error) {
+	e := encoder{abi: a}
+	if err := e.members(members, values, "", kind); err != nil {
+		return nil, err
+	}
+	return e.calldata, nil
+}
+
+func (e *encoder) members(members []Param, values map[string]any, path, kind string) error {
+	known := make(map[string]bool, len(members))
+	for _, member := range members {
+		known[member.Name] = true
+		memberPath := join(path, member.Name)
+		value, ok := values[member.Name]
+		if !ok {
+			return &EncodeError{Path: memberPath, Type: member.Type, Err: fmt.Errorf("missing %s", kind)}
+		}
+		if err := e.encode(member.Type, value, memberPath); err != nil {
+			return err
+		}
+	}
+
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, join(path, name))
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown %ss %s", kind, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func (e *encoder) encode(typ string, value any, path string) error {
+	fail := func(format string, args ...any) error {
+		return &EncodeError{Path: path, Type: typ, Err: fmt.Errorf(format, args...)}
+	}
+
+	// Snapshots serialize like the underlying type
+	typ = strings.TrimPrefix(typ, "@")
+
+	switch typ {
+	case "core::felt252",
+		"core::starknet::contract_address::ContractAddress",
+		"core::starknet::class_hash::ClassHash",
+		"core::starknet::storage_access::StorageAddress":
+		n, err := toInt(value)
+		if err != nil {
+			return fail("%s", err)
+		}
+		if n.Sign() < 0 || n.Cmp(fieldPrime) >= 0 {
+			return fail("%s is outside the field", n)
+		}
+		e.append(n)
+		return nil
+	case "core::starknet::eth_address::EthAddress":
+		return e.uint(value, 160, fail)
+	case "core::integer::u256":
+		if members, ok := asObject(value); ok {
+			return e.members([]Param{{"low", "core::integer::u128"}, {"high", "core::integer::u128"}}, members, path, "member")
+		}
+		n, err := toInt(value)
+		if err != nil {
+			return fail("%s", err)
+		}
+		if n.Sign() < 0 || n.BitLen() > 256 {
+			return fail("%s does not fit in u256", n)
+		}
+		e.append(new(big.Int).And(n, maxU128), new(big.Int).Rsh(n, 128))
+		return nil
+	case "core::bool":
+		switch v := value.(type) {
+		case bool:
+			e.appendBool(v)
+			return nil
+		case string:
+			switch v {
+			case "true":
+				e.appendBool(true)
+				return nil
+			case "false":
+				e.appendBool(false)
+				return nil
+			}
+		}
+		return fail("expected true or false, got %v", value)
+	case "core::byte_array::ByteArray":
+		s, ok := value.(string)
+		if !ok {
+			return fail("expected a string, got %v", value)
+		}
+		e.calldata = append(e.calldata, encodeByteArray(s)...)
+		return nil
+	case "core::bytes_31::bytes31":
+		s, ok := value.(string)
+		if !ok || len(s) > 31 {
+			return fail("expected a string of at most 31 bytes")
+		}
+		e.calldata = append(e.calldata, new(felt.Felt).SetBytes([]byte(s)))
+		return nil
+	case "()":
+		return nil
+	}
+
+	if bits, ok := uintBits[typ]; ok {
+		return e.uint(value, bits, fail)
+	}
+	if bits, ok := intBits[typ]; ok {
+		n, err := toInt(value)
+		if err != nil {
+			return fail("%s", err)
+		}
+		limit := new(big.Int).Lsh(big.NewInt(1), bits-1)
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return fail("%s does not fit in i%d", n, bits)
+		}
+		// Negative integers are represented as P - |n|
+		if n.Sign() < 0 {
+			n = new(big.Int).Add(fieldPrime, n)
+		}
+		e.append(n)
+		return nil
+	}
+
+	if strings.HasPrefix(typ, "(") {
+		items, err := e.list(value)
+		if err != nil {
+			return fail("%s", err)
+		}
+		types := splitTypes(typ[1 : len(typ)-1])
+		if len(items) != len(types) {
+			return fail("expected %d tuple elements, got %d", len(types), len(items))
+		}
+		for i, itemType := range types {
+			if err := e.encode(itemType, items[i], fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	base, params := splitGeneric(typ)
+	switch base {
+	case "core::array::Array", "core::array::Span":
+		if len(params) != 1 {
+			return fail("malformed array type")
+		}
+		items, err := e.list(value)
+		if err != nil {
+			return fail("%s", err)
+		}
+		e.append(big.NewInt(int64(len(items))))
+		for i, item := range items {
+			if err := e.encode(params[0], item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "core::option::Option":
+		if len(params) != 1 {
+			return fail("malformed option type")
+		}
+		if isNull(value) {
+			e.append(big.NewInt(1)) // None
+			return nil
+		}
+		e.append(big.NewInt(0)) // Some
+		return e.encode(params[0], value, path)
+	}
+
+	if s, ok := e.abi.Structs[typ]; ok {
+		members, ok := asObject(value)
+		if !ok {
+			return fail("expected an object with members %s", memberNames(s.Members))
+		}
+		return e.members(s.Members, members, path, "member")
+	}
+	if enum, ok := e.abi.Enums[typ]; ok {
+		return e.enum(enum, value, path, fail)
+	}
+
+	return fail("unsupported type")
+}
+
+// enum serializes the variant index followed by the variant data
+func (e *encoder) enum(enum *Enum, value any, path string, fail func(string, ...any) error) error {
+	var name string
+	var data any
+	switch v := value.(type) {
+	case string:
+		if obj, ok := asObject(v); ok {
+			return e.enum(enum, obj, path, fail)
+		}
+		name = v
+	case map[string]any:
+		if len(v) != 1 {
+			return fail("expected exactly one variant, got %d", len(v))
+		}
+		for variant, variantData := range v {
+			name, data = variant, variantData
+		}
+	default:
+		return fail("expected a variant name or {\"Variant\": value}, one of %s", memberNames(enum.Variants))
+	}
+
+	for i, variant := range enum.Variants {
+		if variant.Name != name {
+			continue
+		}
+		e.append(big.NewInt(int64(i)))
+		if variant.Type == "()" {
+			return nil
+		}
+		return e.encode(variant.Type, data, join(path, name))
+	}
+	return fail("unknown variant %q, expected one of %s", name, memberNames(enum.Variants))
+}
+
+func (e *encoder) uint(value any, bits uint, fail func(string, ...any) error) error {
+	n, err := toInt(value)
+	if err != nil {
+		return fail("%s", err)
+	}
+	if n.Sign() < 0 || n.BitLen() > int(bits) {
+		return fail("%s does not fit in u%d", n, bits)
+	}
+	e.append(n)
+	return nil
+}
+
+// list returns the elements of an array value, parsing JSON strings
+func (e *encoder) list(value any) ([]any, error) {
+	switch v := value.(type) {
+	case []any:
+		return v, nil
+	case string:
+		var items []any
+		if err := decodeJSON(v, &items); err != nil {
+			return nil, fmt.Errorf("expected a JSON array: %w", err)
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("expected an array, got %v", value)
+}
+
+func (e *encoder) append(values ...*big.Int) {
+	for _, v := range values {
+		e.calldata = append(e.calldata, new(felt.Felt).SetBigInt(v))
+	}
+}
+
+func (e *encoder) appendBool(v bool) {
+	if v {
+		e.append(big.NewInt(1))
+	} else {
+		e.append(big.NewInt(0))
+	}
+}
+
+var (
+	fieldPrime, _ = new(big.Int).SetString("800000000000011000000000000000000000000000000000000000000000001", 16)
+	maxU128       = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+)
+
+// uintBits maps unsigned integer types that fit in a single felt to their width
+var uintBits = map[string]uint{
+	"core::integer::u8":   8,
+	"core::integer::u16":  16,
+	"core::integer::u32":  32,
+	"core::integer::u64":  64,
+	"core::integer::u128": 128,
+}
+
+// intBits maps signed integer types to their width
+var intBits = map[string]uint{
+	"core::integer::i8":   8,
+	"core::integer::i16":  16,
+	"core::integer::i32":  32,
+	"core::integer::i64":  64,
+	"core::integer::i128": 128,
+}
+
+// toInt converts a numeric value: decimal or 0x-prefixed hex strings, JSON numbers,
+// Go integers, *big.Int and *felt.Felt
+func toInt(value any) (*big.Int, error) {
+	switch v := value.(type) {
+	case string:
+		s := strings.TrimSpace(v)
+		var n *big.Int
+		var ok bool
+		if rest, found := strings.CutPrefix(s, "-"); found {
+			n, ok = parseUnsigned(rest)
+			n = new(big.Int).Neg(nonNil(n))
+		} else {
+			n, ok = parseUnsigned(s)
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid number %q", v)
+		}
+		return n, nil
+	case json.Number:
+		return toInt(v.String())
+	case float64:
+		if v != float64(int64(v)) {
+			return nil, fmt.Errorf("%v is not an integer", v)
+		}
+		return big.NewInt(int64(v)), nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	case *big.Int:
+		return new(big.Int).Set(v), nil
+	case *felt.Felt:
+		return v.BigInt(new(big.Int)), nil
+	}
+	return nil, fmt.Errorf("expected a number, got %v", value)
+}
+
+func parseUnsigned(s string) (*big.Int, bool) {
+	if hex, ok := strings.CutPrefix(strings.ToLower(s), "0x"); ok {
+		return new(big.Int).SetString(hex, 16)
+	}
+	return new(big.Int).SetString(s, 10)
+}
+
+func nonNil(n *big.Int) *big.Int {
+	if n == nil {
+		return new(big.Int)
+	}
+	return n
+}
+
+// asObject returns the members of an object value, parsing JSON strings
+func asObject(value any) (map[string]any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		return v, true
+	case string:
+		if !strings.HasPrefix(strings.TrimSpace(v), "{") {
+			return nil, false
+		}
+		var obj map[string]any
+		if decodeJSON(v, &obj) != nil {
+			return nil, false
+		}
+		return obj, true
+	}
+	return nil, false
+}
+
+func isNull(value any) bool {
+	if value == nil {
+		return true
+	}
+	s, ok := value.(string)
+	return ok && strings.TrimSpace(s) == "null"
+}
+
+// decodeJSON decodes keeping numbers exact
+func decodeJSON(s string, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(s)))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// splitGeneric splits "core::array::Array::<core::felt252>" into its base and parameters
+func splitGeneric(typ string) (string, []string) {
+	i := strings.Index(typ, "::<")
+	if i < 0 || !strings.HasSuffix(typ, ">") {
+		return typ, nil
+	}
+	return typ[:i], splitTypes(typ[i+3 : len(typ)-1])
+}
+
+// splitTypes splits a comma-separated type list at the top nesting level
+func splitTypes(list string) []string {
+	var types []string
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '<', '(':
+			depth++
+		case '>', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				types = append(types, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(list[start:]); last != "" {
+		types = append(types, last)
+	}
+	return types
+}
+
+func memberNames(members []Param) string {
+	names := make([]string, len(members))
+	for i, m := range members {
+		names[i] = m.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// encodeByteArray serializes a string as a Cairo ByteArray:
+// [data_len, data_words..., pending_word, pending_word_len]
+func encodeByteArray(s string) []*felt.Felt {
+	const wordSize = 31
+
+	data := []byte(s)
+	fullWords := len(data) / wordSize
+	result := []*felt.Felt{new(felt.Felt).SetUint64(uint64(fullWords))}
+	for i := 0; i < fullWords; i++ {
+		result = append(result, new(felt.Felt).SetBytes(data[i*wordSize:(i+1)*wordSize]))
+	}
+
+	pending := data[fullWords*wordSize:]
+	return append(result, new(felt.Felt).SetBytes(pending), new(felt.Felt).SetUint64(uint64(len(pending))))
+}
+
+// shortType strips module paths from a Cairo type for error messages:
+// core::array::Array::<core::felt252> → Array<felt252>
+func shortType(typ string) string {
+	out := strings.ReplaceAll(typ, "::<", "<")
+	for {
+		i := strings.Index(out, "::")
+		if i < 0 {
+			return out
+		}
+		// Drop the path segment before "::" back to the previous delimiter
+		j := strings.LastIndexAny(out[:i], "<(, @") + 1
+		out = out[:j] + out[i+2:]
+	}
+}
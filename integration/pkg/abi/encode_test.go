@@ -0,0 +1,225 @@
+package abi_test
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/abi"
+)
+
+// testABI has a struct, an enum, a constructor and an interface function
+const testABI = `[
+	{"type": "struct", "name": "test::Split", "members": [
+		{"name": "recipient", "type": "core::starknet::contract_address::ContractAddress"},
+		{"name": "amount", "type": "core::integer::u256"}
+	]},
+	{"type": "enum", "name": "test::Limit", "variants": [
+		{"name": "Off", "type": "()"},
+		{"name": "Amount", "type": "core::integer::u256"}
+	]},
+	{"type": "constructor", "name": "constructor", "inputs": [
+		{"name": "owner", "type": "core::starknet::contract_address::ContractAddress"},
+		{"name": "name", "type": "core::byte_array::ByteArray"}
+	]},
+	{"type": "interface", "name": "test::ISplitter", "items": [
+		{"type": "function", "name": "split", "inputs": [
+			{"name": "splits", "type": "core::array::Array::<test::Split>"},
+			{"name": "limit", "type": "test::Limit"}
+		]}
+	]}
+]`
+
+func parseTestABI(t *testing.T) *abi.ABI {
+	t.Helper()
+	a, err := abi.Parse([]byte(testABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func hexes(calldata []*felt.Felt) []string {
+	out := make([]string, len(calldata))
+	for i, f := range calldata {
+		out[i] = f.String()
+	}
+	return out
+}
+
+func TestEncode(t *testing.T) {
+	a := parseTestABI(t)
+	word := "0x" + strings.Repeat("61", 31)
+
+	tests := []struct {
+		name  string
+		typ   string
+		value any
+		want  []string
+	}{
+		{"felt decimal", "core::felt252", "255", []string{"0xff"}},
+		{"felt hex", "core::felt252", "0xFF", []string{"0xff"}},
+		{"felt JSON number", "core::felt252", float64(7), []string{"0x7"}},
+		{"felt big.Int", "core::felt252", big.NewInt(9), []string{"0x9"}},
+		{"felt snapshot", "@core::felt252", 3, []string{"0x3"}},
+		{"address", "core::starknet::contract_address::ContractAddress", "0x49d3", []string{"0x49d3"}},
+		{"u8", "core::integer::u8", "255", []string{"0xff"}},
+		{"u64", "core::integer::u64", uint64(1 << 63), []string{"0x8000000000000000"}},
+		{"i8 negative", "core::integer::i8", "-1", []string{"0x800000000000011000000000000000000000000000000000000000000000000"}},
+		{"u256 small", "core::integer::u256", "5", []string{"0x5", "0x0"}},
+		{"u256 low and high", "core::integer::u256", "340282366920938463463374607431768211461", []string{"0x5", "0x1"}},
+		{"u256 members", "core::integer::u256", map[string]any{"low": "1", "high": "2"}, []string{"0x1", "0x2"}},
+		{"bool", "core::bool", true, []string{"0x1"}},
+		{"bool string", "core::bool", "false", []string{"0x0"}},
+		{"ByteArray short", "core::byte_array::ByteArray", "hello", []string{"0x0", "0x68656c6c6f", "0x5"}},
+		{"ByteArray empty", "core::byte_array::ByteArray", "", []string{"0x0", "0x0", "0x0"}},
+		{"ByteArray full word", "core::byte_array::ByteArray", strings.Repeat("a", 33), []string{"0x1", word, "0x6161", "0x2"}},
+		{"bytes31", "core::bytes_31::bytes31", "abc", []string{"0x616263"}},
+		{"Option None", "core::option::Option::<core::integer::u64>", nil, []string{"0x1"}},
+		{"Option None string", "core::option::Option::<core::integer::u64>", "null", []string{"0x1"}},
+		{"Option Some", "core::option::Option::<core::integer::u64>", "5", []string{"0x0", "0x5"}},
+		{"array", "core::array::Array::<core::integer::u8>", []any{1, 2, 3}, []string{"0x3", "0x1", "0x2", "0x3"}},
+		{"span JSON string", "core::array::Span::<core::felt252>", `["0x1", 2]`, []string{"0x2", "0x1", "0x2"}},
+		{"empty array", "core::array::Array::<core::felt252>", []any{}, []string{"0x0"}},
+		{"tuple", "(core::felt252, core::bool)", []any{"0x1", true}, []string{"0x1", "0x1"}},
+		{"unit", "()", nil, []string{}},
+		{"struct", "test::Split", map[string]any{"recipient": "0xa", "amount": "7"}, []string{"0xa", "0x7", "0x0"}},
+		{"struct JSON string", "test::Split", `{"recipient": "0xa", "amount": 7}`, []string{"0xa", "0x7", "0x0"}},
+		{
+			"array of structs", "core::array::Array::<test::Split>",
+			`[{"recipient": "0xa", "amount": 1}, {"recipient": "0xb", "amount": 2}]`,
+			[]string{"0x2", "0xa", "0x1", "0x0", "0xb", "0x2", "0x0"},
+		},
+		{"enum unit variant", "test::Limit", "Off", []string{"0x0"}},
+		{"enum data variant", "test::Limit", map[string]any{"Amount": "7"}, []string{"0x1", "0x7", "0x0"}},
+		{"enum JSON string", "test::Limit", `{"Amount": 7}`, []string{"0x1", "0x7", "0x0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calldata, err := a.Encode(tt.typ, tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hexes(calldata); !slices.Equal(got, tt.want) {
+				t.Errorf("Encode(%s, %v) = %v, want %v", tt.typ, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	a := parseTestABI(t)
+	prime, _ := new(big.Int).SetString("800000000000011000000000000000000000000000000000000000000000001", 16)
+
+	tests := []struct {
+		name     string
+		typ      string
+		value    any
+		wantPath string
+		wantErr  string
+	}{
+		{"felt wrong type", "core::felt252", true, "", "expected a number"},
+		{"felt invalid string", "core::felt252", "0xzz", "", `invalid number "0xzz"`},
+		{"felt fraction", "core::felt252", 1.5, "", "not an integer"},
+		{"felt outside field", "core::felt252", prime, "", "outside the field"},
+		{"u8 overflow", "core::integer::u8", 256, "", "does not fit in u8"},
+		{"u64 negative", "core::integer::u64", "-1", "", "does not fit in u64"},
+		{"i8 overflow", "core::integer::i8", 128, "", "does not fit in i8"},
+		{"u256 negative", "core::integer::u256", "-5", "", "does not fit in u256"},
+		{"u256 missing high", "core::integer::u256", map[string]any{"low": "1"}, "high", "missing member"},
+		{"bool wrong value", "core::bool", "yes", "", "expected true or false"},
+		{"ByteArray wrong type", "core::byte_array::ByteArray", 5, "", "expected a string"},
+		{"bytes31 too long", "core::bytes_31::bytes31", strings.Repeat("a", 32), "", "at most 31 bytes"},
+		{"array wrong type", "core::array::Array::<core::felt252>", 5, "", "expected an array"},
+		{"array invalid JSON", "core::array::Array::<core::felt252>", "[1,", "", "expected a JSON array"},
+		{"array element", "core::array::Array::<core::integer::u8>", []any{1, 300}, "[1]", "does not fit in u8"},
+		{"tuple length", "(core::felt252, core::bool)", []any{"0x1"}, "", "expected 2 tuple elements"},
+		{"Option Some wrong type", "core::option::Option::<core::bool>", 5, "", "expected true or false"},
+		{"struct wrong type", "test::Split", "0xa", "", "expected an object with members recipient, amount"},
+		{"struct missing member", "test::Split", map[string]any{"recipient": "0xa"}, "amount", "missing member"},
+		{"nested member", "core::array::Array::<test::Split>", `[{"recipient": "0xa", "amount": -1}]`, "[0].amount", "does not fit in u256"},
+		{"enum unknown variant", "test::Limit", "On", "", `unknown variant "On"`},
+		{"enum two variants", "test::Limit", map[string]any{"Off": nil, "Amount": 1}, "", "exactly one variant"},
+		{"enum wrong type", "test::Limit", 1, "", "expected a variant name"},
+		{"enum variant data", "test::Limit", map[string]any{"Amount": "x"}, "Amount", "invalid number"},
+		{"unknown type", "test::Missing", "0x1", "", "unsupported type"},
+		{"unknown type in array", "core::array::Array::<test::Missing>", []any{1}, "[0]", "unsupported type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := a.Encode(tt.typ, tt.value)
+			var encodeErr *abi.EncodeError
+			if !errors.As(err, &encodeErr) {
+				t.Fatalf("err = %v, want an EncodeError", err)
+			}
+			if encodeErr.Path != tt.wantPath || !strings.Contains(encodeErr.Err.Error(), tt.wantErr) {
+				t.Errorf("err = %q at %q, want %q at %q", encodeErr.Err, encodeErr.Path, tt.wantErr, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestEncodeFunction(t *testing.T) {
+	a := parseTestABI(t)
+
+	calldata, err := a.EncodeFunction("split", map[string]any{
+		"splits": `[{"recipient": "0xa", "amount": 1}]`,
+		"limit":  "Off",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hexes(calldata), []string{"0x1", "0xa", "0x1", "0x0", "0x0"}; !slices.Equal(got, want) {
+		t.Errorf("calldata = %v, want %v", got, want)
+	}
+
+	calldata, err = a.EncodeConstructor(map[string]any{"owner": "0x1", "name": "Ethrx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hexes(calldata), []string{"0x1", "0x0", "0x4574687278", "0x5"}; !slices.Equal(got, want) {
+		t.Errorf("constructor calldata = %v, want %v", got, want)
+	}
+}
+
+func TestEncodeFunctionErrors(t *testing.T) {
+	a := parseTestABI(t)
+
+	// A missing argument is an EncodeError naming the input, prefixed with the function
+	_, err := a.EncodeFunction("split", map[string]any{"splits": "[]"})
+	var encodeErr *abi.EncodeError
+	if !errors.As(err, &encodeErr) || encodeErr.Path != "limit" {
+		t.Fatalf("err = %v, want an EncodeError for limit", err)
+	}
+	if want := "split: limit (Limit): missing argument"; err.Error() != want {
+		t.Errorf("err = %q, want %q", err, want)
+	}
+
+	_, err = a.EncodeFunction("split", map[string]any{
+		"splits": `[{"recipient": "0xa", "amount": 1, "memo": "x"}]`,
+		"limit":  "Off",
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown members splits[0].memo") {
+		t.Errorf("err = %v, want the unknown member reported", err)
+	}
+
+	_, err = a.EncodeFunction("split", map[string]any{"splits": "[]", "limit": "Off", "fee": 1, "deadline": 2})
+	if err == nil || !strings.Contains(err.Error(), "unknown arguments deadline, fee") {
+		t.Errorf("err = %v, want the unknown arguments reported", err)
+	}
+
+	if _, err := a.EncodeFunction("merge", nil); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("err = %v, want an unknown function", err)
+	}
+
+	noConstructor, _ := abi.Parse([]byte(`[]`))
+	if _, err := noConstructor.EncodeConstructor(map[string]any{"owner": "0x1"}); err == nil {
+		t.Error("arguments accepted without a constructor")
+	}
+}
@@ -1,12 +1,14 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/NethermindEth/starknet.go/utils"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 )
@@ -25,6 +27,11 @@ type NetworkConfig struct {
 	Name    string `json:"name"`
 	RPCURL  string `json:"rpc_url"`
 	ChainID string `json:"chain_id"`
+
+	// Extra endpoints used when RPCURL fails, in priority order
+	FallbackRPCURLs []string `json:"fallback_rpc_urls"`
+	RPCRateLimit    float64  `json:"rpc_rate_limit"`
+	RPCMaxRetries   int      `json:"rpc_max_retries"`
 }
 
 // DeployerConfig holds deployer account configuration
@@ -32,6 +39,16 @@ type DeployerConfig struct {
 	Address    string `json:"address"`
 	PrivateKey string `json:"private_key"`
 	PublicKey  string `json:"public_key"`
+
+	// Encrypted keystore (starkli/Argent JSON format), used instead of PrivateKey
+	KeystorePath string `json:"keystore_path"`
+	PasswordFile string `json:"password_file"`
+
+	// External signing process, used instead of a local key. SignerCommand is the argv of
+	// the process, so paths and arguments may contain spaces.
+	SignerCommand []string      `json:"signer_command"`
+	SignerSocket  string        `json:"signer_socket"`
+	SignerTimeout time.Duration `json:"signer_timeout"`
 }
 
 // ContractsConfig holds contract-specific configuration
@@ -60,12 +77,25 @@ type DeploymentConfig struct {
 	DeclarationDelay time.Duration `json:"declaration_delay"`
 	MaxFee           string        `json:"max_fee"`
 	GasPrice         string        `json:"gas_price"`
+
+	// Transaction waiting
+	ReceiptTimeout  time.Duration `json:"receipt_timeout"`
+	PollInterval    time.Duration `json:"poll_interval"`
+	MaxPollInterval time.Duration `json:"max_poll_interval"`
+	WaitForL1       bool          `json:"wait_for_l1"`
+
+	// Local nonce allocation for pipelined transactions
+	NonceStateDir string `json:"nonce_state_dir"`
+	MaxInFlight   int    `json:"max_in_flight"`
 }
 
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
 	Level   string `json:"level"`
 	Verbose bool   `json:"verbose"`
+
+	// Listen address of the Prometheus metrics endpoint, e.g. 127.0.0.1:9464; empty disables it
+	MetricsAddr string `json:"metrics_addr"`
 }
 
 // LoadConfig loads configuration from environment variables
@@ -154,10 +184,34 @@ func loadNetworkConfig() (*NetworkConfig, error) {
 		return nil, fmt.Errorf("unsupported network: %s", network)
 	}
 
+	chainID, err := getChainID(network)
+	if err != nil {
+		return nil, err
+	}
+
+	var fallbacks []string
+	for _, u := range strings.Split(os.Getenv(strings.ToUpper(network)+"_RPC_FALLBACK_URLS"), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			fallbacks = append(fallbacks, u)
+		}
+	}
+
+	rateLimit, err := strconv.ParseFloat(getEnvOrDefault("RPC_RATE_LIMIT", "10"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RPC_RATE_LIMIT: %w", err)
+	}
+	maxRetries, err := strconv.Atoi(getEnvOrDefault("RPC_MAX_RETRIES", "3"))
+	if err != nil || maxRetries < 0 {
+		return nil, fmt.Errorf("invalid RPC_MAX_RETRIES: %s", os.Getenv("RPC_MAX_RETRIES"))
+	}
+
 	return &NetworkConfig{
-		Name:    network,
-		RPCURL:  rpcURL,
-		ChainID: getChainID(network),
+		Name:            network,
+		RPCURL:          rpcURL,
+		ChainID:         chainID,
+		FallbackRPCURLs: fallbacks,
+		RPCRateLimit:    rateLimit,
+		RPCMaxRetries:   maxRetries,
 	}, nil
 }
 
@@ -167,33 +221,77 @@ func loadDeployerConfig() (*DeployerConfig, error) {
 		network = "local"
 	}
 
-	var address, privateKey, publicKey string
-	switch network {
-	case "local":
-		address = os.Getenv("LOCAL_DEPLOYER_ADDRESS")
-		privateKey = os.Getenv("LOCAL_DEPLOYER_PRIVATE_KEY")
-		publicKey = os.Getenv("LOCAL_DEPLOYER_PUBLIC_KEY")
-	case "testnet":
-		address = os.Getenv("TESTNET_DEPLOYER_ADDRESS")
-		privateKey = os.Getenv("TESTNET_DEPLOYER_PRIVATE_KEY")
-		publicKey = os.Getenv("TESTNET_DEPLOYER_PUBLIC_KEY")
-	case "mainnet":
-		address = os.Getenv("MAINNET_DEPLOYER_ADDRESS")
-		privateKey = os.Getenv("MAINNET_DEPLOYER_PRIVATE_KEY")
-		publicKey = os.Getenv("MAINNET_DEPLOYER_PUBLIC_KEY")
-	default:
+	prefix := strings.ToUpper(network)
+	if network != "local" && network != "testnet" && network != "mainnet" {
 		return nil, fmt.Errorf("unsupported network: %s", network)
 	}
 
-	if address == "" || privateKey == "" || publicKey == "" {
-		return nil, fmt.Errorf("missing required deployer environment variables for %s: %s_DEPLOYER_ADDRESS, %s_DEPLOYER_PRIVATE_KEY, %s_DEPLOYER_PUBLIC_KEY",
-			strings.ToUpper(network), strings.ToUpper(network), strings.ToUpper(network), strings.ToUpper(network))
+	address := os.Getenv(prefix + "_DEPLOYER_ADDRESS")
+	privateKey := os.Getenv(prefix + "_DEPLOYER_PRIVATE_KEY")
+	publicKey := os.Getenv(prefix + "_DEPLOYER_PUBLIC_KEY")
+	keystorePath := os.Getenv(prefix + "_DEPLOYER_KEYSTORE")
+	passwordFile := os.Getenv(prefix + "_DEPLOYER_PASSWORD_FILE")
+	signerCommand, err := parseCommand(os.Getenv(prefix + "_DEPLOYER_SIGNER_COMMAND"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s_DEPLOYER_SIGNER_COMMAND: %w", prefix, err)
+	}
+	signerSocket := os.Getenv(prefix + "_DEPLOYER_SIGNER_SOCKET")
+
+	if address == "" {
+		return nil, fmt.Errorf("%s_DEPLOYER_ADDRESS is required", prefix)
+	}
+
+	// External signers keep the key off this machine entirely
+	if len(signerCommand) > 0 || signerSocket != "" {
+		if len(signerCommand) > 0 && signerSocket != "" {
+			return nil, fmt.Errorf("both %s_DEPLOYER_SIGNER_COMMAND and %s_DEPLOYER_SIGNER_SOCKET are set, use only one", prefix, prefix)
+		}
+		if privateKey != "" || keystorePath != "" {
+			return nil, fmt.Errorf("%s deployer key must not be configured when using an external signer", network)
+		}
+		if publicKey == "" {
+			return nil, fmt.Errorf("%s_DEPLOYER_PUBLIC_KEY is required when using an external signer", prefix)
+		}
+
+		timeoutStr := getEnvOrDefault("SIGNER_TIMEOUT", "300")
+		timeout, err := strconv.Atoi(timeoutStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SIGNER_TIMEOUT: %w", err)
+		}
+
+		return &DeployerConfig{
+			Address:       address,
+			PublicKey:     publicKey,
+			SignerCommand: signerCommand,
+			SignerSocket:  signerSocket,
+			SignerTimeout: time.Duration(timeout) * time.Second,
+		}, nil
+	}
+	// No key at all is allowed: offline workflows build and broadcast without signing here
+	if privateKey != "" && keystorePath != "" {
+		return nil, fmt.Errorf("both %s_DEPLOYER_KEYSTORE and %s_DEPLOYER_PRIVATE_KEY are set, use only one", prefix, prefix)
+	}
+	if privateKey != "" && publicKey == "" {
+		return nil, fmt.Errorf("%s_DEPLOYER_PUBLIC_KEY is required when using %s_DEPLOYER_PRIVATE_KEY", prefix, prefix)
+	}
+
+	// Plaintext keys in the environment are refused on mainnet unless explicitly allowed
+	if network == "mainnet" && privateKey != "" {
+		allow, err := strconv.ParseBool(getEnvOrDefault("MAINNET_ALLOW_PLAINTEXT_KEY", "false"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAINNET_ALLOW_PLAINTEXT_KEY value: %w", err)
+		}
+		if !allow {
+			return nil, fmt.Errorf("plaintext MAINNET_DEPLOYER_PRIVATE_KEY is refused: use MAINNET_DEPLOYER_KEYSTORE or set MAINNET_ALLOW_PLAINTEXT_KEY=true")
+		}
 	}
 
 	return &DeployerConfig{
-		Address:    address,
-		PrivateKey: privateKey,
-		PublicKey:  publicKey,
+		Address:      address,
+		PrivateKey:   privateKey,
+		PublicKey:    publicKey,
+		KeystorePath: keystorePath,
+		PasswordFile: passwordFile,
 	}, nil
 }
 
@@ -235,18 +333,8 @@ func loadEthrxConfig() (*EthrxConfig, error) {
 		return nil, fmt.Errorf("unsupported network: %s", network)
 	}
 
-	if owner == "" {
-		return nil, fmt.Errorf("%s_ETHRX_OWNER is required", strings.ToUpper(network))
-	}
-	if mintToken == "" {
-		return nil, fmt.Errorf("%s_ETHRX_MINT_TOKEN is required", strings.ToUpper(network))
-	}
-	if mintPrice == "" {
-		return nil, fmt.Errorf("%s_ETHRX_MINT_PRICE is required", strings.ToUpper(network))
-	}
-	if maxSupply == "" {
-		return nil, fmt.Errorf("%s_ETHRX_MAX_SUPPLY is required", strings.ToUpper(network))
-	}
+	// Owner, mint token, price and supply are checked by the Ethrx deployer, since
+	// deployment plans may provide them instead of the environment
 
 	name := getEnvOrDefault("ETHRX_NAME", "Etheracts")
 	symbol := getEnvOrDefault("ETHRX_SYMBOL", "Ethrx")
@@ -293,10 +381,41 @@ func loadDeploymentConfig() (*DeploymentConfig, error) {
 		return nil, fmt.Errorf("invalid %s_DECLARATION_DELAY: %w", strings.ToUpper(network), err)
 	}
 
+	receiptTimeout, err := strconv.Atoi(getEnvOrDefault("RECEIPT_TIMEOUT", "300"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RECEIPT_TIMEOUT: %w", err)
+	}
+	pollInterval, err := strconv.Atoi(getEnvOrDefault("RECEIPT_POLL_INTERVAL", "1"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RECEIPT_POLL_INTERVAL: %w", err)
+	}
+	maxPollInterval, err := strconv.Atoi(getEnvOrDefault("RECEIPT_MAX_POLL_INTERVAL", "15"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RECEIPT_MAX_POLL_INTERVAL: %w", err)
+	}
+	if pollInterval <= 0 || maxPollInterval < pollInterval {
+		return nil, fmt.Errorf("RECEIPT_POLL_INTERVAL must be positive and at most RECEIPT_MAX_POLL_INTERVAL")
+	}
+	waitForL1, err := strconv.ParseBool(getEnvOrDefault("WAIT_FOR_L1", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WAIT_FOR_L1 value: %w", err)
+	}
+
+	maxInFlight, err := strconv.Atoi(getEnvOrDefault("MAX_IN_FLIGHT", "8"))
+	if err != nil || maxInFlight < 1 {
+		return nil, fmt.Errorf("invalid MAX_IN_FLIGHT: %s", os.Getenv("MAX_IN_FLIGHT"))
+	}
+
 	return &DeploymentConfig{
 		DeclarationDelay: time.Duration(delay) * time.Second,
 		MaxFee:           getEnvOrDefault("MAX_FEE", "1000000000000000"),
 		GasPrice:         getEnvOrDefault("GAS_PRICE", "1000000000"),
+		ReceiptTimeout:   time.Duration(receiptTimeout) * time.Second,
+		PollInterval:     time.Duration(pollInterval) * time.Second,
+		MaxPollInterval:  time.Duration(maxPollInterval) * time.Second,
+		WaitForL1:        waitForL1,
+		NonceStateDir:    getEnvOrDefault("NONCE_STATE_DIR", ".nonces"),
+		MaxInFlight:      maxInFlight,
 	}, nil
 }
 
@@ -309,8 +428,9 @@ func loadLoggingConfig() (*LoggingConfig, error) {
 	}
 
 	return &LoggingConfig{
-		Level:   level,
-		Verbose: verbose,
+		Level:       level,
+		Verbose:     verbose,
+		MetricsAddr: os.Getenv("METRICS_ADDR"),
 	}, nil
 }
 
@@ -321,28 +441,70 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
-func getChainID(network string) string {
+// getChainID returns the hex-encoded chain ID the network profile must be connected to.
+// Local devnets default to SN_SEPOLIA (starknet-devnet's default) and can be
+// overridden with LOCAL_CHAIN_ID when the devnet is started with another chain.
+func getChainID(network string) (string, error) {
 	switch network {
 	case "local":
-		return "0x534e5f4d41494e" // SN_MAIN
+		return parseChainID(getEnvOrDefault("LOCAL_CHAIN_ID", "SN_SEPOLIA"))
 	case "testnet":
-		return "0x534e5f474f45524c49" // SN_GOERLI
+		return utils.StrToHex("SN_SEPOLIA"), nil
 	case "mainnet":
-		return "0x534e5f4d41494e" // SN_MAIN
+		return utils.StrToHex("SN_MAIN"), nil
 	default:
-		return "0x534e5f4d41494e"
+		return "", fmt.Errorf("unsupported network: %s", network)
 	}
 }
 
+// parseChainID accepts either a hex chain ID (0x534e5f4d41494e) or its short string form (SN_MAIN)
+func parseChainID(value string) (string, error) {
+	if strings.HasPrefix(value, "0x") {
+		if _, err := utils.HexToFelt(value); err != nil {
+			return "", fmt.Errorf("invalid chain ID %s: %w", value, err)
+		}
+		return value, nil
+	}
+	if value == "" || len(value) > 31 {
+		return "", fmt.Errorf("invalid chain ID: %q", value)
+	}
+	return utils.StrToHex(value), nil
+}
+
+// parseCommand reads a command line given either as a JSON array of arguments
+// (["/opt/kms bridge/sign", "--profile", "prod"]) or as the path of a program run
+// without arguments. Values are never split on spaces, so paths may contain them.
+func parseCommand(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(value, "[") {
+		return []string{value}, nil
+	}
+
+	var argv []string
+	if err := json.Unmarshal([]byte(value), &argv); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of strings: %w", err)
+	}
+	if len(argv) == 0 || argv[0] == "" {
+		return nil, fmt.Errorf("command is empty")
+	}
+	return argv, nil
+}
+
 // ValidateConfig validates the configuration
 func (c *Config) ValidateConfig() error {
 	// Validate network configuration
 	if c.Network.RPCURL == "" {
 		return fmt.Errorf("network RPC URL is required")
 	}
+	if c.Network.ChainID == "" {
+		return fmt.Errorf("network chain ID is required")
+	}
 
 	// Validate deployer configuration
-	if c.Deployer.Address == "" || c.Deployer.PrivateKey == "" || c.Deployer.PublicKey == "" {
+	if c.Deployer.Address == "" {
 		return fmt.Errorf("deployer configuration is incomplete")
 	}
 
@@ -354,9 +516,31 @@ func (c *Config) ValidateConfig() error {
 	return nil
 }
 
-// GetRPCURL returns the RPC URL for the configured network
-func (c *Config) GetRPCURL() string {
-	return c.Network.RPCURL
+// ValidateSigner checks that the deployer can sign, with a private key, a keystore or an
+// external signer. Read-only and offline build/broadcast commands run without one.
+func (c *Config) ValidateSigner() error {
+	d := c.Deployer
+	if d.PrivateKey == "" && d.KeystorePath == "" && len(d.SignerCommand) == 0 && d.SignerSocket == "" {
+		prefix := strings.ToUpper(c.Network.Name)
+		return fmt.Errorf("deployer configuration is incomplete: set %s_DEPLOYER_KEYSTORE, %s_DEPLOYER_PRIVATE_KEY or an external signer",
+			prefix, prefix)
+	}
+	return nil
+}
+
+// GetRPCURLs returns the primary RPC URL followed by the fallbacks
+func (c *Config) GetRPCURLs() []string {
+	return append([]string{c.Network.RPCURL}, c.Network.FallbackRPCURLs...)
+}
+
+// GetChainID returns the expected chain ID for the configured network
+func (c *Config) GetChainID() string {
+	return c.Network.ChainID
+}
+
+// UsesKeystore returns whether the deployer key is loaded from an encrypted keystore
+func (c *Config) UsesKeystore() bool {
+	return c.Deployer.KeystorePath != ""
 }
 
 // IsVerbose returns whether verbose logging is enabled
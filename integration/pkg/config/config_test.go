@@ -0,0 +1,47 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	for _, tc := range []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "/usr/local/bin/kms-sign", want: []string{"/usr/local/bin/kms-sign"}},
+		{value: "/opt/kms bridge/sign", want: []string{"/opt/kms bridge/sign"}},
+		{value: `["/opt/kms bridge/sign", "--profile", "prod env"]`, want: []string{"/opt/kms bridge/sign", "--profile", "prod env"}},
+		{value: `["/opt/sign", "--label", "it's \"quoted\""]`, want: []string{"/opt/sign", "--label", `it's "quoted"`}},
+		{value: `[]`, wantErr: true},
+		{value: `[""]`, wantErr: true},
+		{value: `["/opt/sign",`, wantErr: true},
+	} {
+		got, err := parseCommand(tc.value)
+		if (err != nil) != tc.wantErr || !slices.Equal(got, tc.want) {
+			t.Errorf("parseCommand(%q) = %q, %v", tc.value, got, err)
+		}
+	}
+}
+
+func TestValidateSigner(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		deployer DeployerConfig
+		wantErr  bool
+	}{
+		{"private key", DeployerConfig{Address: "0x1", PrivateKey: "0x2", PublicKey: "0x3"}, false},
+		{"keystore", DeployerConfig{Address: "0x1", KeystorePath: "deployer.json"}, false},
+		{"signer command", DeployerConfig{Address: "0x1", PublicKey: "0x3", SignerCommand: []string{"/opt/sign"}}, false},
+		{"signer socket", DeployerConfig{Address: "0x1", PublicKey: "0x3", SignerSocket: "/run/sign.sock"}, false},
+		{"no key", DeployerConfig{Address: "0x1", PublicKey: "0x3"}, true},
+	} {
+		cfg := &Config{Network: NetworkConfig{Name: "testnet"}, Deployer: tc.deployer}
+		if err := cfg.ValidateSigner(); (err != nil) != tc.wantErr {
+			t.Errorf("%s: ValidateSigner() = %v", tc.name, err)
+		}
+	}
+}
@@ -1,17 +1,49 @@
 package contracts
 
 import (
+	"context"
 	"fmt"
-	"math/big"
+	"strings"
 
 	"github.com/NethermindEth/juno/core/felt"
-	"github.com/NethermindEth/starknet.go/utils"
 	"github.com/sirupsen/logrus"
 
+	"github.com/NovemberFork/etheracts/integration/pkg/abi"
 	"github.com/NovemberFork/etheracts/integration/pkg/config"
 	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
 )
 
+func init() {
+	Register(Registration{
+		Name:         "ethrx",
+		ContractName: "Ethrx",
+		Description:  "Etheracts NFT collection (defaults from <NETWORK>_ETHRX_* and ETHRX_*)",
+		Schema: []ConfigField{
+			{Key: "owner", Description: "contract owner address", Required: true},
+			{Key: "name", Description: "collection name", Required: true},
+			{Key: "symbol", Description: "collection symbol", Required: true},
+			{Key: "base_uri", Description: "token metadata base URI"},
+			{Key: "contract_uri", Description: "collection metadata URI"},
+			{Key: "mint_token", Description: "ERC20 used to pay for mints", Required: true},
+			{Key: "mint_price", Description: "mint price in mint_token base units", Required: true},
+			{Key: "max_supply", Description: "maximum number of tokens", Required: true},
+		},
+		Defaults: func(cfg *config.Config) map[string]string {
+			if cfg == nil {
+				return nil
+			}
+			return configValues(cfg.Contracts.Ethrx)
+		},
+		Encode: func(values map[string]string) ([]*felt.Felt, error) {
+			var ethrxConfig config.EthrxConfig
+			if err := ApplyOverrides(&ethrxConfig, withoutContractName(values)); err != nil {
+				return nil, err
+			}
+			return encodeEthrxConstructor(&ethrxConfig)
+		},
+	})
+}
+
 // EthrxDeployer handles Ethrx contract deployment
 type EthrxDeployer struct {
 	deployer *deploy.Deployer
@@ -19,8 +51,11 @@ type EthrxDeployer struct {
 	logger   *logrus.Logger
 }
 
-// NewEthrxDeployer creates a new Ethrx deployer
+// NewEthrxDeployer creates a new Ethrx deployer. A nil logger uses the deployer's logger.
 func NewEthrxDeployer(deployer *deploy.Deployer, config *config.EthrxConfig, logger *logrus.Logger) *EthrxDeployer {
+	if logger == nil {
+		logger = deployer.Logger()
+	}
 	return &EthrxDeployer{
 		deployer: deployer,
 		config:   config,
@@ -29,7 +64,7 @@ func NewEthrxDeployer(deployer *deploy.Deployer, config *config.EthrxConfig, log
 }
 
 // Deploy deploys the Ethrx contract
-func (e *EthrxDeployer) Deploy() (*deploy.DeploymentResult, error) {
+func (e *EthrxDeployer) Deploy(ctx context.Context) (*deploy.DeploymentResult, error) {
 	e.logger.Info("🚀 Deploying Ethrx Contract")
 	e.logger.Info("=====================================")
 
@@ -50,7 +85,7 @@ func (e *EthrxDeployer) Deploy() (*deploy.DeploymentResult, error) {
 	}
 
 	// Deploy the contract
-	result, err := e.deployer.DeployContract(contractInfo)
+	result, err := e.deployer.DeployContract(ctx, contractInfo)
 	if err != nil {
 		return nil, fmt.Errorf("deployment failed: %w", err)
 	}
@@ -72,8 +107,18 @@ func (e *EthrxDeployer) GetContractName() string {
 
 // ValidateConfig validates the Ethrx configuration
 func (e *EthrxDeployer) ValidateConfig() error {
+	prefix := strings.ToUpper(e.deployer.GetNetwork())
 	if e.config.Owner == "" {
-		return fmt.Errorf("owner address is required")
+		return fmt.Errorf("owner address is required (set %s_ETHRX_OWNER)", prefix)
+	}
+	if e.config.MintToken == "" {
+		return fmt.Errorf("mint token address is required (set %s_ETHRX_MINT_TOKEN)", prefix)
+	}
+	if e.config.MintPrice == "" {
+		return fmt.Errorf("mint price is required (set %s_ETHRX_MINT_PRICE)", prefix)
+	}
+	if e.config.MaxSupply == "" {
+		return fmt.Errorf("max supply is required (set %s_ETHRX_MAX_SUPPLY)", prefix)
 	}
 	if e.config.Name == "" {
 		return fmt.Errorf("contract name is required")
@@ -90,69 +135,19 @@ func (e *EthrxDeployer) ValidateConfig() error {
 	return nil
 }
 
+// ConstructorCalldata returns the serialized constructor arguments from the configuration
+func (e *EthrxDeployer) ConstructorCalldata() ([]*felt.Felt, error) {
+	return e.buildConstructorArgs()
+}
+
 // buildConstructorArgs builds the constructor arguments for Ethrx contract
 func (e *EthrxDeployer) buildConstructorArgs() ([]*felt.Felt, error) {
 	e.logger.Debug("🔧 Building constructor arguments...")
 
-	var calldata []*felt.Felt
-
-	// Convert owner address
-	owner, err := utils.HexToFelt(e.config.Owner)
-	if err != nil {
-		return nil, fmt.Errorf("invalid owner address: %w", err)
-	}
-	calldata = append(calldata, owner)
-
-	// Convert name to ByteArray (Cairo ByteArray structure)
-	nameFelts, err := stringToByteArray(e.config.Name)
-	if err != nil {
-		return nil, fmt.Errorf("invalid name: %w", err)
-	}
-	calldata = append(calldata, nameFelts...)
-
-	// Convert symbol to ByteArray (Cairo ByteArray structure)
-	symbolFelts, err := stringToByteArray(e.config.Symbol)
-	if err != nil {
-		return nil, fmt.Errorf("invalid symbol: %w", err)
-	}
-	calldata = append(calldata, symbolFelts...)
-
-	// Convert base URI to ByteArray (Cairo ByteArray structure)
-	baseURIFelts, err := stringToByteArray(e.config.BaseURI)
+	calldata, err := encodeEthrxConstructor(e.config)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URI: %w", err)
+		return nil, err
 	}
-	calldata = append(calldata, baseURIFelts...)
-
-	// Convert contract URI to ByteArray (Cairo ByteArray structure)
-	contractURIFelts, err := stringToByteArray(e.config.ContractURI)
-	if err != nil {
-		return nil, fmt.Errorf("invalid contract URI: %w", err)
-	}
-	calldata = append(calldata, contractURIFelts...)
-
-	// Convert mint token address
-	mintToken, err := utils.HexToFelt(e.config.MintToken)
-	if err != nil {
-		return nil, fmt.Errorf("invalid mint token address: %w", err)
-	}
-	calldata = append(calldata, mintToken)
-
-	// Convert mint price (u256) - split into low and high 128 bits
-	mintPriceBig, ok := new(big.Int).SetString(e.config.MintPrice, 10)
-	if !ok {
-		return nil, fmt.Errorf("invalid mint price: %s", e.config.MintPrice)
-	}
-	mintPriceLow, mintPriceHigh := splitU256(mintPriceBig)
-	calldata = append(calldata, mintPriceLow, mintPriceHigh)
-
-	// Convert max supply (u256) - split into low and high 128 bits
-	maxSupplyBig, ok := new(big.Int).SetString(e.config.MaxSupply, 10)
-	if !ok {
-		return nil, fmt.Errorf("invalid max supply: %s", e.config.MaxSupply)
-	}
-	maxSupplyLow, maxSupplyHigh := splitU256(maxSupplyBig)
-	calldata = append(calldata, maxSupplyLow, maxSupplyHigh)
 
 	e.logger.Debugf("✅ Constructor arguments built: %d arguments", len(calldata))
 	e.logger.Debugf("   Owner: %s", e.config.Owner)
@@ -173,83 +168,19 @@ func (e *EthrxDeployer) buildConstructorArgs() ([]*felt.Felt, error) {
 	return calldata, nil
 }
 
-// splitU256 splits a u256 into low and high 128-bit parts
-func splitU256(value *big.Int) (*felt.Felt, *felt.Felt) {
-	// Create mask for low 128 bits (2^128 - 1)
-	lowMask := new(big.Int)
-	lowMask.Exp(big.NewInt(2), big.NewInt(128), nil)
-	lowMask.Sub(lowMask, big.NewInt(1))
-
-	// Extract low 128 bits
-	low := new(big.Int).And(value, lowMask)
-
-	// Extract high 128 bits by right-shifting by 128
-	high := new(big.Int).Rsh(value, 128)
-
-	return new(felt.Felt).SetBigInt(low), new(felt.Felt).SetBigInt(high)
-}
+// encodeEthrxConstructor serializes the Ethrx ConstructorArgs struct from the configuration,
+// using the constructor ABI in the sierra file so the member order always matches the contract
+func encodeEthrxConstructor(cfg *config.EthrxConfig) ([]*felt.Felt, error) {
+	contractABI, err := abi.Load(cfg.SierraPath)
+	if err != nil {
+		return nil, err
+	}
 
-// stringToByteArray converts a string to Cairo ByteArray structure
-// Returns: [data_len, data_words..., pending_word, pending_word_len]
-// Cairo ByteArray structure:
-//   - data: Array<bytes31> (each bytes31 is 31 bytes, stored as felt252)
-//   - pending_word: felt252 (last incomplete word, 0-31 bytes)
-//   - pending_word_len: u32 (number of bytes in pending_word)
-// When serialized for calldata, Array is: [length, element0, element1, ...]
-func stringToByteArray(s string) ([]*felt.Felt, error) {
-	if s == "" {
-		// Empty ByteArray: data_len=0, pending_word=0, pending_word_len=0
-		return []*felt.Felt{
-			new(felt.Felt).SetUint64(0), // data_len
-			new(felt.Felt).SetUint64(0), // pending_word
-			new(felt.Felt).SetUint64(0), // pending_word_len
-		}, nil
-	}
-
-	bytes := []byte(s)
-	length := len(bytes)
-	const wordSize = 31 // bytes31 is 31 bytes
-
-	// Calculate number of full words and remaining bytes
-	fullWords := length / wordSize
-	remainingBytes := length % wordSize
-
-	var result []*felt.Felt
-
-	// Add data_len (number of full 31-byte words)
-	result = append(result, new(felt.Felt).SetUint64(uint64(fullWords)))
-
-	// Add full words (31 bytes each)
-	for i := 0; i < fullWords; i++ {
-		start := i * wordSize
-		end := start + wordSize
-		wordBytes := bytes[start:end]
-		hexStr := fmt.Sprintf("0x%x", wordBytes)
-		word, err := utils.HexToFelt(hexStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert word to felt: %w", err)
-		}
-		result = append(result, word)
-	}
-
-	// Add pending_word (last incomplete word, if any)
-	var pendingWord *felt.Felt
-	if remainingBytes > 0 {
-		start := fullWords * wordSize
-		pendingBytes := bytes[start:]
-		hexStr := fmt.Sprintf("0x%x", pendingBytes)
-		var err error
-		pendingWord, err = utils.HexToFelt(hexStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert pending word to felt: %w", err)
+	args := make(map[string]any)
+	for key, value := range configValues(cfg) {
+		if key != KeySierraPath && key != KeyCasmPath {
+			args[key] = value
 		}
-	} else {
-		pendingWord = new(felt.Felt).SetUint64(0)
 	}
-	result = append(result, pendingWord)
-
-	// Add pending_word_len
-	result = append(result, new(felt.Felt).SetUint64(uint64(remainingBytes)))
-
-	return result, nil
+	return contractABI.EncodeConstructor(map[string]any{"args": args})
 }
@@ -0,0 +1,138 @@
+package contracts
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/abi"
+)
+
+// ethrxAdminFunctions maps the CLI arguments of each owner-only Ethrx entrypoint to its ABI inputs
+var ethrxAdminFunctions = map[string]struct {
+	usage string
+	args  func(fn *abi.Function, args []string) (map[string]any, error)
+}{
+	"set_base_uri":       {usage: "<uri>", args: singleArg},
+	"set_contract_uri":   {usage: "<uri>", args: singleArg},
+	"set_mint_price":     {usage: "<amount>", args: singleArg},
+	"set_mint_token":     {usage: "<address>", args: singleArg},
+	"set_is_minting":     {usage: "<true|false>", args: singleArg},
+	"set_tags":           {usage: "[<index>=<TAG>...] [<NEW_TAG>...]", args: setTagsArgs},
+	"upgrade_contract":   {usage: "<class_hash>", args: singleArg},
+	"transfer_ownership": {usage: "<new_owner>", args: singleArg},
+}
+
+// BuildEthrxAdminCall builds an owner-only Ethrx call from its entrypoint name and CLI arguments,
+// encoding the calldata with the contract ABI
+func BuildEthrxAdminCall(contractABI *abi.ABI, contractAddress, function string, args []string) (rpc.InvokeFunctionCall, error) {
+	admin, ok := ethrxAdminFunctions[function]
+	if !ok {
+		return rpc.InvokeFunctionCall{}, fmt.Errorf("unknown Ethrx admin function %s (supported: %s)", function, strings.Join(EthrxAdminFunctions(), ", "))
+	}
+	fn, ok := contractABI.Functions[function]
+	if !ok {
+		return rpc.InvokeFunctionCall{}, fmt.Errorf("function %s not found in the Ethrx ABI", function)
+	}
+
+	address, err := utils.HexToFelt(contractAddress)
+	if err != nil {
+		return rpc.InvokeFunctionCall{}, fmt.Errorf("invalid contract address: %w", err)
+	}
+
+	values, err := admin.args(fn, args)
+	if err != nil {
+		return rpc.InvokeFunctionCall{}, fmt.Errorf("%s %s: %w", function, admin.usage, err)
+	}
+	calldata, err := contractABI.EncodeFunction(function, values)
+	if err != nil {
+		return rpc.InvokeFunctionCall{}, fmt.Errorf("%s %s: %w", function, admin.usage, err)
+	}
+
+	return rpc.InvokeFunctionCall{
+		ContractAddress: address,
+		FunctionName:    function,
+		CallData:        calldata,
+	}, nil
+}
+
+// EthrxAdminFunctions returns the supported admin entrypoints with their argument usage
+func EthrxAdminFunctions() []string {
+	functions := make([]string, 0, len(ethrxAdminFunctions))
+	for name, admin := range ethrxAdminFunctions {
+		functions = append(functions, name+" "+admin.usage)
+	}
+	sort.Strings(functions)
+	return functions
+}
+
+// singleArg passes the only CLI argument to the only input of the function
+func singleArg(fn *abi.Function, args []string) (map[string]any, error) {
+	if len(fn.Inputs) != 1 {
+		return nil, fmt.Errorf("expected the ABI to declare 1 input, got %d", len(fn.Inputs))
+	}
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+	}
+	return map[string]any{fn.Inputs[0].Name: args[0]}, nil
+}
+
+// setTagsArgs maps set_tags(modify_tags: Option<Array<(usize, felt252)>>, new_tags: Option<Array<felt252>>).
+// Arguments of the form <index>=<TAG> re-register an existing tag, anything else registers a new tag;
+// an empty list is passed as None.
+func setTagsArgs(fn *abi.Function, args []string) (map[string]any, error) {
+	if len(fn.Inputs) != 2 {
+		return nil, fmt.Errorf("expected the ABI to declare 2 inputs, got %d", len(fn.Inputs))
+	}
+
+	var modified, added []any
+	for _, arg := range args {
+		if index, tag, ok := strings.Cut(arg, "="); ok {
+			i, err := strconv.ParseUint(index, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tag index %s: %w", index, err)
+			}
+			tagFelt, err := encodeShortString(tag)
+			if err != nil {
+				return nil, err
+			}
+			modified = append(modified, []any{i, tagFelt})
+			continue
+		}
+
+		tagFelt, err := encodeShortString(arg)
+		if err != nil {
+			return nil, err
+		}
+		added = append(added, tagFelt)
+	}
+	if len(modified) == 0 && len(added) == 0 {
+		return nil, fmt.Errorf("expected at least one tag")
+	}
+
+	return map[string]any{
+		fn.Inputs[0].Name: optionalList(modified),
+		fn.Inputs[1].Name: optionalList(added),
+	}, nil
+}
+
+// optionalList returns nil (None) for an empty list
+func optionalList(items []any) any {
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
+
+// encodeShortString encodes a Cairo short string (up to 31 ASCII characters) as a felt252
+func encodeShortString(value string) (*felt.Felt, error) {
+	if value == "" || len(value) > 31 {
+		return nil, fmt.Errorf("invalid short string %q: must be 1-31 characters", value)
+	}
+	return utils.HexToFelt(utils.StrToHex(value))
+}
@@ -0,0 +1,39 @@
+package contracts
+
+import (
+	"fmt"
+
+	"github.com/NethermindEth/juno/core/felt"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/abi"
+)
+
+func init() {
+	Register(Registration{
+		Name:        "generic",
+		Description: "any contract; constructor arguments are keyed by their ABI names",
+		Encode:      encodeFromABI,
+	})
+}
+
+// encodeFromABI serializes constructor arguments using the ABI in the sierra file, taking
+// every configuration key except the artifact keys as a constructor argument. Composite
+// arguments (arrays, structs, enums, options) are given as JSON strings.
+func encodeFromABI(values map[string]string) ([]*felt.Felt, error) {
+	contractABI, err := abi.Load(values[KeySierraPath])
+	if err != nil {
+		return nil, err
+	}
+
+	args := make(map[string]any, len(values))
+	for key, value := range values {
+		if key != KeySierraPath && key != KeyCasmPath && key != KeyContractName {
+			args[key] = value
+		}
+	}
+	calldata, err := contractABI.EncodeConstructor(args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid constructor arguments: %w", err)
+	}
+	return calldata, nil
+}
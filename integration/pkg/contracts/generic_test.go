@@ -0,0 +1,101 @@
+package contracts
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/NethermindEth/starknet.go/utils"
+)
+
+// splitterABI has a constructor with a felt, a u256 and an array argument
+const splitterABI = `[
+	{"type": "constructor", "name": "constructor", "inputs": [
+		{"name": "owner", "type": "core::starknet::contract_address::ContractAddress"},
+		{"name": "cap", "type": "core::integer::u256"},
+		{"name": "recipients", "type": "core::array::Array::<core::starknet::contract_address::ContractAddress>"}
+	]}
+]`
+
+func TestGenericDeployerCalldata(t *testing.T) {
+	sierraPath := writeSierra(t, "etheracts_Splitter.contract_class.json", splitterABI)
+	base := map[string]string{KeySierraPath: sierraPath, KeyCasmPath: "Splitter.casm.json", "owner": "0x0a"}
+
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "all arguments",
+			values: map[string]string{"cap": "340282366920938463463374607431768211457", "recipients": `["0x0b", "0x0c"]`},
+			// cap is 2^128 + 1: low and high are both 1
+			want: []string{"0xa", "0x1", "0x1", "0x2", "0xb", "0xc"},
+		},
+		{
+			name:   "contract name is not an argument",
+			values: map[string]string{"cap": "7", "recipients": "[]", KeyContractName: "Splitter"},
+			want:   []string{"0xa", "0x7", "0x0", "0x0"},
+		},
+		{
+			name:    "missing argument",
+			values:  map[string]string{"recipients": "[]"},
+			wantErr: "invalid constructor arguments",
+		},
+		{
+			name:    "unknown argument",
+			values:  map[string]string{"cap": "7", "recipients": "[]", "fee": "1"},
+			wantErr: "fee",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overrides := map[string]string{}
+			for key, value := range base {
+				overrides[key] = value
+			}
+			for key, value := range tt.values {
+				overrides[key] = value
+			}
+			d, err := NewRegisteredDeployer(nil, nil, "generic", overrides, quietLogger())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			calldata, err := d.ConstructorCalldata()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := utils.FeltArrToStringArr(calldata); !slices.Equal(got, tt.want) {
+				t.Errorf("calldata = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenericContractName(t *testing.T) {
+	for _, tt := range []struct {
+		values map[string]string
+		want   string
+	}{
+		{map[string]string{KeySierraPath: "target/dev/etheracts_Splitter.contract_class.json"}, "Splitter"},
+		{map[string]string{KeySierraPath: "Splitter.contract_class.json"}, "Splitter"},
+		{map[string]string{KeySierraPath: "etheracts_Splitter.contract_class.json", KeyContractName: "Payouts"}, "Payouts"},
+	} {
+		d, err := NewRegisteredDeployer(nil, nil, "generic", tt.values, quietLogger())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.GetContractName(); got != tt.want {
+			t.Errorf("GetContractName(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package contracts
+
+import (
+	"github.com/NovemberFork/etheracts/integration/pkg/config"
+)
+
+func init() {
+	Register(Registration{
+		Name:         "mock_erc20",
+		ContractName: "MockERC20",
+		Description:  "mintable test token from src/mocks/erc20.cairo",
+		Schema: []ConfigField{
+			{Key: "name", Description: "token name", Required: true},
+			{Key: "symbol", Description: "token symbol", Required: true},
+		},
+		Defaults: func(*config.Config) map[string]string {
+			return map[string]string{
+				"name":        "Mock USDC",
+				"symbol":      "mUSDC",
+				KeySierraPath: "../target/dev/etheracts_MockERC20.contract_class.json",
+				KeyCasmPath:   "../target/dev/etheracts_MockERC20.compiled_contract_class.json",
+			}
+		},
+		Encode: encodeFromABI,
+	})
+}
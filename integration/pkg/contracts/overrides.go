@@ -0,0 +1,27 @@
+package contracts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ApplyOverrides sets fields of a contract configuration from key/value pairs keyed by the
+// fields' JSON names, as used in deployment plans. Unknown keys are rejected.
+func ApplyOverrides(cfg any, values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to encode overrides: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("invalid configuration override: %w", err)
+	}
+	return nil
+}
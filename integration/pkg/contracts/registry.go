@@ -0,0 +1,243 @@
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/config"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+)
+
+// Artifact configuration keys accepted by every registered contract
+const (
+	KeySierraPath   = "sierra_path"
+	KeyCasmPath     = "casm_path"
+	KeyContractName = "contract_name"
+)
+
+// ConfigField describes one configuration key of a registered contract
+type ConfigField struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+// ConstructorEncoder serializes constructor calldata from a contract's configuration values
+type ConstructorEncoder func(values map[string]string) ([]*felt.Felt, error)
+
+// Registration describes a contract type that deployment commands and plans can deploy
+type Registration struct {
+	// Name is the command and plan contract type, such as "ethrx"
+	Name string
+	// ContractName is recorded in deployment results; empty derives it from the sierra file
+	ContractName string
+	Description  string
+	// Schema lists the accepted configuration keys besides the artifact keys.
+	// A nil schema accepts any key and leaves validation to the encoder.
+	Schema []ConfigField
+	// Defaults returns default values, for example from the environment configuration
+	Defaults func(cfg *config.Config) map[string]string
+	Encode   ConstructorEncoder
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Registration)
+)
+
+// Register makes a contract type available by name. It panics on duplicate names,
+// so it is meant to be called from init functions.
+func Register(r Registration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if r.Name == "" || r.Encode == nil {
+		panic("contracts: registration needs a name and an encoder")
+	}
+	if _, ok := registry[r.Name]; ok {
+		panic(fmt.Sprintf("contracts: %s registered twice", r.Name))
+	}
+	registry[r.Name] = r
+}
+
+// Lookup returns the registration for a contract type
+func Lookup(name string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	r, ok := registry[name]
+	return r, ok
+}
+
+// Registered returns all registrations sorted by name
+func Registered() []Registration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	all := make([]Registration, 0, len(registry))
+	for _, r := range registry {
+		all = append(all, r)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// RegisteredDeployer deploys a registered contract type from key/value configuration
+type RegisteredDeployer struct {
+	registration Registration
+	deployer     *deploy.Deployer
+	values       map[string]string
+	logger       *logrus.Logger
+}
+
+// NewRegisteredDeployer creates a deployer for the named contract type. Values are the
+// registration defaults overridden by overrides; unknown keys are rejected.
+// A nil logger uses the deployer's logger.
+func NewRegisteredDeployer(deployer *deploy.Deployer, cfg *config.Config, name string, overrides map[string]string, logger *logrus.Logger) (*RegisteredDeployer, error) {
+	r, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown contract type: %s", name)
+	}
+	if logger == nil {
+		logger = deployer.Logger()
+	}
+
+	values := make(map[string]string)
+	if r.Defaults != nil {
+		for key, value := range r.Defaults(cfg) {
+			values[key] = value
+		}
+	}
+	for key, value := range overrides {
+		if !r.accepts(key) {
+			return nil, fmt.Errorf("%s: unknown configuration key %s", name, key)
+		}
+		values[key] = value
+	}
+
+	return &RegisteredDeployer{
+		registration: r,
+		deployer:     deployer,
+		values:       values,
+		logger:       logger,
+	}, nil
+}
+
+// accepts reports whether key is part of the registration's configuration
+func (r Registration) accepts(key string) bool {
+	if r.Schema == nil || key == KeySierraPath || key == KeyCasmPath || key == KeyContractName {
+		return true
+	}
+	for _, field := range r.Schema {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Deploy declares and deploys the contract
+func (d *RegisteredDeployer) Deploy(ctx context.Context) (*deploy.DeploymentResult, error) {
+	name := d.GetContractName()
+	d.logger.Infof("🚀 Deploying %s Contract", name)
+
+	calldata, err := d.ConstructorCalldata()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build constructor arguments: %w", err)
+	}
+
+	result, err := d.deployer.DeployContract(ctx, deploy.ContractInfo{
+		Name:        name,
+		SierraPath:  d.values[KeySierraPath],
+		CasmPath:    d.values[KeyCasmPath],
+		Constructor: deploy.ConstructorArgs{Args: calldata},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("deployment failed: %w", err)
+	}
+
+	d.logger.Infof("🎉 %s deployment completed successfully!", name)
+	d.logger.Info("📋 Summary:")
+	d.logger.Infof("   Class Hash: %s", result.ClassHash)
+	d.logger.Infof("   Deployed Address: %s", result.DeployedAddress)
+	d.logger.Infof("   Transaction Hash: %s", result.TransactionHash)
+	return result, nil
+}
+
+// GetContractName returns the configured contract name, the registration's name, or the
+// name taken from the sierra file (etheracts_Foo.contract_class.json → Foo)
+func (d *RegisteredDeployer) GetContractName() string {
+	if name := d.values[KeyContractName]; name != "" {
+		return name
+	}
+	if d.registration.ContractName != "" {
+		return d.registration.ContractName
+	}
+	base := strings.TrimSuffix(filepath.Base(d.values[KeySierraPath]), ".contract_class.json")
+	if _, name, ok := strings.Cut(base, "_"); ok {
+		return name
+	}
+	return base
+}
+
+// ValidateConfig checks that the artifacts and all required keys are configured
+func (d *RegisteredDeployer) ValidateConfig() error {
+	if d.values[KeySierraPath] == "" {
+		return fmt.Errorf("sierra path is required")
+	}
+	if d.values[KeyCasmPath] == "" {
+		return fmt.Errorf("casm path is required")
+	}
+	for _, field := range d.registration.Schema {
+		if field.Required && d.values[field.Key] == "" {
+			return fmt.Errorf("%s is required (%s)", field.Key, field.Description)
+		}
+	}
+	return nil
+}
+
+// ConstructorCalldata returns the serialized constructor arguments from the configuration
+func (d *RegisteredDeployer) ConstructorCalldata() ([]*felt.Felt, error) {
+	calldata, err := d.registration.Encode(d.values)
+	if err != nil {
+		return nil, err
+	}
+
+	d.logger.Debugf("🔍 Constructor calldata (felt array):")
+	for i, f := range calldata {
+		d.logger.Debugf("   [%d]: %s", i, f.String())
+	}
+	return calldata, nil
+}
+
+// configValues converts a configuration struct to key/value pairs using its JSON names
+func configValues(cfg any) map[string]string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil
+	}
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+// withoutContractName returns values without the contract name key
+func withoutContractName(values map[string]string) map[string]string {
+	rest := make(map[string]string, len(values))
+	for key, value := range values {
+		if key != KeyContractName {
+			rest[key] = value
+		}
+	}
+	return rest
+}
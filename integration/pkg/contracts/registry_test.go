@@ -0,0 +1,204 @@
+package contracts
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/config"
+)
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// testConfig holds the Ethrx settings as loaded from <NETWORK>_ETHRX_* and ETHRX_*
+func testConfig() *config.Config {
+	return &config.Config{Contracts: config.ContractsConfig{Ethrx: config.EthrxConfig{
+		Owner:      "0x0a",
+		Name:       "Etheracts",
+		Symbol:     "Ethrx",
+		MintToken:  "0x70c3",
+		MintPrice:  "1000",
+		MaxSupply:  "1111",
+		SierraPath: "../target/dev/etheracts_Ethrx.contract_class.json",
+		CasmPath:   "../target/dev/etheracts_Ethrx.compiled_contract_class.json",
+	}}}
+}
+
+// writeSierra writes a sierra file holding only an ABI and returns its path
+func writeSierra(t *testing.T, name, contractABI string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(`{"abi": `+contractABI+`}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRegister(t *testing.T) {
+	if _, ok := Lookup("ethrx"); !ok {
+		t.Error("ethrx is not registered")
+	}
+	if _, ok := Lookup("erc1155"); ok {
+		t.Error("unregistered contract type found")
+	}
+
+	Register(Registration{Name: "test_registered", Encode: encodeFromABI})
+	if r, ok := Lookup("test_registered"); !ok || r.Name != "test_registered" {
+		t.Errorf("Lookup = %+v, %t", r, ok)
+	}
+
+	for _, tt := range []struct {
+		name         string
+		registration Registration
+	}{
+		{"duplicate name", Registration{Name: "ethrx", Encode: encodeFromABI}},
+		{"no encoder", Registration{Name: "test_no_encoder"}},
+		{"no name", Registration{Encode: encodeFromABI}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Register did not panic")
+				}
+			}()
+			Register(tt.registration)
+		})
+	}
+}
+
+func TestNewRegisteredDeployer(t *testing.T) {
+	tests := []struct {
+		name      string
+		contract  string
+		overrides map[string]string
+		want      map[string]string
+		wantErr   string
+	}{
+		{
+			name:     "defaults",
+			contract: "ethrx",
+			want:     map[string]string{"owner": "0x0a", "mint_price": "1000", "max_supply": "1111"},
+		},
+		{
+			name:      "overrides take precedence over the defaults",
+			contract:  "ethrx",
+			overrides: map[string]string{"mint_price": "5", "owner": "0x0b", KeySierraPath: "Ethrx.json"},
+			want:      map[string]string{"owner": "0x0b", "mint_price": "5", "max_supply": "1111", KeySierraPath: "Ethrx.json"},
+		},
+		{
+			name:      "unknown key",
+			contract:  "ethrx",
+			overrides: map[string]string{"mint_prise": "5"},
+			wantErr:   "ethrx: unknown configuration key mint_prise",
+		},
+		{
+			name:      "any key without a schema",
+			contract:  "generic",
+			overrides: map[string]string{"initial_supply": "5"},
+			want:      map[string]string{"initial_supply": "5"},
+		},
+		{
+			name:     "unknown contract type",
+			contract: "erc1155",
+			wantErr:  "unknown contract type: erc1155",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewRegisteredDeployer(nil, testConfig(), tt.contract, tt.overrides, quietLogger())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range tt.want {
+				if got := d.values[key]; got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(cfg *config.Config)
+		overrides map[string]string
+		wantErr   string
+	}{
+		{
+			name: "complete",
+		},
+		{
+			name:      "required field missing",
+			configure: func(cfg *config.Config) { cfg.Contracts.Ethrx.Owner = "" },
+			wantErr:   "owner is required (contract owner address)",
+		},
+		{
+			name:      "required field set by an override",
+			configure: func(cfg *config.Config) { cfg.Contracts.Ethrx.MintToken = "" },
+			overrides: map[string]string{"mint_token": "0x70c3"},
+		},
+		{
+			name:      "optional field missing",
+			configure: func(cfg *config.Config) { cfg.Contracts.Ethrx.BaseURI = "" },
+		},
+		{
+			name:      "casm path missing",
+			configure: func(cfg *config.Config) { cfg.Contracts.Ethrx.CasmPath = "" },
+			wantErr:   "casm path is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			if tt.configure != nil {
+				tt.configure(cfg)
+			}
+			d, err := NewRegisteredDeployer(nil, cfg, "ethrx", tt.overrides, quietLogger())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = d.ValidateConfig()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	cfg := testConfig().Contracts.Ethrx
+	if err := ApplyOverrides(&cfg, map[string]string{"mint_price": "5", "base_uri": "ipfs://meta/"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MintPrice != "5" || cfg.BaseURI != "ipfs://meta/" || cfg.Owner != "0x0a" {
+		t.Errorf("config = %+v, want the overrides on top of the defaults", cfg)
+	}
+
+	if err := ApplyOverrides(&cfg, map[string]string{"mint_prise": "5"}); err == nil || !strings.Contains(err.Error(), "mint_prise") {
+		t.Errorf("err = %v, want the unknown key rejected", err)
+	}
+}
@@ -0,0 +1,65 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+)
+
+// BatchResult is the outcome of one transaction of a batch
+type BatchResult struct {
+	TransactionHash *felt.Felt
+	Receipt         *rpc.TransactionReceiptWithBlockInfo
+	Err             error
+}
+
+// InvokeBatch submits one transaction per entry of batches and waits for all of them.
+// With nonce tracking enabled the transactions are pipelined (up to the in-flight limit);
+// otherwise each one is confirmed before the next is sent. Submission stops at the first
+// error, and results are returned in order for every transaction that was attempted.
+func (d *Deployer) InvokeBatch(ctx context.Context, batches [][]rpc.InvokeFunctionCall) ([]BatchResult, error) {
+	// Allocated up front: waiting goroutines write into their own slot
+	results := make([]BatchResult, len(batches))
+	attempted := 0
+	var wg sync.WaitGroup
+	var submitErr error
+
+	for i, calls := range batches {
+		attempted++
+		txHash, err := d.sendInvoke(ctx, calls)
+		if err != nil {
+			results[i].Err = err
+			submitErr = fmt.Errorf("transaction %d of %d: %w", i+1, len(batches), err)
+			break
+		}
+		d.logger.WithField("tx_hash", txHash.String()).Debugf("📤 Batch transaction %d/%d submitted: %s", i+1, len(batches), txHash.String())
+
+		results[i].TransactionHash = txHash
+		if d.nonces == nil {
+			results[i].Receipt, results[i].Err = d.WaitForReceipt(ctx, txHash)
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, txHash *felt.Felt) {
+			defer wg.Done()
+			receipt, err := d.WaitForReceipt(ctx, txHash)
+			results[i].Receipt, results[i].Err = receipt, err
+		}(i, txHash)
+	}
+	wg.Wait()
+	results = results[:attempted]
+
+	if submitErr != nil {
+		return results, submitErr
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			return results, fmt.Errorf("transaction %d of %d: %w", i+1, len(batches), result.Err)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,384 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/account"
+	"github.com/NethermindEth/starknet.go/contracts"
+	"github.com/NethermindEth/starknet.go/hash"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/signer"
+)
+
+// BundleVersion is the version of the transaction bundle file format
+const BundleVersion = 1
+
+// TransactionBundle is a transaction prepared on an online machine, signed on an
+// offline one and broadcast afterwards. Nonce, resource bounds and chain ID are pinned
+// so the signer never needs network access.
+type TransactionBundle struct {
+	Version     int           `json:"version"`
+	Network     string        `json:"network"`
+	ChainID     string        `json:"chain_id"`
+	Account     string        `json:"account"`
+	Type        signer.TxType `json:"type"`
+	Description string        `json:"description"`
+
+	// Human readable view of what is being signed
+	Calls     []signer.Call `json:"calls,omitempty"`
+	ClassHash string        `json:"class_hash,omitempty"`
+
+	// Set for UDC deployments so the broadcast step can report the new contract
+	ContractName    string `json:"contract_name,omitempty"`
+	ExpectedAddress string `json:"expected_address,omitempty"`
+
+	TransactionHash string                     `json:"transaction_hash"`
+	Invoke          *rpc.BroadcastInvokeTxnV3  `json:"invoke,omitempty"`
+	Declare         *rpc.BroadcastDeclareTxnV3 `json:"declare,omitempty"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	SignedAt  *time.Time `json:"signed_at,omitempty"`
+}
+
+// BuildInvokeBundle prepares an unsigned bundle for the given calls
+func (d *Deployer) BuildInvokeBundle(ctx context.Context, description string, calls []rpc.InvokeFunctionCall) (*TransactionBundle, error) {
+	return d.buildInvokeBundle(ctx, d.address, description, calls)
+}
+
+func (d *Deployer) buildInvokeBundle(ctx context.Context, sender *felt.Felt, description string, calls []rpc.InvokeFunctionCall) (*TransactionBundle, error) {
+	invokeTxn, err := d.buildInvoke(ctx, sender, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash, err := hash.TransactionHashInvokeV3(invokeTxn, d.chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute transaction hash: %w", err)
+	}
+
+	return &TransactionBundle{
+		Version:         BundleVersion,
+		Network:         d.network,
+		ChainID:         d.chainID.String(),
+		Account:         sender.String(),
+		Type:            signer.TxTypeInvoke,
+		Description:     description,
+		Calls:           signer.DecodeCalls(calls),
+		TransactionHash: txHash.String(),
+		Invoke:          invokeTxn,
+		CreatedAt:       time.Now(),
+	}, nil
+}
+
+// BuildDeployBundle prepares an unsigned UDC deployment of an already declared class
+func (d *Deployer) BuildDeployBundle(ctx context.Context, contractName string, classHash *felt.Felt, constructorArgs []*felt.Felt) (*TransactionBundle, error) {
+	udcCall, salt, err := utils.BuildUDCCalldata(classHash, constructorArgs, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build UDC calldata: %w", err)
+	}
+
+	bundle, err := d.BuildInvokeBundle(ctx, fmt.Sprintf("Deploy %s (class %s)", contractName, classHash.String()), []rpc.InvokeFunctionCall{udcCall})
+	if err != nil {
+		return nil, err
+	}
+
+	expected := utils.PrecomputeAddressForUDC(classHash, salt, constructorArgs, utils.UDCCairoV0, d.address)
+	bundle.ContractName = contractName
+	bundle.ClassHash = classHash.String()
+	bundle.ExpectedAddress = expected.String()
+
+	return bundle, nil
+}
+
+// BuildDeclareBundle prepares an unsigned declare transaction for the given contract files
+func (d *Deployer) BuildDeclareBundle(ctx context.Context, contractName, sierraPath, casmPath string) (*TransactionBundle, error) {
+	casmClass, err := utils.UnmarshalJSONFileToType[contracts.CasmClass](casmPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse casm contract: %w", err)
+	}
+	contractClass, err := utils.UnmarshalJSONFileToType[contracts.ContractClass](sierraPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sierra contract: %w", err)
+	}
+
+	classHash := hash.ClassHash(contractClass)
+	declared, err := d.IsDeclared(ctx, classHash)
+	if err != nil {
+		return nil, err
+	}
+	if declared {
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyDeclared, classHash.String())
+	}
+
+	declareTxn, err := d.BuildDeclare(ctx, casmClass, contractClass)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash, err := hash.TransactionHashBroadcastDeclareV3(declareTxn, d.chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute transaction hash: %w", err)
+	}
+
+	return &TransactionBundle{
+		Version:         BundleVersion,
+		Network:         d.network,
+		ChainID:         d.chainID.String(),
+		Account:         d.address.String(),
+		Type:            signer.TxTypeDeclare,
+		Description:     fmt.Sprintf("Declare %s (class %s)", contractName, classHash.String()),
+		ClassHash:       classHash.String(),
+		ContractName:    contractName,
+		TransactionHash: txHash.String(),
+		Declare:         declareTxn,
+		CreatedAt:       time.Now(),
+	}, nil
+}
+
+// Verify recomputes the transaction hash from the pinned fields and checks that the
+// human readable calls describe exactly the calldata being signed
+func (b *TransactionBundle) Verify() error {
+	if b.Version != BundleVersion {
+		return fmt.Errorf("unsupported bundle version: %d", b.Version)
+	}
+
+	txHash, err := b.computeHash()
+	if err != nil {
+		return err
+	}
+	if b.TransactionHash != txHash.String() {
+		return fmt.Errorf("transaction hash mismatch: bundle says %s, computed %s", b.TransactionHash, txHash.String())
+	}
+
+	switch b.Type {
+	case signer.TxTypeInvoke:
+		if b.Invoke.SenderAddress.String() != b.Account {
+			return fmt.Errorf("bundle account %s does not match transaction sender %s", b.Account, b.Invoke.SenderAddress.String())
+		}
+		calls := make([]rpc.FunctionCall, len(b.Calls))
+		for i, call := range b.Calls {
+			contractAddress, err := utils.HexToFelt(call.ContractAddress)
+			if err != nil {
+				return fmt.Errorf("call %d: invalid contract address: %w", i, err)
+			}
+			selector := utils.GetSelectorFromNameFelt(call.Entrypoint)
+			if selector.String() != call.Selector {
+				return fmt.Errorf("call %d: entrypoint %s does not match selector %s", i, call.Entrypoint, call.Selector)
+			}
+			calldata, err := utils.HexArrToFelt(call.Calldata)
+			if err != nil {
+				return fmt.Errorf("call %d: invalid calldata: %w", i, err)
+			}
+			calls[i] = rpc.FunctionCall{ContractAddress: contractAddress, EntryPointSelector: selector, Calldata: calldata}
+		}
+		if !slices.EqualFunc(account.FmtCallDataCairo2(calls), b.Invoke.Calldata, func(a, b *felt.Felt) bool { return a.Equal(b) }) {
+			return fmt.Errorf("decoded calls do not match the transaction calldata")
+		}
+	case signer.TxTypeDeclare:
+		if b.Declare.SenderAddress.String() != b.Account {
+			return fmt.Errorf("bundle account %s does not match transaction sender %s", b.Account, b.Declare.SenderAddress.String())
+		}
+		if classHash := hash.ClassHash(b.Declare.ContractClass); classHash.String() != b.ClassHash {
+			return fmt.Errorf("class hash mismatch: bundle says %s, computed %s", b.ClassHash, classHash.String())
+		}
+	}
+
+	return nil
+}
+
+// Sign verifies the bundle and signs it with s. It needs no network access.
+func (b *TransactionBundle) Sign(ctx context.Context, s signer.Signer) error {
+	if err := b.Verify(); err != nil {
+		return fmt.Errorf("refusing to sign bundle: %w", err)
+	}
+
+	req := &signer.SignRequest{
+		Network:         b.Network,
+		ChainID:         utils.HexToShortStr(b.ChainID),
+		Account:         b.Account,
+		Type:            b.Type,
+		TransactionHash: b.TransactionHash,
+		Calls:           b.Calls,
+		ClassHash:       b.ClassHash,
+	}
+	switch b.Type {
+	case signer.TxTypeInvoke:
+		req.Nonce = b.Invoke.Nonce.String()
+	case signer.TxTypeDeclare:
+		req.Nonce = b.Declare.Nonce.String()
+	}
+
+	signature, err := s.SignTransaction(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to sign bundle: %w", err)
+	}
+
+	switch b.Type {
+	case signer.TxTypeInvoke:
+		b.Invoke.Signature = signature
+	case signer.TxTypeDeclare:
+		b.Declare.Signature = signature
+	}
+	now := time.Now()
+	b.SignedAt = &now
+
+	return nil
+}
+
+// IsSigned returns whether the bundle carries a signature
+func (b *TransactionBundle) IsSigned() bool {
+	switch b.Type {
+	case signer.TxTypeInvoke:
+		return len(b.Invoke.Signature) > 0
+	case signer.TxTypeDeclare:
+		return len(b.Declare.Signature) > 0
+	}
+	return false
+}
+
+// Nonce returns the pinned account nonce
+func (b *TransactionBundle) Nonce() *felt.Felt {
+	if b.Type == signer.TxTypeDeclare {
+		return b.Declare.Nonce
+	}
+	return b.Invoke.Nonce
+}
+
+func (b *TransactionBundle) computeHash() (*felt.Felt, error) {
+	chainID, err := utils.HexToFelt(b.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chain ID: %w", err)
+	}
+
+	if err := b.checkTransaction(); err != nil {
+		return nil, err
+	}
+	if b.Type == signer.TxTypeDeclare {
+		return hash.TransactionHashBroadcastDeclareV3(b.Declare, chainID)
+	}
+	return hash.TransactionHashInvokeV3(b.Invoke, chainID)
+}
+
+// checkTransaction checks the bundle carries the transaction of its type, which the
+// other methods rely on
+func (b *TransactionBundle) checkTransaction() error {
+	switch b.Type {
+	case signer.TxTypeInvoke:
+		if b.Invoke == nil {
+			return fmt.Errorf("invoke bundle has no transaction")
+		}
+	case signer.TxTypeDeclare:
+		if b.Declare == nil {
+			return fmt.Errorf("declare bundle has no transaction")
+		}
+	default:
+		return fmt.Errorf("unsupported bundle type: %q", b.Type)
+	}
+	return nil
+}
+
+// BroadcastBundle submits a signed bundle and returns the transaction hash
+func (d *Deployer) BroadcastBundle(ctx context.Context, b *TransactionBundle) (*felt.Felt, error) {
+	if err := b.Verify(); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if !b.IsSigned() {
+		return nil, fmt.Errorf("bundle is not signed")
+	}
+	if b.ChainID != d.chainID.String() {
+		return nil, fmt.Errorf("bundle was built for chain %s but RPC serves %s",
+			utils.HexToShortStr(b.ChainID), d.chainName())
+	}
+	if b.Account != d.address.String() {
+		return nil, fmt.Errorf("bundle was built for account %s but deployer account is %s", b.Account, d.address.String())
+	}
+
+	return d.submitBundle(ctx, b)
+}
+
+// submitBundle checks the pinned nonce is still usable and submits the signed transaction
+func (d *Deployer) submitBundle(ctx context.Context, b *TransactionBundle) (*felt.Felt, error) {
+	sender, err := utils.HexToFelt(b.Account)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle account: %w", err)
+	}
+
+	// A pinned nonce that was already used means the bundle can never be included
+	nonce, err := d.nonceOf(ctx, sender)
+	if err != nil {
+		return nil, err
+	}
+	if nonce.Cmp(b.Nonce()) > 0 {
+		return nil, fmt.Errorf("bundle nonce %s is stale (account nonce is %s), rebuild it", b.Nonce().String(), nonce.String())
+	}
+
+	txHash, err := utils.HexToFelt(b.TransactionHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction hash: %w", err)
+	}
+
+	switch b.Type {
+	case signer.TxTypeInvoke:
+		_, err = d.client.AddInvokeTransaction(ctx, b.Invoke)
+	case signer.TxTypeDeclare:
+		_, err = d.client.AddDeclareTransaction(ctx, b.Declare)
+	default:
+		return nil, fmt.Errorf("unsupported bundle type: %s", b.Type)
+	}
+	if err != nil {
+		if err := d.confirmSubmission(ctx, txHash, err); err != nil {
+			return nil, err
+		}
+	}
+	d.metrics.TransactionSubmitted(string(b.Type))
+	return txHash, nil
+}
+
+// ClassHashFromFile computes the class hash of a compiled Sierra contract class
+func ClassHashFromFile(sierraPath string) (*felt.Felt, error) {
+	contractClass, err := utils.UnmarshalJSONFileToType[contracts.ContractClass](sierraPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sierra contract: %w", err)
+	}
+	return hash.ClassHash(contractClass), nil
+}
+
+// LoadBundle reads a transaction bundle from a JSON file
+func LoadBundle(path string) (*TransactionBundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", path, err)
+	}
+
+	var bundle TransactionBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle %s: %w", path, err)
+	}
+	if err := bundle.checkTransaction(); err != nil {
+		return nil, fmt.Errorf("invalid bundle %s: %w", path, err)
+	}
+
+	return &bundle, nil
+}
+
+// SaveBundle writes a transaction bundle to a JSON file
+func SaveBundle(path string, bundle *TransactionBundle) error {
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write bundle %s: %w", path, err)
+	}
+
+	return nil
+}
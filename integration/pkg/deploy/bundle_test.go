@@ -0,0 +1,54 @@
+package deploy_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy/deploytest"
+)
+
+func TestLoadBundle(t *testing.T) {
+	d := newDeployer(t, deploytest.NewProvider())
+	bundle, err := d.BuildInvokeBundle(t.Context(), "Ping", ping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := deploy.SaveBundle(path, bundle); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := deploy.LoadBundle(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Verify(); err != nil {
+		t.Errorf("saved bundle does not verify: %v", err)
+	}
+}
+
+func TestLoadBundleRejectsMissingTransaction(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"invoke without transaction", `{"version": 1, "type": "INVOKE", "declare": {}}`, "invoke bundle has no transaction"},
+		{"declare without transaction", `{"version": 1, "type": "DECLARE", "invoke": {}}`, "declare bundle has no transaction"},
+		{"unknown type", `{"version": 1, "type": "DEPLOY_ACCOUNT", "invoke": {}}`, `unsupported bundle type: "DEPLOY_ACCOUNT"`},
+		{"no type", `{"version": 1}`, `unsupported bundle type: ""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bundle.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := deploy.LoadBundle(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
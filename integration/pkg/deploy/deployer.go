@@ -2,44 +2,145 @@ package deploy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"net/http"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/NethermindEth/juno/core/felt"
-	"github.com/NethermindEth/starknet.go/account"
+	"github.com/NethermindEth/starknet.go/client"
 	"github.com/NethermindEth/starknet.go/contracts"
 	"github.com/NethermindEth/starknet.go/hash"
 	"github.com/NethermindEth/starknet.go/rpc"
 	"github.com/NethermindEth/starknet.go/utils"
 	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/metrics"
+	"github.com/NovemberFork/etheracts/integration/pkg/signer"
 )
 
 // Deployer handles contract deployment operations
 type Deployer struct {
-	account *account.Account
-	client  *rpc.Provider
+	address *felt.Felt
+	chainID *felt.Felt
+	client  Provider
+	signer  signer.Signer
 	network string
 	logger  *logrus.Logger
+
+	wait        WaitOptions
+	deployDelay time.Duration
+	httpClient  *http.Client
+	nonces      *NonceManager
+
+	nonceStatePath string
+	maxInFlight    int
+	trackNonces    bool
+
+	progress ProgressFunc
+	steps    stepTimes
+	metrics  *metrics.Metrics
+}
+
+// Option configures a Deployer
+type Option func(*Deployer)
+
+// WithSigner signs all transactions through s. Without a signer the deployer can only
+// build unsigned transactions or broadcast pre-signed ones.
+func WithSigner(s signer.Signer) Option {
+	return func(d *Deployer) { d.signer = s }
+}
+
+// WithLogger sets the logger; by default nothing is logged
+func WithLogger(logger *logrus.Logger) Option {
+	return func(d *Deployer) { d.logger = logger }
 }
 
-// NewDeployer creates a new deployment instance
-func NewDeployer(rpcURL, network string, accountAddress, privateKey, publicKey string, logger *logrus.Logger) (*Deployer, error) {
+// WithHTTPClient sends RPC requests through c, e.g. a provider.Pool for failover
+func WithHTTPClient(c *http.Client) Option {
+	return func(d *Deployer) { d.httpClient = c }
+}
+
+// WithNonceTracking allocates deployer nonces locally so up to maxInFlight transactions can
+// be pending at once. In-flight transactions are persisted to statePath (if not empty) so a
+// restarted process continues from the right nonce.
+func WithNonceTracking(statePath string, maxInFlight int) Option {
+	return func(d *Deployer) {
+		d.trackNonces = true
+		d.nonceStatePath = statePath
+		d.maxInFlight = maxInFlight
+	}
+}
+
+// WithWaitOptions sets how transactions are awaited (deadline, backoff and target finality).
+// Zero fields keep their default.
+func WithWaitOptions(opts WaitOptions) Option {
+	return func(d *Deployer) { d.wait = opts.withDefaults() }
+}
+
+// WithMetrics records submitted and awaited transactions and the fees paid in m
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(d *Deployer) { d.metrics = m }
+}
+
+// WithDeployDelay sets the pause between declaring and deploying a contract
+func WithDeployDelay(delay time.Duration) Option {
+	return func(d *Deployer) { d.deployDelay = delay }
+}
+
+// NewDeployer connects to the RPC, verifies it serves chainID and creates a deployer for
+// the given account
+func NewDeployer(ctx context.Context, rpcURL, network, chainID, accountAddress string, opts ...Option) (*Deployer, error) {
+	// Only the HTTP client option is needed to connect; the rest are applied by NewDeployerWithProvider
+	d := &Deployer{}
+	for _, opt := range opts {
+		opt(d)
+	}
+
 	// Initialize connection to RPC provider
-	client, err := rpc.NewProvider(rpcURL)
+	var clientOpts []client.ClientOption
+	if d.httpClient != nil {
+		clientOpts = append(clientOpts, client.WithHTTPClient(d.httpClient))
+	}
+	rpcClient, err := rpc.NewProvider(rpcURL, clientOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("error connecting to RPC provider: %w", err)
 	}
 
-	// Initialize the account memkeyStore
-	ks := account.NewMemKeystore()
-	privKeyBI, ok := new(big.Int).SetString(privateKey, 0)
-	if !ok {
-		return nil, fmt.Errorf("failed to convert private key to big.Int")
+	return NewDeployerWithProvider(ctx, rpcClient, network, chainID, accountAddress, opts...)
+}
+
+// NewDeployerWithProvider creates a deployer for the given account on top of an existing
+// provider, such as the in-memory deploytest.Provider. It verifies the provider serves chainID.
+func NewDeployerWithProvider(ctx context.Context, provider Provider, network, chainID, accountAddress string, opts ...Option) (*Deployer, error) {
+	silent := logrus.New()
+	silent.SetOutput(io.Discard)
+
+	d := &Deployer{
+		client:      provider,
+		network:     network,
+		logger:      silent,
+		wait:        DefaultWaitOptions(),
+		deployDelay: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if err := d.wait.validate(); err != nil {
+		return nil, fmt.Errorf("invalid wait options: %w", err)
+	}
+
+	// Refuse to sign anything if the RPC serves a different chain than the network profile
+	if err := VerifyChainID(ctx, provider, network, chainID); err != nil {
+		return nil, err
+	}
+	chainIDInFelt, err := utils.HexToFelt(chainID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chain ID %s: %w", chainID, err)
 	}
-	ks.Put(publicKey, privKeyBI)
 
 	// Convert account address to felt
 	accountAddressInFelt, err := utils.HexToFelt(accountAddress)
@@ -47,48 +148,81 @@ func NewDeployer(rpcURL, network string, accountAddress, privateKey, publicKey s
 		return nil, fmt.Errorf("failed to transform account address: %w", err)
 	}
 
-	// Initialize the account (Cairo v2)
-	accnt, err := account.NewAccount(client, accountAddressInFelt, publicKey, ks, account.CairoV2)
+	d.address = accountAddressInFelt
+	d.chainID = chainIDInFelt
+	if d.trackNonces {
+		d.nonces = NewNonceManager(provider, accountAddressInFelt, d.nonceStatePath, d.maxInFlight, d.logger)
+	}
+	return d, nil
+}
+
+// VerifyChainID queries starknet_chainId and compares it to the chain expected by the network profile
+func VerifyChainID(ctx context.Context, client Provider, network, expectedChainID string) error {
+	expected, err := utils.HexToFelt(expectedChainID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize account: %w", err)
+		return fmt.Errorf("invalid expected chain ID %s: %w", expectedChainID, err)
 	}
 
-	return &Deployer{
-		account: accnt,
-		client:  client,
-		network: network,
-		logger:  logger,
-	}, nil
+	// The provider returns the chain ID decoded as a short string (e.g. SN_SEPOLIA)
+	actualChainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query chain ID from RPC: %w", err)
+	}
+	actual := new(felt.Felt).SetBytes([]byte(actualChainID))
+
+	if !actual.Equal(expected) {
+		return fmt.Errorf("chain ID mismatch: network %s expects %s (%s) but RPC reports %s (%s)",
+			network, utils.HexToShortStr(expectedChainID), expectedChainID, actualChainID, actual.String())
+	}
+
+	return nil
 }
 
-// DeployContract deploys a contract with the given configuration
-func (d *Deployer) DeployContract(contractInfo ContractInfo) (*DeploymentResult, error) {
-	d.logger.Infof("🚀 Starting deployment of %s contract", contractInfo.Name)
-	d.logger.Infof("📡 Network: %s", d.network)
-	d.logger.Infof("📋 Account: %s", d.account.Address.String())
+// DeployContract declares the contract if needed and deploys it through the UDC
+func (d *Deployer) DeployContract(ctx context.Context, contractInfo ContractInfo) (*DeploymentResult, error) {
+	log := d.logger.WithField("contract", contractInfo.Name)
+	log.Infof("🚀 Starting deployment of %s contract", contractInfo.Name)
+	log.Infof("📡 Network: %s", d.network)
+	log.Infof("📋 Account: %s", d.address.String())
+	progress := d.newProgressReporter(contractInfo.Name)
+	progress.report(StatusPending, "", 0, "Starting deployment")
 
 	// Step 1: Declare the contract
-	d.logger.Info("📋 Step 1: Declaring contract...")
-	classHash, err := d.declareContract(contractInfo.SierraPath, contractInfo.CasmPath)
-	if err != nil {
-		return nil, fmt.Errorf("contract declaration failed: %w", err)
+	declareLog := log.WithField("step", "declare")
+	declareLog.Info("📋 Step 1: Declaring contract...")
+	progress.startStep(StatusDeclaring, "declare", 0, "Declaring contract")
+	classHash, err := d.Declare(ctx, contractInfo.SierraPath, contractInfo.CasmPath)
+	if errors.Is(err, ErrAlreadyDeclared) {
+		declareLog.Info("✅ Contract already declared, reusing class hash")
+	} else if err != nil {
+		return nil, progress.failed(fmt.Errorf("contract declaration failed: %w", err))
 	}
-	d.logger.Infof("✅ Contract declaration completed! Class Hash: %s", classHash)
+	// Only declarations sent on chain are representative of the next steps
+	progress.finishStep(err == nil)
+	declareLog.Infof("✅ Contract declaration completed! Class Hash: %s", classHash)
 
 	// Wait before deployment
-	d.logger.Info("⏳ Waiting before deployment...")
-	time.Sleep(5 * time.Second)
+	deployLog := log.WithField("step", "deploy")
+	deployLog.Info("⏳ Waiting before deployment...")
+	progress.report(StatusDeclaring, "declare", 1, "Waiting before deployment")
+	if err := sleep(ctx, d.deployDelay); err != nil {
+		return nil, progress.failed(err)
+	}
 
 	// Step 2: Deploy the contract
-	d.logger.Info("📋 Step 2: Deploying contract...")
-	deployedAddress, txHash, err := d.deployContract(classHash, contractInfo.Constructor.Args)
+	deployLog.Info("📋 Step 2: Deploying contract...")
+	progress.startStep(StatusDeploying, "deploy", 1, "Deploying contract")
+	deployedAddress, txHash, err := d.Deploy(ctx, classHash, contractInfo.Constructor.Args)
 	if err != nil {
-		return nil, fmt.Errorf("contract deployment failed: %w", err)
+		return nil, progress.failed(fmt.Errorf("contract deployment failed: %w", err))
 	}
+	progress.finishStep(true)
 
-	d.logger.Infof("✅ Contract deployed successfully!")
-	d.logger.Infof("   Deployed Address: %s", deployedAddress)
-	d.logger.Infof("   Transaction Hash: %s", txHash)
+	deployLog = deployLog.WithField("tx_hash", txHash)
+	deployLog.Infof("✅ Contract deployed successfully!")
+	deployLog.Infof("   Deployed Address: %s", deployedAddress)
+	deployLog.Infof("   Transaction Hash: %s", txHash)
+	progress.completed(txHash, "Deployed at "+deployedAddress)
 
 	return &DeploymentResult{
 		ContractName:    contractInfo.Name,
@@ -100,8 +234,9 @@ func (d *Deployer) DeployContract(contractInfo ContractInfo) (*DeploymentResult,
 	}, nil
 }
 
-// declareContract declares a contract on the network
-func (d *Deployer) declareContract(sierraPath, casmPath string) (string, error) {
+// Declare declares a contract and returns its class hash. If the class already
+// exists the class hash is returned together with ErrAlreadyDeclared.
+func (d *Deployer) Declare(ctx context.Context, sierraPath, casmPath string) (string, error) {
 	d.logger.Debugf("📋 Loading contract files:")
 	d.logger.Debugf("   Sierra: %s", sierraPath)
 	d.logger.Debugf("   Casm: %s", casmPath)
@@ -126,37 +261,51 @@ func (d *Deployer) declareContract(sierraPath, casmPath string) (string, error)
 		return "", fmt.Errorf("failed to parse sierra contract: %w", err)
 	}
 
-	// Building and sending the declare transaction
+	// Skip the declaration when the class is already on chain; fee estimation would fail otherwise
+	classHash := hash.ClassHash(contractClass)
+	declared, err := d.IsDeclared(ctx, classHash)
+	if err != nil {
+		return "", err
+	}
+	if declared {
+		return classHash.String(), ErrAlreadyDeclared
+	}
+
+	// Building, signing and sending the declare transaction
 	d.logger.Debug("📤 Declaring contract...")
-	resp, err := d.account.BuildAndSendDeclareTxn(
-		context.Background(),
-		casmClass,
-		contractClass,
-		nil,
-	)
+	txHash, _, err := d.sendDeclare(ctx, casmClass, contractClass)
 	if err != nil {
-		// Check if it's an "already declared" error
-		if strings.Contains(err.Error(), "already declared") {
-			d.logger.Info("✅ Contract already declared, extracting class hash...")
-			// Use the proper ClassHash function from the hash package
-			classHash := hash.ClassHash(contractClass)
-			return classHash.String(), nil
+		// Another transaction may have declared the class since the check
+		if errors.Is(err, ErrAlreadyDeclared) {
+			return classHash.String(), err
 		}
 		return "", fmt.Errorf("failed to declare contract: %w", err)
 	}
 
 	// Wait for transaction receipt
 	d.logger.Debug("⏳ Waiting for declaration confirmation...")
-	_, err = d.account.WaitForTransactionReceipt(context.Background(), resp.Hash, time.Second)
-	if err != nil {
+	if _, err := d.WaitForReceipt(ctx, txHash); err != nil {
 		return "", fmt.Errorf("declare transaction failed: %w", err)
 	}
 
-	return resp.ClassHash.String(), nil
+	return classHash.String(), nil
 }
 
-// deployContract deploys a contract with constructor arguments
-func (d *Deployer) deployContract(classHash string, constructorArgs []*felt.Felt) (string, string, error) {
+// IsDeclared checks with starknet_getClass whether a class hash is declared
+func (d *Deployer) IsDeclared(ctx context.Context, classHash *felt.Felt) (bool, error) {
+	_, err := d.client.Class(ctx, rpc.WithBlockTag(rpc.BlockTagLatest), classHash)
+	if err == nil {
+		return true, nil
+	}
+	if nodeErr, ok := AsNodeError(err); ok && nodeErr.Code == rpc.ErrClassHashNotFound.Code {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check class %s: %w", classHash.String(), classifyError(err))
+}
+
+// Deploy deploys a declared class through the UDC and returns the contract address
+// and transaction hash
+func (d *Deployer) Deploy(ctx context.Context, classHash string, constructorArgs []*felt.Felt) (string, string, error) {
 	// Convert class hash to felt
 	classHashFelt, err := utils.HexToFelt(classHash)
 	if err != nil {
@@ -166,20 +315,22 @@ func (d *Deployer) deployContract(classHash string, constructorArgs []*felt.Felt
 	d.logger.Debug("📤 Sending deployment transaction...")
 
 	// Deploy the contract with UDC
-	resp, salt, err := d.account.DeployContractWithUDC(context.Background(), classHashFelt, constructorArgs, nil, nil)
+	udcCall, salt, err := utils.BuildUDCCalldata(classHashFelt, constructorArgs, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build UDC calldata: %w", err)
+	}
+	txHash, err := d.sendInvoke(ctx, []rpc.InvokeFunctionCall{udcCall})
 	if err != nil {
 		return "", "", fmt.Errorf("failed to deploy contract: %w", err)
 	}
 
-	// Extract transaction hash from response
-	txHash := resp.Hash
-	d.logger.Debugf("⏳ Transaction sent! Hash: %s", txHash.String())
+	d.logger.WithField("tx_hash", txHash.String()).Debugf("⏳ Transaction sent! Hash: %s", txHash.String())
 	d.logger.Debug("⏳ Waiting for transaction confirmation...")
 
 	// Wait for transaction receipt
-	txReceipt, err := d.account.WaitForTransactionReceipt(context.Background(), txHash, time.Second)
+	txReceipt, err := d.WaitForReceipt(ctx, txHash)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get transaction receipt: %w", err)
+		return "", "", fmt.Errorf("deploy transaction failed: %w", err)
 	}
 
 	d.logger.Debugf("✅ Transaction confirmed!")
@@ -187,14 +338,50 @@ func (d *Deployer) deployContract(classHash string, constructorArgs []*felt.Felt
 	d.logger.Debugf("   Finality Status: %s", txReceipt.FinalityStatus)
 
 	// Compute the deployed contract address
-	deployedAddress := utils.PrecomputeAddressForUDC(classHashFelt, salt, constructorArgs, utils.UDCCairoV0, d.account.Address)
+	deployedAddress := utils.PrecomputeAddressForUDC(classHashFelt, salt, constructorArgs, utils.UDCCairoV0, d.address)
 
 	return deployedAddress.String(), txHash.String(), nil
 }
 
+// WaitForReceipt waits for a transaction to reach the configured finality. It fails with
+// ErrReceiptTimeout after the deadline and with a *RevertError if the transaction reverted.
+func (d *Deployer) WaitForReceipt(ctx context.Context, txHash *felt.Felt) (*rpc.TransactionReceiptWithBlockInfo, error) {
+	receipt, err := NewWaiter(d.client, d.wait, d.logger).Wait(ctx, txHash)
+	if receipt != nil || errors.Is(err, ErrReverted) {
+		var fee *big.Int
+		var unit string
+		if receipt != nil && receipt.ActualFee.Amount != nil {
+			fee, unit = receipt.ActualFee.Amount.BigInt(new(big.Int)), string(receipt.ActualFee.Unit)
+		}
+		d.metrics.TransactionIncluded(errors.Is(err, ErrReverted), fee, unit)
+	}
+
+	// Included transactions (accepted or reverted) no longer hold a nonce in flight
+	if d.nonces != nil && (receipt != nil || errors.Is(err, ErrReverted)) {
+		if saveErr := d.nonces.Confirmed(txHash); saveErr != nil {
+			d.logger.Warnf("⚠️  %s", saveErr)
+		}
+	}
+
+	return receipt, err
+}
+
+// sleep pauses for delay unless ctx is cancelled first
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // GetAccountAddress returns the deployer account address
 func (d *Deployer) GetAccountAddress() string {
-	return d.account.Address.String()
+	return d.address.String()
 }
 
 // GetNetwork returns the network name
@@ -202,3 +389,7 @@ func (d *Deployer) GetNetwork() string {
 	return d.network
 }
 
+// Logger returns the logger used by the deployer
+func (d *Deployer) Logger() *logrus.Logger {
+	return d.logger
+}
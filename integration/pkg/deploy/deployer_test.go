@@ -0,0 +1,456 @@
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/contracts"
+	"github.com/NethermindEth/starknet.go/hash"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy/deploytest"
+	"github.com/NovemberFork/etheracts/integration/pkg/metrics"
+	"github.com/NovemberFork/etheracts/integration/pkg/signer"
+)
+
+const (
+	accountAddress = "0x1234"
+	sierraPath     = "testdata/etheracts_Minimal.contract_class.json"
+	casmPath       = "testdata/etheracts_Minimal.compiled_contract_class.json"
+)
+
+// fastWait polls every few milliseconds and gives up quickly, so timeouts stay cheap
+var fastWait = deploy.WaitOptions{
+	Timeout:         200 * time.Millisecond,
+	InitialInterval: time.Millisecond,
+	MaxInterval:     5 * time.Millisecond,
+	Multiplier:      2,
+	Finality:        rpc.TxnStatus_Accepted_On_L2,
+}
+
+func newDeployer(t *testing.T, provider *deploytest.Provider, opts ...deploy.Option) *deploy.Deployer {
+	t.Helper()
+
+	s, err := signer.NewLocalSignerFromHex("0x1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts = append([]deploy.Option{
+		deploy.WithSigner(s),
+		deploy.WithWaitOptions(fastWait),
+		deploy.WithDeployDelay(0),
+	}, opts...)
+
+	d, err := deploy.NewDeployerWithProvider(context.Background(), provider, "testnet", deploytest.ChainIDHex, accountAddress, opts...)
+	if err != nil {
+		t.Fatalf("NewDeployerWithProvider: %v", err)
+	}
+	return d
+}
+
+func minimalContract() deploy.ContractInfo {
+	return deploy.ContractInfo{
+		Name:        "Minimal",
+		SierraPath:  sierraPath,
+		CasmPath:    casmPath,
+		Constructor: deploy.ConstructorArgs{Args: []*felt.Felt{new(felt.Felt).SetUint64(7)}},
+	}
+}
+
+func minimalClassHash(t *testing.T) *felt.Felt {
+	t.Helper()
+	class, err := utils.UnmarshalJSONFileToType[contracts.ContractClass](sierraPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.ClassHash(class)
+}
+
+func TestDeployContractDeclaresAndDeploys(t *testing.T) {
+	provider := deploytest.NewProvider()
+	d := newDeployer(t, provider)
+
+	result, err := d.DeployContract(context.Background(), minimalContract())
+	if err != nil {
+		t.Fatalf("DeployContract: %v", err)
+	}
+
+	classHash := minimalClassHash(t)
+	if result.ClassHash != classHash.String() {
+		t.Errorf("class hash = %s, want %s", result.ClassHash, classHash)
+	}
+	if !provider.IsDeclared(classHash) {
+		t.Error("class was not declared")
+	}
+	if n := len(provider.Declares()); n != 1 {
+		t.Errorf("declares = %d, want 1", n)
+	}
+
+	invokes := provider.Invokes()
+	if len(invokes) != 1 {
+		t.Fatalf("invokes = %d, want 1", len(invokes))
+	}
+	if invokes[0].Nonce.Uint64() != 1 {
+		t.Errorf("deploy nonce = %s, want 1", invokes[0].Nonce)
+	}
+	if len(invokes[0].Signature) != 2 {
+		t.Errorf("deploy transaction is not signed")
+	}
+	if result.TransactionHash == "" || result.DeployedAddress == "" {
+		t.Errorf("incomplete result: %+v", result)
+	}
+}
+
+func TestDeployContractReusesDeclaredClass(t *testing.T) {
+	provider := deploytest.NewProvider()
+	provider.Declare(minimalClassHash(t))
+	d := newDeployer(t, provider)
+
+	if _, err := d.DeployContract(context.Background(), minimalContract()); err != nil {
+		t.Fatalf("DeployContract: %v", err)
+	}
+	if n := len(provider.Declares()); n != 0 {
+		t.Errorf("declares = %d, want 0", n)
+	}
+	if n := len(provider.Invokes()); n != 1 {
+		t.Errorf("invokes = %d, want 1", n)
+	}
+}
+
+func TestDeclareRacedByAnotherTransaction(t *testing.T) {
+	provider := deploytest.NewProvider()
+	provider.FailNext("AddDeclareTransaction", rpc.ErrClassAlreadyDeclared)
+	d := newDeployer(t, provider)
+
+	classHash, err := d.Declare(context.Background(), sierraPath, casmPath)
+	if !errors.Is(err, deploy.ErrAlreadyDeclared) {
+		t.Fatalf("Declare error = %v, want ErrAlreadyDeclared", err)
+	}
+	if classHash != minimalClassHash(t).String() {
+		t.Errorf("class hash = %s, want %s", classHash, minimalClassHash(t))
+	}
+
+	// DeployContract treats the race like a class declared before the check
+	if _, err := d.DeployContract(context.Background(), minimalContract()); err != nil {
+		t.Fatalf("DeployContract: %v", err)
+	}
+}
+
+func TestDeployContractReverted(t *testing.T) {
+	provider := deploytest.NewProvider()
+	provider.Script(deploytest.Outcome{}, deploytest.Outcome{RevertReason: "Constructor failed"})
+	d := newDeployer(t, provider)
+
+	_, err := d.DeployContract(context.Background(), minimalContract())
+	if !errors.Is(err, deploy.ErrReverted) {
+		t.Fatalf("error = %v, want ErrReverted", err)
+	}
+	var revertErr *deploy.RevertError
+	if !errors.As(err, &revertErr) || revertErr.Reason != "Constructor failed" {
+		t.Errorf("revert error = %v, want reason %q", err, "Constructor failed")
+	}
+}
+
+func TestDeclareReverted(t *testing.T) {
+	provider := deploytest.NewProvider()
+	provider.RevertNext("Invalid compiled class")
+	d := newDeployer(t, provider)
+
+	_, err := d.DeployContract(context.Background(), minimalContract())
+	if !errors.Is(err, deploy.ErrReverted) {
+		t.Fatalf("error = %v, want ErrReverted", err)
+	}
+	if provider.IsDeclared(minimalClassHash(t)) {
+		t.Error("reverted declare declared the class")
+	}
+	if n := len(provider.Invokes()); n != 0 {
+		t.Errorf("invokes = %d, want no deployment after a failed declare", n)
+	}
+}
+
+func TestDeployContractReportsProgress(t *testing.T) {
+	provider := deploytest.NewProvider()
+	var updates []deploy.DeploymentProgress
+	d := newDeployer(t, provider, deploy.WithProgress(func(p deploy.DeploymentProgress) {
+		updates = append(updates, p)
+	}))
+
+	result, err := d.DeployContract(context.Background(), minimalContract())
+	if err != nil {
+		t.Fatalf("DeployContract: %v", err)
+	}
+
+	want := []struct {
+		status   deploy.DeploymentStatus
+		step     string
+		progress float64
+	}{
+		{deploy.StatusPending, "", 0},
+		{deploy.StatusDeclaring, "declare", 0},
+		{deploy.StatusDeclaring, "declare", 0.5},
+		{deploy.StatusDeploying, "deploy", 0.5},
+		{deploy.StatusCompleted, "deploy", 1},
+	}
+	if len(updates) != len(want) {
+		t.Fatalf("updates = %+v, want %d", updates, len(want))
+	}
+	for i, w := range want {
+		u := updates[i]
+		if u.Status != w.status || u.CurrentStep != w.step || u.Progress != w.progress {
+			t.Errorf("update %d = %s/%s/%.1f, want %s/%s/%.1f", i, u.Status, u.CurrentStep, u.Progress, w.status, w.step, w.progress)
+		}
+		if u.Contract != "Minimal" || u.TotalSteps != 2 {
+			t.Errorf("update %d = %+v, want contract Minimal with 2 steps", i, u)
+		}
+		if i > 0 && u.ElapsedTime < updates[i-1].ElapsedTime {
+			t.Errorf("update %d elapsed time went back", i)
+		}
+	}
+	if updates[0].EstimatedTime <= 0 {
+		t.Error("no estimate before the deployment")
+	}
+	last := updates[len(updates)-1]
+	if last.EstimatedTime != 0 || last.TransactionHash != result.TransactionHash {
+		t.Errorf("completed update = %+v, want no time left and tx %s", last, result.TransactionHash)
+	}
+
+	// Estimates of later deployments are based on the timed steps
+	updates = nil
+	if _, err := d.DeployContract(context.Background(), minimalContract()); err != nil {
+		t.Fatalf("DeployContract: %v", err)
+	}
+	if updates[0].EstimatedTime >= time.Second {
+		t.Errorf("estimate = %s, want the duration of the fake transactions", updates[0].EstimatedTime)
+	}
+}
+
+func TestDeployContractReportsFailure(t *testing.T) {
+	provider := deploytest.NewProvider()
+	provider.Script(deploytest.Outcome{}, deploytest.Outcome{RevertReason: "Constructor failed"})
+	report, updates := deploy.ProgressChannel(10)
+	d := newDeployer(t, provider, deploy.WithProgress(report))
+
+	if _, err := d.DeployContract(context.Background(), minimalContract()); err == nil {
+		t.Fatal("DeployContract succeeded")
+	}
+
+	// The channel is closed after the final update
+	var last deploy.DeploymentProgress
+	for p := range updates {
+		last = p
+	}
+	if last.Status != deploy.StatusFailed || last.CurrentStep != "deploy" || last.Progress != 0.5 {
+		t.Errorf("last update = %+v, want failed deploy step at 50%%", last)
+	}
+}
+
+func TestProgressChannelDeliversFinalUpdate(t *testing.T) {
+	provider := deploytest.NewProvider()
+	// Nobody reads during the deployment, so the single slot fills with the first update
+	report, updates := deploy.ProgressChannel(1)
+	d := newDeployer(t, provider, deploy.WithProgress(report))
+
+	result, err := d.DeployContract(context.Background(), minimalContract())
+	if err != nil {
+		t.Fatalf("DeployContract: %v", err)
+	}
+
+	var received []deploy.DeploymentProgress
+	for p := range updates {
+		received = append(received, p)
+	}
+	if len(received) != 1 || received[0].Status != deploy.StatusCompleted || received[0].TransactionHash != result.TransactionHash {
+		t.Fatalf("updates = %+v, want only the completed update", received)
+	}
+
+	// Later deployments do not send on the closed channel
+	if _, err := d.DeployContract(context.Background(), minimalContract()); err != nil {
+		t.Fatalf("DeployContract: %v", err)
+	}
+}
+
+func TestDeployContractRecordsMetrics(t *testing.T) {
+	provider := deploytest.NewProvider()
+	m := metrics.New()
+	d := newDeployer(t, provider, deploy.WithMetrics(m))
+
+	if _, err := d.DeployContract(context.Background(), minimalContract()); err != nil {
+		t.Fatalf("DeployContract: %v", err)
+	}
+
+	out := httptest.NewRecorder()
+	m.Handler().ServeHTTP(out, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, line := range []string{
+		`ethrx_transactions_submitted_total{type="DECLARE"} 1`,
+		`ethrx_transactions_submitted_total{type="INVOKE"} 1`,
+		`ethrx_transactions_confirmed_total 2`,
+		`ethrx_fees_paid_total{unit="FRI"} 6`,
+	} {
+		if !strings.Contains(out.Body.String(), line+"\n") {
+			t.Errorf("metrics lack %q:\n%s", line, out.Body.String())
+		}
+	}
+}
+
+func TestDeclareTimeout(t *testing.T) {
+	provider := deploytest.NewProvider()
+	provider.DropNext()
+	d := newDeployer(t, provider)
+
+	_, err := d.Declare(context.Background(), sierraPath, casmPath)
+	if !errors.Is(err, deploy.ErrReceiptTimeout) {
+		t.Fatalf("error = %v, want ErrReceiptTimeout", err)
+	}
+}
+
+func TestWaitFollowsFinality(t *testing.T) {
+	provider := deploytest.NewProvider()
+	provider.Script(deploytest.Outcome{Pending: 3})
+	d := newDeployer(t, provider)
+
+	txHash, err := d.Invoke(context.Background(), []rpc.InvokeFunctionCall{{
+		ContractAddress: new(felt.Felt).SetUint64(0x99),
+		FunctionName:    "ping",
+	}})
+	if err != nil {
+		t.Fatalf("Invoke: %v", err)
+	}
+
+	receipt, err := d.WaitForReceipt(context.Background(), txHash)
+	if err != nil {
+		t.Fatalf("WaitForReceipt: %v", err)
+	}
+	if receipt.ExecutionStatus != rpc.TxnExecutionStatusSUCCEEDED {
+		t.Errorf("execution status = %s", receipt.ExecutionStatus)
+	}
+	if n := provider.Requests("GetTransactionStatus"); n != 4 {
+		t.Errorf("status polls = %d, want 4", n)
+	}
+}
+
+func TestWaitOptionsDefaults(t *testing.T) {
+	provider := deploytest.NewProvider()
+	provider.Script(deploytest.Outcome{Pending: 1})
+	// Only the first interval is set; a zero timeout or multiplier would fail or spin
+	d := newDeployer(t, provider, deploy.WithWaitOptions(deploy.WaitOptions{InitialInterval: time.Millisecond}))
+
+	txHash, err := d.Invoke(context.Background(), []rpc.InvokeFunctionCall{{
+		ContractAddress: new(felt.Felt).SetUint64(0x99),
+		FunctionName:    "ping",
+	}})
+	if err != nil {
+		t.Fatalf("Invoke: %v", err)
+	}
+	if _, err := d.WaitForReceipt(context.Background(), txHash); err != nil {
+		t.Fatalf("WaitForReceipt: %v", err)
+	}
+}
+
+func TestWaitOptionsRejectMultiplierBelowOne(t *testing.T) {
+	opts := fastWait
+	opts.Multiplier = 0.5
+
+	_, err := deploy.NewDeployerWithProvider(context.Background(), deploytest.NewProvider(), "testnet", deploytest.ChainIDHex, accountAddress, deploy.WithWaitOptions(opts))
+	if want := "poll interval multiplier 0.5 is below 1"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("err = %v, want %q", err, want)
+	}
+}
+
+func TestSubmissionErrorsAreClassified(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		err    error
+		want   error
+	}{
+		{"invalid nonce", "AddInvokeTransaction", rpc.ErrInvalidTransactionNonce, deploy.ErrInvalidNonce},
+		{"insufficient balance", "EstimateFee", rpc.ErrInsufficientAccountBalance, deploy.ErrInsufficientFee},
+		{"fee below minimum", "AddInvokeTransaction", rpc.ErrFeeBelowMinimum, deploy.ErrInsufficientFee},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := deploytest.NewProvider()
+			provider.FailNext(tt.method, tt.err)
+			d := newDeployer(t, provider)
+
+			_, _, err := d.Deploy(context.Background(), minimalClassHash(t).String(), nil)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			var nodeErr *deploy.NodeError
+			if !errors.As(err, &nodeErr) {
+				t.Errorf("error %v does not carry the node error", err)
+			}
+		})
+	}
+}
+
+func TestLostSubmissionResponseIsCheckedByHash(t *testing.T) {
+	provider := deploytest.NewProvider()
+	provider.Script(deploytest.Outcome{LostResponse: errors.New("context deadline exceeded")})
+	d := newDeployer(t, provider)
+	calls := []rpc.InvokeFunctionCall{{ContractAddress: new(felt.Felt).SetUint64(0x99), FunctionName: "ping"}}
+
+	// The node accepted the transaction, so its hash is returned rather than an error
+	txHash, err := d.Invoke(context.Background(), calls)
+	if err != nil {
+		t.Fatalf("Invoke: %v", err)
+	}
+	if invokes := provider.Invokes(); len(invokes) != 1 {
+		t.Fatalf("invokes = %d, want 1", len(invokes))
+	}
+	if _, err := d.WaitForReceipt(context.Background(), txHash); err != nil {
+		t.Fatalf("WaitForReceipt: %v", err)
+	}
+
+	// A failed submission the node does not know about is reported
+	provider.FailNext("AddInvokeTransaction", errors.New("context deadline exceeded"))
+	if _, err := d.Invoke(context.Background(), calls); err == nil {
+		t.Error("unknown transaction reported as submitted")
+	}
+}
+
+func TestNonceTrackingPipelinesInvokes(t *testing.T) {
+	provider := deploytest.NewProvider()
+	provider.Script(deploytest.Outcome{Pending: 5}, deploytest.Outcome{Pending: 5})
+	d := newDeployer(t, provider, deploy.WithNonceTracking("", 2))
+
+	call := []rpc.InvokeFunctionCall{{ContractAddress: new(felt.Felt).SetUint64(0x99), FunctionName: "ping"}}
+	first, err := d.Invoke(context.Background(), call)
+	if err != nil {
+		t.Fatalf("first Invoke: %v", err)
+	}
+	second, err := d.Invoke(context.Background(), call)
+	if err != nil {
+		t.Fatalf("second Invoke: %v", err)
+	}
+
+	invokes := provider.Invokes()
+	if len(invokes) != 2 || invokes[0].Nonce.Uint64() != 0 || invokes[1].Nonce.Uint64() != 1 {
+		t.Fatalf("expected nonces 0 and 1 in flight, got %d invokes", len(invokes))
+	}
+	for _, txHash := range []*felt.Felt{first, second} {
+		if _, err := d.WaitForReceipt(context.Background(), txHash); err != nil {
+			t.Fatalf("WaitForReceipt: %v", err)
+		}
+	}
+}
+
+func TestChainIDMismatch(t *testing.T) {
+	provider := deploytest.NewProvider()
+	provider.SetChainID("SN_MAIN")
+
+	_, err := deploy.NewDeployerWithProvider(context.Background(), provider, "testnet", deploytest.ChainIDHex, accountAddress)
+	if err == nil {
+		t.Fatal("expected a chain ID mismatch error")
+	}
+}
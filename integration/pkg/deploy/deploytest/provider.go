@@ -0,0 +1,413 @@
+// Package deploytest provides an in-memory Starknet provider for testing deployment logic
+// without a node
+package deploytest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/hash"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+)
+
+// ChainID is the chain served by a new Provider, as a short string and in hex
+const (
+	ChainID    = "SN_SEPOLIA"
+	ChainIDHex = "0x534e5f5345504f4c4941"
+)
+
+// Outcome scripts what happens to a submitted transaction
+type Outcome struct {
+	// RevertReason makes the transaction revert with this reason
+	RevertReason string
+	// Drop keeps the transaction unknown to the node, so waiting for it times out
+	Drop bool
+	// Pending is the number of status polls answered with RECEIVED before the outcome applies
+	Pending int
+	// LostResponse is returned by the submission although the transaction was accepted,
+	// like a timeout after the node received it
+	LostResponse error
+}
+
+// CallHandler answers a starknet_call to one entry point
+type CallHandler func(call rpc.FunctionCall) ([]*felt.Felt, error)
+
+// Provider is an in-memory deploy.Provider. Submitted transactions are recorded and
+// included immediately unless an outcome was scripted for them; declared classes and
+// account nonces are tracked so the deployer sees a consistent chain.
+type Provider struct {
+	mu sync.Mutex
+
+	chainID  string
+	nonces   map[felt.Felt]uint64
+	classes  map[felt.Felt]bool
+	txs      map[felt.Felt]*transaction
+	block    uint64
+	outcomes []Outcome
+	errs     map[string][]error
+	handlers map[felt.Felt]CallHandler
+	requests map[string]int
+
+	invokes  []*rpc.BroadcastInvokeTxnV3
+	declares []*rpc.BroadcastDeclareTxnV3
+}
+
+// transaction is the simulated on-chain state of a submitted transaction
+type transaction struct {
+	hash      *felt.Felt
+	txType    rpc.TransactionType
+	outcome   Outcome
+	classHash *felt.Felt // declared class, nil for invokes
+	block     uint64
+}
+
+var _ deploy.Provider = (*Provider)(nil)
+
+// NewProvider creates an empty chain serving ChainID
+func NewProvider() *Provider {
+	return &Provider{
+		chainID:  ChainID,
+		nonces:   make(map[felt.Felt]uint64),
+		classes:  make(map[felt.Felt]bool),
+		txs:      make(map[felt.Felt]*transaction),
+		errs:     make(map[string][]error),
+		handlers: make(map[felt.Felt]CallHandler),
+		requests: make(map[string]int),
+	}
+}
+
+// SetChainID changes the chain ID reported by starknet_chainId (as a short string)
+func (p *Provider) SetChainID(chainID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.chainID = chainID
+}
+
+// SetNonce sets the nonce of an account
+func (p *Provider) SetNonce(address *felt.Felt, nonce uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nonces[*address] = nonce
+}
+
+// Declare marks a class hash as already declared
+func (p *Provider) Declare(classHash *felt.Felt) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.classes[*classHash] = true
+}
+
+// IsDeclared reports whether a class hash is declared
+func (p *Provider) IsDeclared(classHash *felt.Felt) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.classes[*classHash]
+}
+
+// Script queues outcomes for the next submitted transactions, in order
+func (p *Provider) Script(outcomes ...Outcome) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.outcomes = append(p.outcomes, outcomes...)
+}
+
+// RevertNext makes the next submitted transaction revert with reason
+func (p *Provider) RevertNext(reason string) {
+	p.Script(Outcome{RevertReason: reason})
+}
+
+// DropNext makes the next submitted transaction never reach the chain
+func (p *Provider) DropNext() {
+	p.Script(Outcome{Drop: true})
+}
+
+// FailNext makes the next request to method (e.g. "AddInvokeTransaction") fail with err.
+// Errors queued for the same method are returned in order.
+func (p *Provider) FailNext(method string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.errs[method] = append(p.errs[method], err)
+}
+
+// HandleCall answers starknet_call requests to the named entry point with fn
+func (p *Provider) HandleCall(entryPoint string, fn CallHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[*utils.GetSelectorFromNameFelt(entryPoint)] = fn
+}
+
+// Invokes returns the submitted invoke transactions
+func (p *Provider) Invokes() []*rpc.BroadcastInvokeTxnV3 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*rpc.BroadcastInvokeTxnV3(nil), p.invokes...)
+}
+
+// Declares returns the submitted declare transactions
+func (p *Provider) Declares() []*rpc.BroadcastDeclareTxnV3 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*rpc.BroadcastDeclareTxnV3(nil), p.declares...)
+}
+
+// Requests returns how often method was requested, including failed requests
+func (p *Provider) Requests(method string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.requests[method]
+}
+
+// request counts a request to method and returns its scripted error, if any.
+// The caller must hold p.mu.
+func (p *Provider) request(method string) error {
+	p.requests[method]++
+	queued := p.errs[method]
+	if len(queued) == 0 {
+		return nil
+	}
+	p.errs[method] = queued[1:]
+	return queued[0]
+}
+
+// ChainID implements deploy.Provider
+func (p *Provider) ChainID(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.request("ChainID"); err != nil {
+		return "", err
+	}
+	return p.chainID, nil
+}
+
+// Nonce implements deploy.Provider
+func (p *Provider) Nonce(ctx context.Context, blockID rpc.BlockID, contractAddress *felt.Felt) (*felt.Felt, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.request("Nonce"); err != nil {
+		return nil, err
+	}
+	return new(felt.Felt).SetUint64(p.nonces[*contractAddress]), nil
+}
+
+// Class implements deploy.Provider. It only reports whether the class exists.
+func (p *Provider) Class(ctx context.Context, blockID rpc.BlockID, classHash *felt.Felt) (rpc.ClassOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.request("Class"); err != nil {
+		return nil, err
+	}
+	if !p.classes[*classHash] {
+		return nil, rpc.ErrClassHashNotFound
+	}
+	return struct{}{}, nil
+}
+
+// Call implements deploy.Provider with the handlers registered by HandleCall
+func (p *Provider) Call(ctx context.Context, call rpc.FunctionCall, blockID rpc.BlockID) ([]*felt.Felt, error) {
+	p.mu.Lock()
+	err := p.request("Call")
+	handler, ok := p.handlers[*call.EntryPointSelector]
+	p.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, rpc.ErrEntrypointNotFound
+	}
+	return handler(call)
+}
+
+// EstimateFee implements deploy.Provider with a fixed estimate per transaction
+func (p *Provider) EstimateFee(ctx context.Context, requests []rpc.BroadcastTxn, simulationFlags []rpc.SimulationFlag, blockID rpc.BlockID) ([]rpc.FeeEstimation, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.request("EstimateFee"); err != nil {
+		return nil, err
+	}
+
+	one := new(felt.Felt).SetUint64(1)
+	estimates := make([]rpc.FeeEstimation, len(requests))
+	for i := range requests {
+		estimates[i] = rpc.FeeEstimation{
+			FeeEstimationCommon: rpc.FeeEstimationCommon{
+				L1GasConsumed:     one,
+				L1GasPrice:        one,
+				L2GasConsumed:     one,
+				L2GasPrice:        one,
+				L1DataGasConsumed: one,
+				L1DataGasPrice:    one,
+				OverallFee:        new(felt.Felt).SetUint64(3),
+			},
+			Unit: rpc.FriUnit,
+		}
+	}
+	return estimates, nil
+}
+
+// AddInvokeTransaction implements deploy.Provider. The transaction hash is computed as
+// a node would, so it matches the hash the deployer signed.
+func (p *Provider) AddInvokeTransaction(ctx context.Context, invokeTxn *rpc.BroadcastInvokeTxnV3) (rpc.AddInvokeTransactionResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.request("AddInvokeTransaction"); err != nil {
+		return rpc.AddInvokeTransactionResponse{}, err
+	}
+	if err := p.checkNonce(invokeTxn.SenderAddress, invokeTxn.Nonce); err != nil {
+		return rpc.AddInvokeTransactionResponse{}, err
+	}
+
+	txHash, err := hash.TransactionHashInvokeV3(invokeTxn, p.chainIDFelt())
+	if err != nil {
+		return rpc.AddInvokeTransactionResponse{}, fmt.Errorf("deploytest: %w", err)
+	}
+
+	p.invokes = append(p.invokes, invokeTxn)
+	tx := &transaction{hash: txHash, txType: rpc.TransactionType_Invoke}
+	p.submit(tx, invokeTxn.SenderAddress, invokeTxn.Nonce)
+	if tx.outcome.LostResponse != nil {
+		return rpc.AddInvokeTransactionResponse{}, tx.outcome.LostResponse
+	}
+	return rpc.AddInvokeTransactionResponse{Hash: txHash}, nil
+}
+
+// AddDeclareTransaction implements deploy.Provider. The class is declared once the
+// transaction is included and did not revert.
+func (p *Provider) AddDeclareTransaction(ctx context.Context, declareTxn *rpc.BroadcastDeclareTxnV3) (rpc.AddDeclareTransactionResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.request("AddDeclareTransaction"); err != nil {
+		return rpc.AddDeclareTransactionResponse{}, err
+	}
+	if err := p.checkNonce(declareTxn.SenderAddress, declareTxn.Nonce); err != nil {
+		return rpc.AddDeclareTransactionResponse{}, err
+	}
+
+	classHash := hash.ClassHash(declareTxn.ContractClass)
+	if p.classes[*classHash] {
+		return rpc.AddDeclareTransactionResponse{}, rpc.ErrClassAlreadyDeclared
+	}
+
+	txHash, err := hash.TransactionHashBroadcastDeclareV3(declareTxn, p.chainIDFelt())
+	if err != nil {
+		return rpc.AddDeclareTransactionResponse{}, fmt.Errorf("deploytest: %w", err)
+	}
+
+	p.declares = append(p.declares, declareTxn)
+	tx := &transaction{hash: txHash, txType: rpc.TransactionType_Declare, classHash: classHash}
+	p.submit(tx, declareTxn.SenderAddress, declareTxn.Nonce)
+	if tx.outcome.LostResponse != nil {
+		return rpc.AddDeclareTransactionResponse{}, tx.outcome.LostResponse
+	}
+	return rpc.AddDeclareTransactionResponse{Hash: txHash, ClassHash: classHash}, nil
+}
+
+// checkNonce rejects transactions reusing an account nonce. The caller must hold p.mu.
+func (p *Provider) checkNonce(sender, nonce *felt.Felt) error {
+	if nonce.Uint64() < p.nonces[*sender] {
+		return rpc.ErrInvalidTransactionNonce
+	}
+	return nil
+}
+
+// submit records a transaction with the next scripted outcome. Transactions that are not
+// dropped use their nonce immediately. The caller must hold p.mu.
+func (p *Provider) submit(tx *transaction, sender, nonce *felt.Felt) {
+	if len(p.outcomes) > 0 {
+		tx.outcome = p.outcomes[0]
+		p.outcomes = p.outcomes[1:]
+	}
+	p.txs[*tx.hash] = tx
+	if tx.outcome.Drop {
+		return
+	}
+
+	if next := nonce.Uint64() + 1; next > p.nonces[*sender] {
+		p.nonces[*sender] = next
+	}
+	if tx.outcome.Pending == 0 {
+		p.include(tx)
+	}
+}
+
+// include adds a transaction to a new block. The caller must hold p.mu.
+func (p *Provider) include(tx *transaction) {
+	p.block++
+	tx.block = p.block
+	if tx.classHash != nil && tx.outcome.RevertReason == "" {
+		p.classes[*tx.classHash] = true
+	}
+}
+
+// GetTransactionStatus implements deploy.Provider. Pending transactions report RECEIVED
+// until their scripted polls are used up.
+func (p *Provider) GetTransactionStatus(ctx context.Context, transactionHash *felt.Felt) (*rpc.TxnStatusResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.request("GetTransactionStatus"); err != nil {
+		return nil, err
+	}
+
+	tx, ok := p.txs[*transactionHash]
+	if !ok || tx.outcome.Drop {
+		return nil, rpc.ErrHashNotFound
+	}
+	if tx.block == 0 {
+		if tx.outcome.Pending > 0 {
+			tx.outcome.Pending--
+			return &rpc.TxnStatusResult{FinalityStatus: rpc.TxnStatus_Received}, nil
+		}
+		p.include(tx)
+	}
+
+	status := &rpc.TxnStatusResult{
+		FinalityStatus:  rpc.TxnStatus_Accepted_On_L2,
+		ExecutionStatus: rpc.TxnExecutionStatusSUCCEEDED,
+	}
+	if tx.outcome.RevertReason != "" {
+		status.ExecutionStatus = rpc.TxnExecutionStatusREVERTED
+		status.FailureReason = tx.outcome.RevertReason
+	}
+	return status, nil
+}
+
+// TransactionReceipt implements deploy.Provider for included transactions
+func (p *Provider) TransactionReceipt(ctx context.Context, transactionHash *felt.Felt) (*rpc.TransactionReceiptWithBlockInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.request("TransactionReceipt"); err != nil {
+		return nil, err
+	}
+
+	tx, ok := p.txs[*transactionHash]
+	if !ok || tx.block == 0 {
+		return nil, rpc.ErrHashNotFound
+	}
+
+	receipt := &rpc.TransactionReceiptWithBlockInfo{
+		TransactionReceipt: rpc.TransactionReceipt{
+			Hash:            tx.hash,
+			Type:            tx.txType,
+			ActualFee:       rpc.FeePayment{Amount: new(felt.Felt).SetUint64(3), Unit: rpc.UnitFri},
+			FinalityStatus:  rpc.TxnFinalityStatusAcceptedOnL2,
+			ExecutionStatus: rpc.TxnExecutionStatusSUCCEEDED,
+		},
+		BlockHash:   new(felt.Felt).SetUint64(tx.block),
+		BlockNumber: uint(tx.block),
+	}
+	if tx.outcome.RevertReason != "" {
+		receipt.ExecutionStatus = rpc.TxnExecutionStatusREVERTED
+		receipt.RevertReason = tx.outcome.RevertReason
+	}
+	return receipt, nil
+}
+
+// chainIDFelt returns the chain ID as a felt. The caller must hold p.mu.
+func (p *Provider) chainIDFelt() *felt.Felt {
+	return new(felt.Felt).SetBytes([]byte(p.chainID))
+}
@@ -0,0 +1,114 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NethermindEth/starknet.go/rpc"
+)
+
+var (
+	// ErrAlreadyDeclared is returned when the class being declared already exists on chain
+	ErrAlreadyDeclared = errors.New("class already declared")
+	// ErrReceiptTimeout is returned when a transaction receipt did not arrive in time
+	ErrReceiptTimeout = errors.New("timed out waiting for transaction receipt")
+	// ErrReverted is returned when a transaction was included but its execution reverted
+	ErrReverted = errors.New("transaction reverted")
+	// ErrInsufficientFee is returned when the account cannot cover the transaction fee
+	ErrInsufficientFee = errors.New("insufficient fee")
+	// ErrInvalidNonce is returned when the transaction nonce was already used or is too far ahead
+	ErrInvalidNonce = errors.New("invalid transaction nonce")
+	// ErrCompiledClassHashMismatch is returned when the casm file does not match the sierra class
+	ErrCompiledClassHashMismatch = errors.New("compiled class hash mismatch")
+)
+
+// RevertError describes a reverted transaction. It matches ErrReverted with errors.Is.
+type RevertError struct {
+	TransactionHash string
+	Reason          string
+}
+
+func (e *RevertError) Error() string {
+	return fmt.Sprintf("transaction %s reverted: %s", e.TransactionHash, e.Reason)
+}
+
+func (e *RevertError) Unwrap() error {
+	return ErrReverted
+}
+
+// NodeError is a JSON-RPC error returned by the node. Data holds the node's
+// execution error details, such as the failing contract call and its revert message.
+type NodeError struct {
+	Code    int
+	Message string
+	Data    string
+}
+
+func (e *NodeError) Error() string {
+	if e.Data == "" {
+		return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("rpc error %d: %s: %s", e.Code, e.Message, e.Data)
+}
+
+// classifyError converts RPC errors into a *NodeError wrapped with the matching sentinel
+// so callers can use errors.Is and errors.As instead of matching messages
+func classifyError(err error) error {
+	nodeErr, ok := AsNodeError(err)
+	if !ok {
+		return err
+	}
+
+	switch nodeErr.Code {
+	case rpc.ErrClassAlreadyDeclared.Code:
+		return fmt.Errorf("%w: %w", ErrAlreadyDeclared, nodeErr)
+	case rpc.ErrInvalidTransactionNonce.Code:
+		return fmt.Errorf("%w: %w", ErrInvalidNonce, nodeErr)
+	case rpc.ErrCompiledClassHashMismatch.Code:
+		return fmt.Errorf("%w: %w", ErrCompiledClassHashMismatch, nodeErr)
+	case rpc.ErrInsufficientResourcesForValidate.Code,
+		rpc.ErrInsufficientAccountBalance.Code,
+		rpc.ErrReplacementTransactionUnderpriced.Code,
+		rpc.ErrFeeBelowMinimum.Code:
+		return fmt.Errorf("%w: %w", ErrInsufficientFee, nodeErr)
+	}
+
+	return nodeErr
+}
+
+// rejected reports whether a classified submission error means the node refused the
+// transaction. After transport failures and internal node errors it is unknown whether
+// the transaction was accepted.
+func rejected(err error) bool {
+	var nodeErr *NodeError
+	return errors.As(err, &nodeErr) && nodeErr.Code != rpc.InternalError && nodeErr.Code != rpc.ErrUnexpectedError.Code
+}
+
+// AsNodeError extracts the code, message and data of the RPC error behind err
+func AsNodeError(err error) (*NodeError, bool) {
+	var rpcErr *rpc.RPCError
+	if !errors.As(err, &rpcErr) {
+		return nil, false
+	}
+
+	nodeErr := &NodeError{Code: rpcErr.Code, Message: rpcErr.Message}
+	if rpcErr.Data != nil {
+		nodeErr.Data = rpcErr.Data.ErrorMessage()
+	}
+
+	// starknet.go reports errors it does not expect for a method (or whose message differs
+	// from the spec) as internal errors whose data starts with the original code, and HTTP
+	// errors with their status the same way
+	if rpcErr.Code == rpc.InternalError {
+		code, rest, _ := strings.Cut(nodeErr.Data, " ")
+		if original, err := strconv.Atoi(code); err == nil {
+			nodeErr.Code = original
+			nodeErr.Message = rest
+			nodeErr.Data = ""
+		}
+	}
+
+	return nodeErr, true
+}
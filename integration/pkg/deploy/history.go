@@ -92,3 +92,56 @@ func (dh *DeploymentHistory) formatDeploymentEntry(result *DeploymentResult) str
 
 `, timestamp, result.ContractName, result.ClassHash, result.DeployedAddress, result.TransactionHash, timestamp)
 }
+
+// LogPlan logs all contracts deployed by a plan as a single entry in the network markdown file
+func (dh *DeploymentHistory) LogPlan(result *PlanResult) error {
+	if err := os.MkdirAll(dh.exportsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create exports directory: %w", err)
+	}
+
+	path := filepath.Join(dh.exportsDir, fmt.Sprintf("%s.md", result.Network))
+	fileExists := true
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fileExists = false
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if !fileExists {
+		if _, err := file.WriteString(dh.getHeader(result.Network)); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+	}
+
+	if _, err := file.WriteString(dh.formatPlanEntry(result)); err != nil {
+		return fmt.Errorf("failed to write plan entry: %w", err)
+	}
+	return nil
+}
+
+// formatPlanEntry formats a plan result as a markdown entry with one row per contract
+func (dh *DeploymentHistory) formatPlanEntry(result *PlanResult) string {
+	timestamp := result.CompletedAt.Format("2006-01-02 15:04:05")
+	title := "Plan Deployment"
+	if result.Partial {
+		title = "Partial Plan Deployment"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s - %s - %s\n\n", title, result.Name, timestamp)
+	if result.Partial {
+		b.WriteString("A later step failed: only the contracts below were deployed.\n\n")
+	}
+	b.WriteString("| Step | Contract | Class Hash | Deployed Address | Transaction Hash |\n")
+	b.WriteString("|------|----------|------------|------------------|------------------|\n")
+	for _, step := range result.Steps {
+		fmt.Fprintf(&b, "| %s | %s | `%s` | `%s` | `%s` |\n",
+			step.ID, step.Result.ContractName, step.Result.ClassHash, step.Result.DeployedAddress, step.Result.TransactionHash)
+	}
+	fmt.Fprintf(&b, "\n- **Timestamp**: %s\n\n---\n\n", timestamp)
+	return b.String()
+}
@@ -0,0 +1,279 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+// NonceManager hands out account nonces locally so several transactions can be in flight
+// at once. Submitted transactions are persisted to a state file; after a restart the
+// chain nonce is combined with the ones still pending so no nonce is reused or skipped.
+type NonceManager struct {
+	client      Provider
+	address     *felt.Felt
+	statePath   string
+	maxInFlight int
+	logger      *logrus.Logger
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	next     *felt.Felt      // nil until synced
+	reserved map[uint64]bool // allocated but not yet submitted or released
+	inFlight map[uint64]string
+}
+
+// nonceState is the persisted form of the in-flight transactions
+type nonceState struct {
+	Account  string            `json:"account"`
+	InFlight map[uint64]string `json:"in_flight"` // nonce → transaction hash
+}
+
+// NewNonceManager creates a nonce manager for address. statePath may be empty to disable
+// restart recovery; maxInFlight <= 0 allows any number of pending transactions.
+func NewNonceManager(client Provider, address *felt.Felt, statePath string, maxInFlight int, logger *logrus.Logger) *NonceManager {
+	m := &NonceManager{
+		client:      client,
+		address:     address,
+		statePath:   statePath,
+		maxInFlight: maxInFlight,
+		logger:      logger,
+		reserved:    make(map[uint64]bool),
+		inFlight:    make(map[uint64]string),
+	}
+	m.cond = sync.NewCond(&m.mu)
+	return m
+}
+
+// Next reserves the next nonce, blocking while maxInFlight transactions are pending.
+// Every reserved nonce must be passed to either Submitted or Release.
+func (m *NonceManager) Next(ctx context.Context) (*felt.Felt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Wake the waits below when ctx is cancelled
+	stop := context.AfterFunc(ctx, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.cond.Broadcast()
+	})
+	defer stop()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if m.next == nil {
+			if err := m.recover(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if m.maxInFlight <= 0 || len(m.inFlight)+len(m.reserved) < m.maxInFlight {
+			break
+		}
+		m.cond.Wait()
+	}
+
+	nonce := m.next.Uint64()
+	m.reserved[nonce] = true
+	m.next = new(felt.Felt).SetUint64(m.following(nonce + 1))
+	return new(felt.Felt).SetUint64(nonce), nil
+}
+
+// following returns the first nonce from n that is neither reserved nor in flight.
+// Callers must hold m.mu.
+func (m *NonceManager) following(n uint64) uint64 {
+	for m.reserved[n] || m.inFlight[n] != "" {
+		n++
+	}
+	return n
+}
+
+// Submitted records that the transaction using nonce was accepted by the node
+func (m *NonceManager) Submitted(nonce, txHash *felt.Felt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.reserved, nonce.Uint64())
+	m.inFlight[nonce.Uint64()] = txHash.String()
+	return m.save()
+}
+
+// Unconfirmed records a transaction whose submission failed without a definite answer
+// (timeout, dropped connection). Its nonce stays in flight and the next allocation resyncs
+// with the chain, which drops the transaction again if the node never received it.
+func (m *NonceManager) Unconfirmed(nonce, txHash *felt.Felt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.reserved, nonce.Uint64())
+	m.inFlight[nonce.Uint64()] = txHash.String()
+	m.next = nil
+	m.cond.Broadcast()
+	return m.save()
+}
+
+// Release returns a reserved nonce whose transaction was never submitted or was rejected
+// by the node. If later nonces were handed out meanwhile, the gap is closed by resyncing
+// with the chain, which keeps the nonces still reserved or in flight.
+func (m *NonceManager) Release(nonce *felt.Felt) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.reserved, nonce.Uint64())
+	if m.next != nil && new(felt.Felt).Add(nonce, new(felt.Felt).SetUint64(1)).Equal(m.next) {
+		m.next = nonce
+	} else {
+		m.next = nil
+	}
+	m.cond.Broadcast()
+}
+
+// Confirmed removes a transaction that was included (accepted or reverted) from the in-flight set
+func (m *NonceManager) Confirmed(txHash *felt.Felt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash := txHash.String()
+	for nonce, h := range m.inFlight {
+		if h == hash {
+			delete(m.inFlight, nonce)
+			m.cond.Broadcast()
+			return m.save()
+		}
+	}
+	return nil
+}
+
+// Resync drops the local nonce so the next allocation is recomputed from the chain.
+// Call it when the node rejects a transaction nonce.
+func (m *NonceManager) Resync() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.next = nil
+	m.cond.Broadcast()
+}
+
+// recover computes the next nonce from the chain nonce, the persisted in-flight
+// transactions still known to the node and the reserved nonces. Callers must hold m.mu.
+func (m *NonceManager) recover(ctx context.Context) error {
+	if err := m.load(); err != nil {
+		return err
+	}
+
+	chainNonce, err := m.client.Nonce(ctx, rpc.WithBlockTag(rpc.BlockTagPre_confirmed), m.address)
+	if err != nil {
+		return fmt.Errorf("failed to fetch nonce: %w", err)
+	}
+
+	nonces := make([]uint64, 0, len(m.inFlight))
+	for nonce := range m.inFlight {
+		nonces = append(nonces, nonce)
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+
+	// Transactions still in the mempool keep their nonces, even above a gap that a later
+	// transaction fills; the ones the node no longer knows are dropped
+	next := chainNonce.Uint64()
+	for _, nonce := range nonces {
+		if nonce < chainNonce.Uint64() {
+			delete(m.inFlight, nonce)
+			continue
+		}
+		status, err := m.status(ctx, m.inFlight[nonce])
+		if err != nil {
+			return fmt.Errorf("failed to check in-flight transaction %s (nonce %d): %w", m.inFlight[nonce], nonce, err)
+		}
+		switch status {
+		case rpc.TxnStatus_Received, rpc.TxnStatus_Candidate, rpc.TxnStatus_Pre_confirmed:
+		case rpc.TxnStatus_Accepted_On_L2, rpc.TxnStatus_Accepted_On_L1:
+			// Included since the chain nonce was read
+			delete(m.inFlight, nonce)
+			next = max(next, nonce+1)
+		default:
+			m.logger.Warnf("⚠️  Dropping in-flight transaction %s (nonce %d) that the node no longer knows", m.inFlight[nonce], nonce)
+			delete(m.inFlight, nonce)
+		}
+	}
+	next = m.following(next)
+
+	m.next = new(felt.Felt).SetUint64(next)
+	m.logger.Debugf("🔢 Nonce synced: chain %d, next %d, %d in flight, %d reserved", chainNonce.Uint64(), next, len(m.inFlight), len(m.reserved))
+	m.cond.Broadcast()
+	return m.save()
+}
+
+// status returns the finality status of a transaction that was not yet included, or an
+// empty status when the node does not know it
+func (m *NonceManager) status(ctx context.Context, txHash string) (rpc.TxnStatus, error) {
+	hash, err := new(felt.Felt).SetString(txHash)
+	if err != nil {
+		return "", nil
+	}
+	status, err := m.client.GetTransactionStatus(ctx, hash)
+	var rpcErr *rpc.RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == rpc.ErrHashNotFound.Code {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return status.FinalityStatus, nil
+}
+
+func (m *NonceManager) load() error {
+	if m.statePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(m.statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read nonce state %s: %w", m.statePath, err)
+	}
+
+	var state nonceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse nonce state %s: %w", m.statePath, err)
+	}
+	if state.Account != m.address.String() {
+		return fmt.Errorf("nonce state %s belongs to account %s", m.statePath, state.Account)
+	}
+	for nonce, hash := range state.InFlight {
+		m.inFlight[nonce] = hash
+	}
+	return nil
+}
+
+func (m *NonceManager) save() error {
+	if m.statePath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(nonceState{Account: m.address.String(), InFlight: m.inFlight}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode nonce state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.statePath), 0755); err != nil {
+		return fmt.Errorf("failed to create nonce state directory: %w", err)
+	}
+
+	// Write atomically so a crash never leaves a truncated state file
+	tmp := m.statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write nonce state: %w", err)
+	}
+	return os.Rename(tmp, m.statePath)
+}
@@ -0,0 +1,249 @@
+package deploy_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy/deploytest"
+)
+
+var ping = []rpc.InvokeFunctionCall{{ContractAddress: new(felt.Felt).SetUint64(0x99), FunctionName: "ping"}}
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// writeNonceState writes a state file as left behind by a previous run
+func writeNonceState(t *testing.T, account string, inFlight map[uint64]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "nonces.json")
+	data, err := json.Marshal(map[string]any{"account": account, "in_flight": inFlight})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNonceManagerRestoresInFlight(t *testing.T) {
+	provider := deploytest.NewProvider()
+	account, _ := utils.HexToFelt(accountAddress)
+
+	// A previous run left nonce 0 in the mempool; nonce 1 never reached the node
+	provider.Script(deploytest.Outcome{Pending: 100})
+	pending, err := newDeployer(t, provider).Invoke(t.Context(), ping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.SetNonce(account, 0)
+	path := writeNonceState(t, account.String(), map[uint64]string{0: pending.String(), 1: "0xdead"})
+
+	m := deploy.NewNonceManager(provider, account, path, 0, quietLogger())
+	nonce, err := m.Next(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce.Uint64() != 1 {
+		t.Errorf("next nonce = %d, want 1 after the pending transaction", nonce.Uint64())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state struct {
+		InFlight map[uint64]string `json:"in_flight"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.InFlight) != 1 || state.InFlight[0] != pending.String() {
+		t.Errorf("saved in-flight = %v, want only the pending transaction", state.InFlight)
+	}
+}
+
+func TestNonceManagerKeepsPendingAboveGap(t *testing.T) {
+	provider := deploytest.NewProvider()
+	account, _ := utils.HexToFelt(accountAddress)
+
+	// Nonce 0 was released by the previous run, nonce 1 is still in the mempool
+	provider.Script(deploytest.Outcome{Pending: 100})
+	pending, err := newDeployer(t, provider).Invoke(t.Context(), ping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.SetNonce(account, 0)
+	path := writeNonceState(t, account.String(), map[uint64]string{1: pending.String()})
+
+	// A failed status check is not taken as a dropped transaction
+	provider.FailNext("GetTransactionStatus", errors.New("connection reset"))
+	m := deploy.NewNonceManager(provider, account, path, 0, quietLogger())
+	if _, err := m.Next(t.Context()); err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Fatalf("err = %v, want the failed status check", err)
+	}
+
+	var got []uint64
+	for range 2 {
+		nonce, err := m.Next(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, nonce.Uint64())
+	}
+	if !slices.Equal(got, []uint64{0, 2}) {
+		t.Errorf("nonces = %v, want the gap filled and the pending nonce skipped", got)
+	}
+}
+
+func TestNonceManagerReleaseKeepsReservedNonces(t *testing.T) {
+	provider := deploytest.NewProvider()
+	account, _ := utils.HexToFelt(accountAddress)
+	m := deploy.NewNonceManager(provider, account, "", 0, quietLogger())
+
+	// Two transactions reserve nonces at the same time
+	var wg sync.WaitGroup
+	reserved := make([]*felt.Felt, 2)
+	for i := range reserved {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := m.Next(t.Context())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			reserved[i] = nonce
+		}()
+	}
+	wg.Wait()
+	if t.Failed() {
+		t.FailNow()
+	}
+	lower, higher := reserved[0], reserved[1]
+	if lower.Uint64() > higher.Uint64() {
+		lower, higher = higher, lower
+	}
+	if lower.Uint64() != 0 || higher.Uint64() != 1 {
+		t.Fatalf("reserved = %s and %s, want 0 and 1", lower, higher)
+	}
+
+	// The lower one is released while the higher one is still being submitted
+	m.Release(lower)
+	var got []uint64
+	for range 2 {
+		nonce, err := m.Next(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, nonce.Uint64())
+	}
+	if !slices.Equal(got, []uint64{0, 2}) {
+		t.Errorf("nonces = %v, want the released nonce reused and the reserved one skipped", got)
+	}
+}
+
+func TestNonceManagerRejectsForeignState(t *testing.T) {
+	provider := deploytest.NewProvider()
+	account, _ := utils.HexToFelt(accountAddress)
+	path := writeNonceState(t, "0x999", map[uint64]string{0: "0x1"})
+
+	m := deploy.NewNonceManager(provider, account, path, 0, quietLogger())
+	if _, err := m.Next(t.Context()); err == nil || !strings.Contains(err.Error(), "belongs to account 0x999") {
+		t.Errorf("err = %v, want the state rejected", err)
+	}
+}
+
+func TestNonceSettlement(t *testing.T) {
+	account, _ := utils.HexToFelt(accountAddress)
+
+	tests := []struct {
+		name  string
+		setup func(provider *deploytest.Provider)
+		// wantNonce is the nonce used by the transaction after the failed one
+		wantNonce uint64
+		// wantResync tells whether the chain nonce is read again
+		wantResync bool
+	}{
+		{
+			name: "rejected by the node",
+			setup: func(provider *deploytest.Provider) {
+				provider.FailNext("AddInvokeTransaction", rpc.ErrFeeBelowMinimum)
+			},
+			wantNonce: 1,
+		},
+		{
+			name: "invalid nonce",
+			setup: func(provider *deploytest.Provider) {
+				// Another client used the account meanwhile
+				provider.SetNonce(account, 7)
+				provider.FailNext("AddInvokeTransaction", rpc.ErrInvalidTransactionNonce)
+			},
+			wantNonce:  7,
+			wantResync: true,
+		},
+		{
+			name: "ambiguous and received",
+			setup: func(provider *deploytest.Provider) {
+				// The node took the transaction, but neither the response nor the status
+				// check made it back
+				provider.Script(deploytest.Outcome{LostResponse: errors.New("context deadline exceeded")})
+				provider.FailNext("GetTransactionStatus", errors.New("connection reset"))
+			},
+			wantNonce:  2,
+			wantResync: true,
+		},
+		{
+			name: "ambiguous and lost",
+			setup: func(provider *deploytest.Provider) {
+				provider.FailNext("AddInvokeTransaction", errors.New("context deadline exceeded"))
+			},
+			wantNonce:  1,
+			wantResync: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := deploytest.NewProvider()
+			d := newDeployer(t, provider, deploy.WithNonceTracking("", 0))
+
+			if _, err := d.Invoke(t.Context(), ping); err != nil {
+				t.Fatal(err)
+			}
+			syncs := provider.Requests("Nonce")
+
+			tt.setup(provider)
+			if _, err := d.Invoke(t.Context(), ping); err == nil {
+				t.Fatal("failed submission reported as submitted")
+			}
+			if _, err := d.Invoke(t.Context(), ping); err != nil {
+				t.Fatal(err)
+			}
+
+			invokes := provider.Invokes()
+			if got := invokes[len(invokes)-1].Nonce.Uint64(); got != tt.wantNonce {
+				t.Errorf("next nonce = %d, want %d", got, tt.wantNonce)
+			}
+			if resynced := provider.Requests("Nonce") > syncs; resynced != tt.wantResync {
+				t.Errorf("resynced = %t, want %t", resynced, tt.wantResync)
+			}
+		})
+	}
+}
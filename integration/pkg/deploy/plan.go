@@ -0,0 +1,287 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Plan describes several contracts deployed in dependency order. Config values may
+// reference outputs of other steps as ${<id>.address}, ${<id>.class_hash} or
+// ${<id>.transaction_hash}, and the deployer account as ${deployer}.
+type Plan struct {
+	Name      string     `json:"name"`
+	Contracts []PlanStep `json:"contracts"`
+}
+
+// PlanStep is one contract deployment in a plan
+type PlanStep struct {
+	// ID names the step so other steps can reference its outputs
+	ID string `json:"id"`
+	// Contract is the contract type, such as "ethrx" or "mock_erc20"
+	Contract string `json:"contract"`
+	// Config overrides the contract configuration, keyed by its JSON field names
+	Config map[string]string `json:"config,omitempty"`
+	// DependsOn lists steps that must be deployed first in addition to referenced ones
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// StepFactory creates the deployer for a plan step from its resolved configuration
+type StepFactory func(step PlanStep, config map[string]string) (ContractDeployer, error)
+
+// PlanStepResult is the outcome of one deployed plan step
+type PlanStepResult struct {
+	ID       string            `json:"id"`
+	Contract string            `json:"contract"`
+	Result   *DeploymentResult `json:"result"`
+}
+
+// PlanResult combines the results of all deployed plan steps
+type PlanResult struct {
+	Name    string           `json:"name"`
+	Network string           `json:"network"`
+	Steps   []PlanStepResult `json:"steps"`
+	// Partial is set when a step failed; Steps then holds the steps deployed before it
+	Partial     bool      `json:"partial,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// referencePattern matches ${deployer} and ${<id>.<output>} references
+var referencePattern = regexp.MustCompile(`\$\{([A-Za-z0-9_-]+)(?:\.([a-z_]+))?\}`)
+
+// LoadPlan reads and validates a plan file
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan %s: %w", path, err)
+	}
+
+	var plan Plan
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %w", path, err)
+	}
+	if plan.Name == "" {
+		plan.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	if _, err := plan.Order(); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Order returns the steps sorted so every step comes after the steps it depends on.
+// Steps without dependencies between them keep their order from the plan file.
+func (p *Plan) Order() ([]PlanStep, error) {
+	if len(p.Contracts) == 0 {
+		return nil, fmt.Errorf("plan %s has no contracts", p.Name)
+	}
+
+	index := make(map[string]int, len(p.Contracts))
+	for i, step := range p.Contracts {
+		if step.ID == "" {
+			return nil, fmt.Errorf("plan step %d has no id", i+1)
+		}
+		if step.ID == "deployer" {
+			return nil, fmt.Errorf("plan step id %q is reserved", step.ID)
+		}
+		if step.Contract == "" {
+			return nil, fmt.Errorf("plan step %s has no contract type", step.ID)
+		}
+		if _, ok := index[step.ID]; ok {
+			return nil, fmt.Errorf("duplicate plan step id %q", step.ID)
+		}
+		index[step.ID] = i
+	}
+
+	deps := make([][]int, len(p.Contracts))
+	for i, step := range p.Contracts {
+		ids, err := step.dependencies()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			j, ok := index[id]
+			if !ok {
+				return nil, fmt.Errorf("plan step %s references unknown step %q", step.ID, id)
+			}
+			if j == i {
+				return nil, fmt.Errorf("plan step %s references itself", step.ID)
+			}
+			deps[i] = append(deps[i], j)
+		}
+	}
+
+	// Depth-first topological sort, visiting steps in file order
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(p.Contracts))
+	ordered := make([]PlanStep, 0, len(p.Contracts))
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("plan has a dependency cycle: %s", strings.Join(append(path, p.Contracts[i].ID), " → "))
+		}
+		state[i] = visiting
+		for _, j := range deps[i] {
+			if err := visit(j, append(path, p.Contracts[i].ID)); err != nil {
+				return err
+			}
+		}
+		state[i] = done
+		ordered = append(ordered, p.Contracts[i])
+		return nil
+	}
+	for i := range p.Contracts {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// dependencies returns the step ids referenced by the config and listed in DependsOn
+func (s PlanStep) dependencies() ([]string, error) {
+	seen := make(map[string]bool)
+	var ids []string
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range s.DependsOn {
+		add(id)
+	}
+
+	// Sort keys so errors and dependency order do not depend on map iteration
+	keys := make([]string, 0, len(s.Config))
+	for key := range s.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, match := range referencePattern.FindAllStringSubmatch(s.Config[key], -1) {
+			id, output := match[1], match[2]
+			if id == "deployer" && output == "" {
+				continue
+			}
+			if _, ok := planOutput(&DeploymentResult{}, output); !ok {
+				return nil, fmt.Errorf("plan step %s: invalid reference %s in %s", s.ID, match[0], key)
+			}
+			add(id)
+		}
+	}
+	return ids, nil
+}
+
+// ExecutePlan deploys the plan steps in dependency order, resolving references from the
+// outputs of earlier steps. If a step fails, the result is marked partial and holds the
+// steps deployed so far.
+func (d *Deployer) ExecutePlan(ctx context.Context, plan *Plan, factory StepFactory) (*PlanResult, error) {
+	steps, err := plan.Order()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PlanResult{
+		Name:      plan.Name,
+		Network:   d.network,
+		StartedAt: time.Now(),
+	}
+	outputs := make(map[string]*DeploymentResult, len(steps))
+	fail := func(err error) (*PlanResult, error) {
+		result.Partial = true
+		result.CompletedAt = time.Now()
+		return result, err
+	}
+
+	for i, step := range steps {
+		d.logger.WithFields(logrus.Fields{"plan_step": step.ID, "contract": step.Contract}).Infof("📦 Plan step %d/%d: %s (%s)", i+1, len(steps), step.ID, step.Contract)
+
+		values, err := d.resolveConfig(step, outputs)
+		if err != nil {
+			return fail(err)
+		}
+
+		contract, err := factory(step, values)
+		if err != nil {
+			return fail(fmt.Errorf("plan step %s: %w", step.ID, err))
+		}
+		if err := contract.ValidateConfig(); err != nil {
+			return fail(fmt.Errorf("plan step %s: invalid configuration: %w", step.ID, err))
+		}
+
+		deployed, err := contract.Deploy(ctx)
+		if err != nil {
+			return fail(fmt.Errorf("plan step %s: %w", step.ID, err))
+		}
+
+		outputs[step.ID] = deployed
+		result.Steps = append(result.Steps, PlanStepResult{ID: step.ID, Contract: step.Contract, Result: deployed})
+	}
+
+	result.CompletedAt = time.Now()
+	return result, nil
+}
+
+// resolveConfig substitutes references in the step configuration
+func (d *Deployer) resolveConfig(step PlanStep, outputs map[string]*DeploymentResult) (map[string]string, error) {
+	values := make(map[string]string, len(step.Config))
+	for key, value := range step.Config {
+		var resolveErr error
+		values[key] = referencePattern.ReplaceAllStringFunc(value, func(ref string) string {
+			match := referencePattern.FindStringSubmatch(ref)
+			id, output := match[1], match[2]
+			if id == "deployer" && output == "" {
+				return d.GetAccountAddress()
+			}
+
+			deployed, ok := outputs[id]
+			if !ok {
+				resolveErr = fmt.Errorf("plan step %s: %s is not deployed yet", step.ID, id)
+				return ref
+			}
+			resolved, _ := planOutput(deployed, output)
+			return resolved
+		})
+		if resolveErr != nil {
+			return nil, resolveErr
+		}
+	}
+	return values, nil
+}
+
+// planOutput returns the named output of a deployed step
+func planOutput(result *DeploymentResult, output string) (string, bool) {
+	switch output {
+	case "address":
+		return result.DeployedAddress, true
+	case "class_hash":
+		return result.ClassHash, true
+	case "transaction_hash":
+		return result.TransactionHash, true
+	}
+	return "", false
+}
@@ -0,0 +1,229 @@
+package deploy_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy/deploytest"
+)
+
+func TestPlanOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []deploy.PlanStep
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "independent steps keep file order",
+			steps: []deploy.PlanStep{
+				{ID: "a", Contract: "mock_erc20"},
+				{ID: "b", Contract: "mock_erc20"},
+			},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "referenced address comes first",
+			steps: []deploy.PlanStep{
+				{ID: "ethrx", Contract: "ethrx", Config: map[string]string{"mint_token": "${token.address}"}},
+				{ID: "token", Contract: "mock_erc20"},
+			},
+			want: []string{"token", "ethrx"},
+		},
+		{
+			name: "references inside a value, class hash and deployer",
+			steps: []deploy.PlanStep{
+				{ID: "c", Contract: "ethrx", Config: map[string]string{
+					"owner":      "${deployer}",
+					"mint_token": "${b-2.address}",
+					"note":       "class ${a_1.class_hash} from ${a_1.transaction_hash}",
+				}},
+				{ID: "b-2", Contract: "mock_erc20"},
+				{ID: "a_1", Contract: "mock_erc20"},
+			},
+			// References are followed in config key order: mint_token before note
+			want: []string{"b-2", "a_1", "c"},
+		},
+		{
+			name: "depends_on without a reference",
+			steps: []deploy.PlanStep{
+				{ID: "a", Contract: "ethrx", DependsOn: []string{"c"}},
+				{ID: "b", Contract: "mock_erc20"},
+				{ID: "c", Contract: "mock_erc20", Config: map[string]string{"owner": "${b.address}"}},
+			},
+			want: []string{"b", "c", "a"},
+		},
+		{
+			name: "cycle",
+			steps: []deploy.PlanStep{
+				{ID: "a", Contract: "ethrx", Config: map[string]string{"mint_token": "${b.address}"}},
+				{ID: "b", Contract: "mock_erc20", DependsOn: []string{"c"}},
+				{ID: "c", Contract: "mock_erc20", Config: map[string]string{"owner": "${a.address}"}},
+			},
+			wantErr: "dependency cycle: a → b → c → a",
+		},
+		{
+			name: "self reference",
+			steps: []deploy.PlanStep{
+				{ID: "a", Contract: "ethrx", Config: map[string]string{"owner": "${a.address}"}},
+			},
+			wantErr: "references itself",
+		},
+		{
+			name: "unknown step",
+			steps: []deploy.PlanStep{
+				{ID: "ethrx", Contract: "ethrx", Config: map[string]string{"mint_token": "${tokn.address}"}},
+				{ID: "token", Contract: "mock_erc20"},
+			},
+			wantErr: `references unknown step "tokn"`,
+		},
+		{
+			name: "unknown output",
+			steps: []deploy.PlanStep{
+				{ID: "ethrx", Contract: "ethrx", Config: map[string]string{"mint_token": "${token.addr}"}},
+				{ID: "token", Contract: "mock_erc20"},
+			},
+			wantErr: "invalid reference ${token.addr} in mint_token",
+		},
+		{
+			name: "step without output",
+			steps: []deploy.PlanStep{
+				{ID: "ethrx", Contract: "ethrx", Config: map[string]string{"mint_token": "${token}"}},
+				{ID: "token", Contract: "mock_erc20"},
+			},
+			wantErr: "invalid reference ${token}",
+		},
+		{
+			name:    "no steps",
+			wantErr: "has no contracts",
+		},
+		{
+			name: "duplicate id",
+			steps: []deploy.PlanStep{
+				{ID: "a", Contract: "ethrx"},
+				{ID: "a", Contract: "mock_erc20"},
+			},
+			wantErr: `duplicate plan step id "a"`,
+		},
+		{
+			name:    "reserved id",
+			steps:   []deploy.PlanStep{{ID: "deployer", Contract: "ethrx"}},
+			wantErr: "is reserved",
+		},
+		{
+			name:    "missing contract",
+			steps:   []deploy.PlanStep{{ID: "a"}},
+			wantErr: "has no contract type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &deploy.Plan{Name: "test", Contracts: tt.steps}
+			ordered, err := plan.Order()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []string
+			for _, step := range ordered {
+				ids = append(ids, step.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("order = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPlan(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	plan, err := deploy.LoadPlan(write("sepolia-launch.json", `{"contracts": [{"id": "token", "contract": "mock_erc20"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Name != "sepolia-launch" {
+		t.Errorf("name = %q, want the file name", plan.Name)
+	}
+
+	if _, err := deploy.LoadPlan(write("typo.json", `{"contracts": [{"id": "token", "contract": "mock_erc20", "dependson": []}]}`)); err == nil {
+		t.Error("unknown field accepted")
+	}
+	if _, err := deploy.LoadPlan(write("cycle.json", `{"contracts": [{"id": "a", "contract": "ethrx", "depends_on": ["a"]}]}`)); err == nil {
+		t.Error("invalid plan accepted")
+	}
+}
+
+// planContract is a plan step deployer that returns a fixed address or fails
+type planContract struct {
+	address string
+	err     error
+}
+
+func (c planContract) Deploy(ctx context.Context) (*deploy.DeploymentResult, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &deploy.DeploymentResult{ContractName: "Mock", DeployedAddress: c.address, Network: "testnet"}, nil
+}
+
+func (c planContract) GetContractName() string { return "Mock" }
+
+func (c planContract) ValidateConfig() error { return nil }
+
+func TestExecutePlanKeepsDeployedSteps(t *testing.T) {
+	d := newDeployer(t, deploytest.NewProvider())
+	plan := &deploy.Plan{Name: "launch", Contracts: []deploy.PlanStep{
+		{ID: "token", Contract: "mock_erc20"},
+		{ID: "nft", Contract: "ethrx", Config: map[string]string{"mint_token": "${token.address}"}},
+	}}
+	factory := func(step deploy.PlanStep, config map[string]string) (deploy.ContractDeployer, error) {
+		if step.ID == "nft" {
+			return planContract{err: errors.New("out of fee")}, nil
+		}
+		return planContract{address: "0x70c3"}, nil
+	}
+
+	result, err := d.ExecutePlan(context.Background(), plan, factory)
+	if err == nil || !strings.Contains(err.Error(), "plan step nft: out of fee") {
+		t.Fatalf("err = %v, want the nft step failure", err)
+	}
+	if !result.Partial || len(result.Steps) != 1 || result.Steps[0].Result.DeployedAddress != "0x70c3" {
+		t.Fatalf("result = %+v, want a partial result with the token step", result)
+	}
+
+	// The deployed steps are recorded in the history, marked as partial
+	t.Chdir(t.TempDir())
+	if err := deploy.NewDeploymentHistory().LogPlan(result); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join("exports", "testnet.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## Partial Plan Deployment - launch", "| token | Mock | `` | `0x70c3` |"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("history does not contain %q:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package deploy
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultStepEstimate is the expected duration of a declare or deploy transaction until the
+// deployer has timed one
+const defaultStepEstimate = 30 * time.Second
+
+// deploymentSteps are the transaction steps of DeployContract
+const deploymentSteps = 2
+
+// ProgressFunc receives the progress of DeployContract. It is called synchronously from the
+// deploying goroutine and should return quickly.
+type ProgressFunc func(DeploymentProgress)
+
+// WithProgress reports the progress of every DeployContract call to fn
+func WithProgress(fn ProgressFunc) Option {
+	return func(d *Deployer) { d.progress = fn }
+}
+
+// ProgressChannel returns a ProgressFunc that forwards the updates of one deployment to a
+// buffered channel, for subscribers running in their own goroutine. Intermediate updates are
+// dropped while the channel is full, so a slow subscriber never stalls a deployment. The final
+// completed or failed update is always delivered, replacing the oldest buffered update if
+// needed, and the channel is closed after it; later updates are ignored.
+func ProgressChannel(size int) (ProgressFunc, <-chan DeploymentProgress) {
+	// The final update needs a buffered slot to be delivered without a waiting subscriber
+	ch := make(chan DeploymentProgress, max(size, 1))
+	var (
+		mu     sync.Mutex
+		closed bool
+	)
+	return func(p DeploymentProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		if p.Status != StatusCompleted && p.Status != StatusFailed {
+			select {
+			case ch <- p:
+			default:
+			}
+			return
+		}
+		for {
+			select {
+			case ch <- p:
+				closed = true
+				close(ch)
+				return
+			default:
+				// Only this function sends, so a slot is free after one receive
+				select {
+				case <-ch:
+				default:
+				}
+			}
+		}
+	}, ch
+}
+
+// stepTimes keeps the durations of completed transaction steps to estimate the next ones
+type stepTimes struct {
+	mu    sync.Mutex
+	total time.Duration
+	count int
+}
+
+// add records the duration of a completed step
+func (s *stepTimes) add(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.total += d
+	s.count++
+}
+
+// average returns the mean step duration, or defaultStepEstimate before any step completed
+func (s *stepTimes) average() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count == 0 {
+		return defaultStepEstimate
+	}
+	return s.total / time.Duration(s.count)
+}
+
+// progressReporter emits the progress of a single DeployContract call
+type progressReporter struct {
+	d         *Deployer
+	contract  string
+	started   time.Time
+	stepStart time.Time
+	running   bool
+	txHash    string
+	last      DeploymentProgress
+}
+
+func (d *Deployer) newProgressReporter(contract string) *progressReporter {
+	now := time.Now()
+	return &progressReporter{d: d, contract: contract, started: now, stepStart: now}
+}
+
+// report emits an update. done is the number of completed steps; the deploy delay is
+// included in the estimate until the deploy step starts.
+func (r *progressReporter) report(status DeploymentStatus, step string, done int, message string) {
+	if r.d.progress == nil {
+		return
+	}
+
+	var remaining time.Duration
+	switch status {
+	case StatusCompleted, StatusFailed:
+	default:
+		remaining = time.Duration(deploymentSteps-done) * r.d.steps.average()
+		if status != StatusDeploying {
+			remaining += r.d.deployDelay
+		}
+		// The running step is already partly done
+		if r.running {
+			remaining -= min(time.Since(r.stepStart), r.d.steps.average())
+		}
+	}
+
+	r.last = DeploymentProgress{
+		Contract:        r.contract,
+		Status:          status,
+		Message:         message,
+		Progress:        float64(done) / deploymentSteps,
+		CurrentStep:     step,
+		TotalSteps:      deploymentSteps,
+		ElapsedTime:     time.Since(r.started),
+		EstimatedTime:   max(remaining, 0),
+		TransactionHash: r.txHash,
+	}
+	r.d.progress(r.last)
+}
+
+// startStep marks the beginning of a transaction step
+func (r *progressReporter) startStep(status DeploymentStatus, step string, done int, message string) {
+	r.stepStart = time.Now()
+	r.running = true
+	r.report(status, step, done, message)
+}
+
+// finishStep ends the running step; timed steps are kept for later estimates
+func (r *progressReporter) finishStep(timed bool) {
+	r.running = false
+	if timed {
+		r.d.steps.add(time.Since(r.stepStart))
+	}
+}
+
+// completed reports the deployed contract
+func (r *progressReporter) completed(txHash, message string) {
+	r.txHash = txHash
+	r.report(StatusCompleted, "deploy", deploymentSteps, message)
+}
+
+// failed reports err at the step it happened in and returns it
+func (r *progressReporter) failed(err error) error {
+	if r.d.progress != nil {
+		done := int(r.last.Progress * deploymentSteps)
+		r.report(StatusFailed, r.last.CurrentStep, done, err.Error())
+	}
+	return err
+}
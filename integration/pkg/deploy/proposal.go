@@ -0,0 +1,383 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/curve"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/signer"
+)
+
+// ProposalVersion is the version of the multisig proposal file format
+const ProposalVersion = 1
+
+// MultisigLayout is how an Argent multisig identifies its signers and expects their
+// signatures to be serialized
+type MultisigLayout string
+
+const (
+	// LayoutSignerTriples is Argent multisig 0.1: get_signers returns the signer public
+	// keys and the signature is a flat list of (pubkey, r, s) triples sorted by public key
+	LayoutSignerTriples MultisigLayout = "signer_triples"
+
+	// LayoutSignerSignatures is Argent multisig 0.2 and later: get_signer_guids returns
+	// signer GUIDs and the signature is a serialized Array<SignerSignature> of Starknet
+	// signers, [len, 0, pubkey, r, s, ...], sorted by GUID
+	LayoutSignerSignatures MultisigLayout = "signer_signatures"
+)
+
+// starknetSignerVariant is the index of SignerSignature::Starknet
+const starknetSignerVariant = 0
+
+// starknetSignerType is the 'Starknet Signer' short string hashed into signer GUIDs
+var starknetSignerType = new(felt.Felt).SetBytes([]byte("Starknet Signer"))
+
+// Proposal is a transaction sent from a multisig account (e.g. the Ethrx owner) that
+// collects signatures from its signers until the threshold is met.
+//
+// Signers lists the multisig's signers as it reports them: public keys in the
+// LayoutSignerTriples layout, GUIDs in LayoutSignerSignatures. Proposals without a
+// layout predate GUIDs and use LayoutSignerTriples.
+type Proposal struct {
+	Version   int            `json:"version"`
+	Multisig  string         `json:"multisig"`
+	Threshold uint64         `json:"threshold"`
+	Layout    MultisigLayout `json:"layout,omitempty"`
+	Signers   []string       `json:"signers"`
+
+	Transaction TransactionBundle   `json:"transaction"`
+	Signatures  []ProposalSignature `json:"signatures"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProposalSignature is one signer's approval of a proposal. Signer is the public key.
+type ProposalSignature struct {
+	Signer   string    `json:"signer"`
+	R        string    `json:"r"`
+	S        string    `json:"s"`
+	SignedAt time.Time `json:"signed_at"`
+}
+
+// BuildProposal prepares a proposal for calls sent from the multisig account.
+// The threshold, signer set and signature layout are read from the multisig contract.
+func (d *Deployer) BuildProposal(ctx context.Context, multisig *felt.Felt, description string, calls []rpc.InvokeFunctionCall) (*Proposal, error) {
+	config, err := d.multisigConfig(ctx, multisig)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle, err := d.buildInvokeBundle(ctx, multisig, description, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Proposal{
+		Version:     ProposalVersion,
+		Multisig:    multisig.String(),
+		Threshold:   config.threshold,
+		Layout:      config.layout,
+		Signers:     config.signers,
+		Transaction: *bundle,
+		Signatures:  []ProposalSignature{},
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// Verify checks the transaction and every collected signature
+func (p *Proposal) Verify() error {
+	if p.Version != ProposalVersion {
+		return fmt.Errorf("unsupported proposal version: %d", p.Version)
+	}
+	if p.Transaction.Type != signer.TxTypeInvoke {
+		return fmt.Errorf("proposals must be invoke transactions, got %s", p.Transaction.Type)
+	}
+	if p.Transaction.Account != p.Multisig {
+		return fmt.Errorf("proposal transaction is sent from %s, not the multisig %s", p.Transaction.Account, p.Multisig)
+	}
+	if p.Layout != "" && p.Layout != LayoutSignerTriples && p.Layout != LayoutSignerSignatures {
+		return fmt.Errorf("unsupported multisig layout: %s", p.Layout)
+	}
+	if err := p.Transaction.Verify(); err != nil {
+		return err
+	}
+
+	txHash, err := utils.HexToFelt(p.Transaction.TransactionHash)
+	if err != nil {
+		return fmt.Errorf("invalid transaction hash: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, sig := range p.Signatures {
+		if !p.isSigner(sig.Signer) {
+			return fmt.Errorf("signature from %s, which is not a multisig signer", sig.Signer)
+		}
+		if seen[sig.Signer] {
+			return fmt.Errorf("duplicate signature from %s", sig.Signer)
+		}
+		seen[sig.Signer] = true
+
+		signature, err := utils.HexArrToFelt([]string{sig.R, sig.S})
+		if err != nil {
+			return fmt.Errorf("invalid signature from %s: %w", sig.Signer, err)
+		}
+		if err := signer.Verify(sig.Signer, txHash, signature); err != nil {
+			return fmt.Errorf("signature from %s: %w", sig.Signer, err)
+		}
+	}
+
+	return nil
+}
+
+// Sign adds the approval of s to the proposal. It needs no network access.
+func (p *Proposal) Sign(ctx context.Context, s signer.Signer) error {
+	if err := p.Verify(); err != nil {
+		return fmt.Errorf("refusing to sign proposal: %w", err)
+	}
+
+	publicKey, err := normalizeKey(s.PublicKey())
+	if err != nil {
+		return fmt.Errorf("invalid signer public key: %w", err)
+	}
+	if !p.isSigner(publicKey) {
+		return fmt.Errorf("%s is not a signer of multisig %s", publicKey, p.Multisig)
+	}
+	if p.HasSigned(publicKey) {
+		return fmt.Errorf("%s has already signed this proposal", publicKey)
+	}
+
+	signature, err := s.SignTransaction(ctx, &signer.SignRequest{
+		Network:         p.Transaction.Network,
+		ChainID:         utils.HexToShortStr(p.Transaction.ChainID),
+		Account:         p.Multisig,
+		Type:            signer.TxTypeInvoke,
+		TransactionHash: p.Transaction.TransactionHash,
+		Nonce:           p.Transaction.Nonce().String(),
+		Calls:           p.Transaction.Calls,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign proposal: %w", err)
+	}
+	if len(signature) != 2 {
+		return fmt.Errorf("multisig signers must return a single (r, s) signature, got %d elements", len(signature))
+	}
+
+	p.Signatures = append(p.Signatures, ProposalSignature{
+		Signer:   publicKey,
+		R:        signature[0].String(),
+		S:        signature[1].String(),
+		SignedAt: time.Now(),
+	})
+
+	return nil
+}
+
+// HasSigned returns whether a signer, given by public key or as listed in Signers,
+// already approved the proposal
+func (p *Proposal) HasSigned(signer string) bool {
+	return slices.ContainsFunc(p.Signatures, func(sig ProposalSignature) bool {
+		return sig.Signer == signer || p.signerID(sig.Signer) == signer
+	})
+}
+
+// ThresholdMet returns whether enough signatures were collected to submit
+func (p *Proposal) ThresholdMet() bool {
+	return uint64(len(p.Signatures)) >= p.Threshold
+}
+
+// SubmitProposal assembles the multisig signature and submits the transaction once
+// the threshold is met. The on-chain signer set is re-read so a stale proposal fails early.
+func (d *Deployer) SubmitProposal(ctx context.Context, p *Proposal) (*felt.Felt, error) {
+	if err := p.Verify(); err != nil {
+		return nil, fmt.Errorf("invalid proposal: %w", err)
+	}
+	if p.Transaction.ChainID != d.chainID.String() {
+		return nil, fmt.Errorf("proposal was built for chain %s but RPC serves %s",
+			utils.HexToShortStr(p.Transaction.ChainID), d.chainName())
+	}
+
+	multisig, err := utils.HexToFelt(p.Multisig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid multisig address: %w", err)
+	}
+	config, err := d.multisigConfig(ctx, multisig)
+	if err != nil {
+		return nil, err
+	}
+	if config.layout != p.layout() {
+		return nil, fmt.Errorf("proposal uses the %s multisig layout but the multisig now uses %s, rebuild it", p.layout(), config.layout)
+	}
+
+	var signatures []ProposalSignature
+	for _, sig := range p.Signatures {
+		if slices.Contains(config.signers, p.signerID(sig.Signer)) {
+			signatures = append(signatures, sig)
+		}
+	}
+	if uint64(len(signatures)) < config.threshold {
+		return nil, fmt.Errorf("threshold not met: %d of %d required signatures", len(signatures), config.threshold)
+	}
+
+	bundle := p.Transaction
+	invokeTxn := *bundle.Invoke
+	invokeTxn.Signature = p.multisigSignature(signatures[:config.threshold:config.threshold])
+	bundle.Invoke = &invokeTxn
+
+	return d.submitBundle(ctx, &bundle)
+}
+
+// multisigSignature serializes the signatures in the proposal's layout. The multisig
+// expects exactly threshold signatures, ordered by the signer as it lists them.
+func (p *Proposal) multisigSignature(signatures []ProposalSignature) []*felt.Felt {
+	slices.SortFunc(signatures, func(a, b ProposalSignature) int {
+		return feltFromHex(p.signerID(a.Signer)).Cmp(feltFromHex(p.signerID(b.Signer)))
+	})
+
+	if p.layout() == LayoutSignerTriples {
+		signature := make([]*felt.Felt, 0, 3*len(signatures))
+		for _, sig := range signatures {
+			signature = append(signature, feltFromHex(sig.Signer), feltFromHex(sig.R), feltFromHex(sig.S))
+		}
+		return signature
+	}
+
+	signature := make([]*felt.Felt, 0, 1+4*len(signatures))
+	signature = append(signature, new(felt.Felt).SetUint64(uint64(len(signatures))))
+	for _, sig := range signatures {
+		signature = append(signature, new(felt.Felt).SetUint64(starknetSignerVariant),
+			feltFromHex(sig.Signer), feltFromHex(sig.R), feltFromHex(sig.S))
+	}
+	return signature
+}
+
+// multisigConfig is the threshold and signer set of a multisig account
+type multisigConfig struct {
+	threshold uint64
+	signers   []string
+	layout    MultisigLayout
+}
+
+// multisigConfig reads the threshold and signers of a multisig account. Multisigs
+// without get_signer_guids predate GUIDs and list public keys with get_signers.
+func (d *Deployer) multisigConfig(ctx context.Context, multisig *felt.Felt) (*multisigConfig, error) {
+	threshold, err := d.callMultisig(ctx, multisig, "get_threshold")
+	if err != nil {
+		return nil, err
+	}
+	if len(threshold) != 1 {
+		return nil, fmt.Errorf("unexpected get_threshold response length: %d", len(threshold))
+	}
+
+	layout := LayoutSignerSignatures
+	signers, err := d.callMultisig(ctx, multisig, "get_signer_guids")
+	if err != nil {
+		var guidsErr error
+		layout, guidsErr = LayoutSignerTriples, err
+		if signers, err = d.callMultisig(ctx, multisig, "get_signers"); err != nil {
+			return nil, errors.Join(guidsErr, err)
+		}
+	}
+	if len(signers) == 0 || signers[0].Uint64() != uint64(len(signers)-1) {
+		return nil, fmt.Errorf("unexpected multisig signers response")
+	}
+
+	config := &multisigConfig{threshold: threshold[0].Uint64(), layout: layout}
+	for _, s := range signers[1:] {
+		config.signers = append(config.signers, s.String())
+	}
+	return config, nil
+}
+
+// callMultisig reads a view function of a multisig account without arguments
+func (d *Deployer) callMultisig(ctx context.Context, multisig *felt.Felt, function string) ([]*felt.Felt, error) {
+	resp, err := d.client.Call(ctx, rpc.FunctionCall{
+		ContractAddress:    multisig,
+		EntryPointSelector: utils.GetSelectorFromNameFelt(function),
+	}, rpc.WithBlockTag(rpc.BlockTagLatest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read multisig %s: %w", function, err)
+	}
+	return resp, nil
+}
+
+// StarknetSignerGUID returns the GUID Argent multisig 0.2 and later list a Stark public
+// key under: poseidon('Starknet Signer', pubkey)
+func StarknetSignerGUID(publicKey *felt.Felt) *felt.Felt {
+	return curve.Poseidon(starknetSignerType, publicKey)
+}
+
+func (p *Proposal) layout() MultisigLayout {
+	if p.Layout == "" {
+		return LayoutSignerTriples
+	}
+	return p.Layout
+}
+
+// signerID returns how the multisig lists the signer with publicKey
+func (p *Proposal) signerID(publicKey string) string {
+	if p.layout() == LayoutSignerTriples {
+		return publicKey
+	}
+	key, err := utils.HexToFelt(publicKey)
+	if err != nil {
+		return ""
+	}
+	return StarknetSignerGUID(key).String()
+}
+
+func (p *Proposal) isSigner(publicKey string) bool {
+	return slices.Contains(p.Signers, p.signerID(publicKey))
+}
+
+// normalizeKey formats a hex key the way felts are printed so keys compare as strings
+func normalizeKey(key string) (string, error) {
+	f, err := utils.HexToFelt(key)
+	if err != nil {
+		return "", err
+	}
+	return f.String(), nil
+}
+
+// feltFromHex converts a hex string already validated by Verify
+func feltFromHex(value string) *felt.Felt {
+	f, _ := utils.HexToFelt(value)
+	return f
+}
+
+// LoadProposal reads a multisig proposal from a JSON file
+func LoadProposal(path string) (*Proposal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proposal %s: %w", path, err)
+	}
+
+	var proposal Proposal
+	if err := json.Unmarshal(data, &proposal); err != nil {
+		return nil, fmt.Errorf("failed to parse proposal %s: %w", path, err)
+	}
+
+	return &proposal, nil
+}
+
+// SaveProposal writes a multisig proposal to a JSON file
+func SaveProposal(path string, proposal *Proposal) error {
+	data, err := json.MarshalIndent(proposal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode proposal: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write proposal %s: %w", path, err)
+	}
+
+	return nil
+}
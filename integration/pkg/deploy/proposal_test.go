@@ -0,0 +1,169 @@
+package deploy_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy/deploytest"
+	"github.com/NovemberFork/etheracts/integration/pkg/signer"
+)
+
+// multisigSigners are three signers of a 2-of-3 multisig
+func multisigSigners(t *testing.T) []*signer.LocalSigner {
+	t.Helper()
+	var signers []*signer.LocalSigner
+	for _, key := range []string{"0x11", "0x12", "0x13"} {
+		s, err := signer.NewLocalSignerFromHex(key, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers = append(signers, s)
+	}
+	return signers
+}
+
+// fakeMultisig answers the multisig views: get_signer_guids when layout is
+// LayoutSignerSignatures, get_signers otherwise
+func fakeMultisig(provider *deploytest.Provider, layout deploy.MultisigLayout, signers []*signer.LocalSigner) {
+	listed := []*felt.Felt{new(felt.Felt).SetUint64(uint64(len(signers)))}
+	for _, s := range signers {
+		key, _ := utils.HexToFelt(s.PublicKey())
+		if layout == deploy.LayoutSignerSignatures {
+			key = deploy.StarknetSignerGUID(key)
+		}
+		listed = append(listed, key)
+	}
+
+	provider.HandleCall("get_threshold", func(rpc.FunctionCall) ([]*felt.Felt, error) {
+		return []*felt.Felt{new(felt.Felt).SetUint64(2)}, nil
+	})
+	entryPoint := "get_signers"
+	if layout == deploy.LayoutSignerSignatures {
+		entryPoint = "get_signer_guids"
+	}
+	provider.HandleCall(entryPoint, func(rpc.FunctionCall) ([]*felt.Felt, error) {
+		return listed, nil
+	})
+}
+
+func TestSubmitProposalSignatureLayout(t *testing.T) {
+	for _, layout := range []deploy.MultisigLayout{deploy.LayoutSignerTriples, deploy.LayoutSignerSignatures} {
+		t.Run(string(layout), func(t *testing.T) {
+			ctx := t.Context()
+			provider := deploytest.NewProvider()
+			signers := multisigSigners(t)
+			fakeMultisig(provider, layout, signers)
+			d := newDeployer(t, provider)
+
+			multisig := new(felt.Felt).SetUint64(0x5151)
+			target := new(felt.Felt).SetUint64(0xe7)
+			p, err := d.BuildProposal(ctx, multisig, "Set mint price", []rpc.InvokeFunctionCall{{
+				ContractAddress: target,
+				FunctionName:    "set_mint_price",
+				CallData:        []*felt.Felt{new(felt.Felt).SetUint64(5), new(felt.Felt)},
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Layout != layout || p.Threshold != 2 || len(p.Signers) != 3 {
+				t.Fatalf("proposal = %+v", p)
+			}
+
+			// The third signer signs first; the multisig still gets the signatures in its order
+			if err := p.Sign(ctx, signers[2]); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := d.SubmitProposal(ctx, p); err == nil {
+				t.Fatal("proposal submitted below the threshold")
+			}
+			if err := p.Sign(ctx, signers[0]); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Sign(ctx, signers[0]); err == nil {
+				t.Error("signer approved twice")
+			}
+			outsider, _ := signer.NewLocalSignerFromHex("0x14", "")
+			if err := p.Sign(ctx, outsider); err == nil {
+				t.Error("outsider approved the proposal")
+			}
+			for _, s := range p.Signers {
+				if want := s != p.Signers[1]; p.HasSigned(s) != want {
+					t.Errorf("HasSigned(%s) = %t", s, !want)
+				}
+			}
+
+			if _, err := d.SubmitProposal(ctx, p); err != nil {
+				t.Fatal(err)
+			}
+			invokes := provider.Invokes()
+			if len(invokes) != 1 || !invokes[0].SenderAddress.Equal(multisig) {
+				t.Fatalf("invokes = %+v", invokes)
+			}
+
+			approvals := map[string]deploy.ProposalSignature{}
+			for _, sig := range p.Signatures {
+				approvals[sig.Signer] = sig
+			}
+			approved := []string{signers[0].PublicKey(), signers[2].PublicKey()}
+			sortKey := func(publicKey string) *felt.Felt {
+				key, _ := utils.HexToFelt(publicKey)
+				if layout == deploy.LayoutSignerSignatures {
+					return deploy.StarknetSignerGUID(key)
+				}
+				return key
+			}
+			slices.SortFunc(approved, func(a, b string) int { return sortKey(a).Cmp(sortKey(b)) })
+
+			var want []string
+			if layout == deploy.LayoutSignerSignatures {
+				want = append(want, "0x2")
+			}
+			for _, publicKey := range approved {
+				if layout == deploy.LayoutSignerSignatures {
+					want = append(want, "0x0")
+				}
+				want = append(want, publicKey, approvals[publicKey].R, approvals[publicKey].S)
+			}
+			if got := utils.FeltArrToStringArr(invokes[0].Signature); !slices.Equal(got, want) {
+				t.Errorf("signature = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSubmitProposalRejectsUpgradedMultisig(t *testing.T) {
+	ctx := t.Context()
+	signers := multisigSigners(t)
+	provider := deploytest.NewProvider()
+	fakeMultisig(provider, deploy.LayoutSignerTriples, signers)
+	d := newDeployer(t, provider)
+
+	p, err := d.BuildProposal(ctx, new(felt.Felt).SetUint64(0x5151), "", []rpc.InvokeFunctionCall{{
+		ContractAddress: new(felt.Felt).SetUint64(0xe7),
+		FunctionName:    "set_is_minting",
+		CallData:        []*felt.Felt{new(felt.Felt).SetUint64(1)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range signers[:2] {
+		if err := p.Sign(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The multisig is upgraded to a version listing signer GUIDs before submission
+	fakeMultisig(provider, deploy.LayoutSignerSignatures, signers)
+	if _, err := d.SubmitProposal(ctx, p); err == nil || !strings.Contains(err.Error(), "rebuild it") {
+		t.Errorf("err = %v, want the proposal rejected for its layout", err)
+	}
+	if len(provider.Invokes()) != 0 {
+		t.Error("proposal was submitted")
+	}
+}
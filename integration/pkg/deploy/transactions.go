@@ -0,0 +1,278 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/account"
+	"github.com/NethermindEth/starknet.go/contracts"
+	"github.com/NethermindEth/starknet.go/hash"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/signer"
+)
+
+// feeMultiplier is the safety margin applied to estimated resource bounds
+const feeMultiplier = 1.5
+
+// BuildInvoke builds an unsigned v3 invoke transaction for the given calls with the
+// nonce and resource bounds pinned. The fee is estimated with SKIP_VALIDATE so no
+// signature is needed until the final transaction is signed.
+func (d *Deployer) BuildInvoke(ctx context.Context, calls []rpc.InvokeFunctionCall) (*rpc.BroadcastInvokeTxnV3, error) {
+	return d.buildInvoke(ctx, d.address, calls)
+}
+
+// buildInvoke builds an unsigned invoke transaction sent from sender, which may be
+// another account such as a multisig owning the target contract
+func (d *Deployer) buildInvoke(ctx context.Context, sender *felt.Felt, calls []rpc.InvokeFunctionCall) (*rpc.BroadcastInvokeTxnV3, error) {
+	nonce, err := d.nonceOf(ctx, sender)
+	if err != nil {
+		return nil, err
+	}
+	return d.buildInvokeWithNonce(ctx, sender, nonce, calls)
+}
+
+func (d *Deployer) buildInvokeWithNonce(ctx context.Context, sender, nonce *felt.Felt, calls []rpc.InvokeFunctionCall) (*rpc.BroadcastInvokeTxnV3, error) {
+	callData := account.FmtCallDataCairo2(utils.InvokeFuncCallsToFunctionCalls(calls))
+	invokeTxn := utils.BuildInvokeTxn(sender, nonce, callData, zeroResourceBounds(), nil)
+
+	// estimate txn fee
+	estimate, err := d.client.EstimateFee(
+		ctx,
+		[]rpc.BroadcastTxn{invokeTxn},
+		[]rpc.SimulationFlag{rpc.SKIP_VALIDATE},
+		rpc.WithBlockTag(rpc.BlockTagPre_confirmed),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate fee: %w", classifyError(err))
+	}
+	invokeTxn.ResourceBounds = utils.FeeEstToResBoundsMap(estimate[0], feeMultiplier)
+
+	return invokeTxn, nil
+}
+
+// BuildDeclare builds an unsigned v3 declare transaction with the nonce and resource bounds pinned
+func (d *Deployer) BuildDeclare(ctx context.Context, casmClass *contracts.CasmClass, contractClass *contracts.ContractClass) (*rpc.BroadcastDeclareTxnV3, error) {
+	nonce, err := d.nonceOf(ctx, d.address)
+	if err != nil {
+		return nil, err
+	}
+	return d.buildDeclareWithNonce(ctx, nonce, casmClass, contractClass)
+}
+
+func (d *Deployer) buildDeclareWithNonce(ctx context.Context, nonce *felt.Felt, casmClass *contracts.CasmClass, contractClass *contracts.ContractClass) (*rpc.BroadcastDeclareTxnV3, error) {
+	declareTxn, err := utils.BuildDeclareTxn(d.address, casmClass, contractClass, nonce, zeroResourceBounds(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build declare transaction: %w", err)
+	}
+
+	// estimate txn fee
+	estimate, err := d.client.EstimateFee(
+		ctx,
+		[]rpc.BroadcastTxn{declareTxn},
+		[]rpc.SimulationFlag{rpc.SKIP_VALIDATE},
+		rpc.WithBlockTag(rpc.BlockTagPre_confirmed),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate fee: %w", classifyError(err))
+	}
+	declareTxn.ResourceBounds = utils.FeeEstToResBoundsMap(estimate[0], feeMultiplier)
+
+	return declareTxn, nil
+}
+
+// Invoke builds, signs and submits a v3 invoke transaction for the given calls without
+// waiting for it. With nonce tracking enabled several invokes can be in flight at once.
+func (d *Deployer) Invoke(ctx context.Context, calls []rpc.InvokeFunctionCall) (*felt.Felt, error) {
+	return d.sendInvoke(ctx, calls)
+}
+
+// sendInvoke builds, signs and submits a v3 invoke transaction for the given calls
+func (d *Deployer) sendInvoke(ctx context.Context, calls []rpc.InvokeFunctionCall) (*felt.Felt, error) {
+	nonce, err := d.reserveNonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash, err := d.submitInvoke(ctx, nonce, calls)
+	d.settleNonce(nonce, txHash, err)
+	if err != nil {
+		return nil, err
+	}
+	return txHash, nil
+}
+
+func (d *Deployer) submitInvoke(ctx context.Context, nonce *felt.Felt, calls []rpc.InvokeFunctionCall) (*felt.Felt, error) {
+	invokeTxn, err := d.buildInvokeWithNonce(ctx, d.address, nonce, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash, err := hash.TransactionHashInvokeV3(invokeTxn, d.chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute transaction hash: %w", err)
+	}
+
+	signature, err := d.sign(ctx, &signer.SignRequest{
+		Type:            signer.TxTypeInvoke,
+		TransactionHash: txHash.String(),
+		Nonce:           invokeTxn.Nonce.String(),
+		Calls:           signer.DecodeCalls(calls),
+	})
+	if err != nil {
+		return nil, err
+	}
+	invokeTxn.Signature = signature
+
+	if _, err := d.client.AddInvokeTransaction(ctx, invokeTxn); err != nil {
+		if err := d.confirmSubmission(ctx, txHash, err); err != nil {
+			return txHash, err
+		}
+	}
+	d.metrics.TransactionSubmitted(string(signer.TxTypeInvoke))
+
+	return txHash, nil
+}
+
+// sendDeclare builds, signs and submits a v3 declare transaction.
+// It returns the transaction hash and the declared class hash.
+func (d *Deployer) sendDeclare(ctx context.Context, casmClass *contracts.CasmClass, contractClass *contracts.ContractClass) (*felt.Felt, *felt.Felt, error) {
+	nonce, err := d.reserveNonce(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txHash, classHash, err := d.submitDeclare(ctx, nonce, casmClass, contractClass)
+	d.settleNonce(nonce, txHash, err)
+	if err != nil {
+		return nil, nil, err
+	}
+	return txHash, classHash, nil
+}
+
+func (d *Deployer) submitDeclare(ctx context.Context, nonce *felt.Felt, casmClass *contracts.CasmClass, contractClass *contracts.ContractClass) (*felt.Felt, *felt.Felt, error) {
+	declareTxn, err := d.buildDeclareWithNonce(ctx, nonce, casmClass, contractClass)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txHash, err := hash.TransactionHashBroadcastDeclareV3(declareTxn, d.chainID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to compute transaction hash: %w", err)
+	}
+
+	classHash := hash.ClassHash(contractClass)
+	signature, err := d.sign(ctx, &signer.SignRequest{
+		Type:            signer.TxTypeDeclare,
+		TransactionHash: txHash.String(),
+		Nonce:           declareTxn.Nonce.String(),
+		ClassHash:       classHash.String(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	declareTxn.Signature = signature
+
+	if _, err := d.client.AddDeclareTransaction(ctx, declareTxn); err != nil {
+		if err := d.confirmSubmission(ctx, txHash, err); err != nil {
+			return txHash, nil, err
+		}
+	}
+	d.metrics.TransactionSubmitted(string(signer.TxTypeDeclare))
+
+	return txHash, classHash, nil
+}
+
+// confirmSubmission checks whether a failed submission reached the chain anyway. A timeout
+// or dropped connection can hide an accepted transaction, and resending one fails as a
+// duplicate, but in both cases the node knows its hash. It returns the classified error
+// when the transaction is unknown.
+func (d *Deployer) confirmSubmission(ctx context.Context, txHash *felt.Felt, err error) error {
+	if _, statusErr := d.client.GetTransactionStatus(ctx, txHash); statusErr != nil {
+		return classifyError(err)
+	}
+	d.logger.Warnf("⚠️  Submitting %s failed (%s), but the node knows the transaction", txHash.String(), err)
+	return nil
+}
+
+// reserveNonce returns the nonce for the next deployer transaction, allocated locally when
+// nonce tracking is enabled
+func (d *Deployer) reserveNonce(ctx context.Context) (*felt.Felt, error) {
+	if d.nonces == nil {
+		return d.nonceOf(ctx, d.address)
+	}
+	return d.nonces.Next(ctx)
+}
+
+// settleNonce records the outcome of a submission with the nonce manager. txHash is set
+// when the transaction was sent, even if the submission failed.
+func (d *Deployer) settleNonce(nonce, txHash *felt.Felt, err error) {
+	if d.nonces == nil {
+		return
+	}
+
+	switch {
+	case err == nil:
+		if saveErr := d.nonces.Submitted(nonce, txHash); saveErr != nil {
+			d.logger.Warnf("⚠️  %s", saveErr)
+		}
+	case txHash == nil:
+		// Failed before sending (fee estimation, signing)
+		d.nonces.Release(nonce)
+	case errors.Is(err, ErrInvalidNonce):
+		d.nonces.Release(nonce)
+		d.nonces.Resync()
+	case rejected(err):
+		d.nonces.Release(nonce)
+	default:
+		// The node may hold the transaction: releasing its nonce could hand it to another
+		// transaction, so keep it in flight and let the chain decide
+		if saveErr := d.nonces.Unconfirmed(nonce, txHash); saveErr != nil {
+			d.logger.Warnf("⚠️  %s", saveErr)
+		}
+	}
+}
+
+// nonceOf returns the pre-confirmed nonce of an account
+func (d *Deployer) nonceOf(ctx context.Context, address *felt.Felt) (*felt.Felt, error) {
+	nonce, err := d.client.Nonce(ctx, rpc.WithBlockTag(rpc.BlockTagPre_confirmed), address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// sign fills in the account details of req and asks the configured signer to sign it
+func (d *Deployer) sign(ctx context.Context, req *signer.SignRequest) ([]*felt.Felt, error) {
+	if d.signer == nil {
+		return nil, fmt.Errorf("no signer configured for account %s", d.address.String())
+	}
+
+	req.Network = d.network
+	req.ChainID = d.chainName()
+	req.Account = d.address.String()
+
+	signature, err := d.signer.SignTransaction(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return signature, nil
+}
+
+// chainName returns the account chain ID as a short string (e.g. SN_SEPOLIA)
+func (d *Deployer) chainName() string {
+	return utils.HexToShortStr(d.chainID.String())
+}
+
+// zeroResourceBounds returns empty resource bounds used while estimating fees
+func zeroResourceBounds() *rpc.ResourceBoundsMapping {
+	zero := rpc.ResourceBounds{MaxAmount: "0x0", MaxPricePerUnit: "0x0"}
+	return &rpc.ResourceBoundsMapping{
+		L1Gas:     zero,
+		L1DataGas: zero,
+		L2Gas:     zero,
+	}
+}
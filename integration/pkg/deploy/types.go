@@ -1,6 +1,7 @@
 package deploy
 
 import (
+	"context"
 	"time"
 
 	"github.com/NethermindEth/juno/core/felt"
@@ -19,7 +20,7 @@ type DeploymentResult struct {
 // ContractDeployer defines the interface for contract deployment
 type ContractDeployer interface {
 	// Deploy deploys the contract and returns the deployment result
-	Deploy() (*DeploymentResult, error)
+	Deploy(ctx context.Context) (*DeploymentResult, error)
 	
 	// GetContractName returns the name of the contract
 	GetContractName() string
@@ -52,14 +53,17 @@ const (
 	StatusFailed     DeploymentStatus = "failed"
 )
 
-// DeploymentProgress contains progress information for a deployment
+// DeploymentProgress contains progress information for a deployment. ElapsedTime is
+// measured from the start of the deployment and EstimatedTime is the expected time left.
 type DeploymentProgress struct {
-	Status        DeploymentStatus `json:"status"`
-	Message       string           `json:"message"`
-	Progress      float64          `json:"progress"`
-	CurrentStep   string           `json:"current_step"`
-	TotalSteps    int              `json:"total_steps"`
-	ElapsedTime   time.Duration    `json:"elapsed_time"`
-	EstimatedTime time.Duration    `json:"estimated_time"`
+	Contract        string           `json:"contract"`
+	Status          DeploymentStatus `json:"status"`
+	Message         string           `json:"message"`
+	Progress        float64          `json:"progress"`
+	CurrentStep     string           `json:"current_step"`
+	TotalSteps      int              `json:"total_steps"`
+	ElapsedTime     time.Duration    `json:"elapsed_time"`
+	EstimatedTime   time.Duration    `json:"estimated_time"`
+	TransactionHash string           `json:"transaction_hash,omitempty"`
 }
 
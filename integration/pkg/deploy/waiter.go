@@ -0,0 +1,171 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+// WaitOptions controls how transactions are awaited. Zero fields take the value of
+// DefaultWaitOptions.
+type WaitOptions struct {
+	// Timeout is the deadline for reaching Finality, after which ErrReceiptTimeout is returned
+	Timeout time.Duration
+	// InitialInterval is the first poll interval; it grows by Multiplier (at least 1) up to MaxInterval
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	// Finality is the status to wait for: ACCEPTED_ON_L2 or ACCEPTED_ON_L1
+	Finality rpc.TxnStatus
+}
+
+// DefaultWaitOptions waits up to 5 minutes for L2 acceptance, polling every 1s backing off to 15s
+func DefaultWaitOptions() WaitOptions {
+	return WaitOptions{
+		Timeout:         5 * time.Minute,
+		InitialInterval: time.Second,
+		MaxInterval:     15 * time.Second,
+		Multiplier:      1.5,
+		Finality:        rpc.TxnStatus_Accepted_On_L2,
+	}
+}
+
+func (o WaitOptions) withDefaults() WaitOptions {
+	defaults := DefaultWaitOptions()
+	if o.Timeout <= 0 {
+		o.Timeout = defaults.Timeout
+	}
+	if o.InitialInterval <= 0 {
+		o.InitialInterval = defaults.InitialInterval
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = defaults.MaxInterval
+	}
+	if o.Multiplier == 0 {
+		o.Multiplier = defaults.Multiplier
+	}
+	if o.Finality == "" {
+		o.Finality = defaults.Finality
+	}
+	return o
+}
+
+// validate rejects options that would poll without backing off or wait for no finality
+func (o WaitOptions) validate() error {
+	if o.Multiplier < 1 {
+		return fmt.Errorf("poll interval multiplier %v is below 1", o.Multiplier)
+	}
+	if _, ok := finalityRank[o.Finality]; !ok {
+		return fmt.Errorf("unsupported target finality: %s", o.Finality)
+	}
+	return nil
+}
+
+// finalityRank orders finality statuses from least to most final
+var finalityRank = map[rpc.TxnStatus]int{
+	rpc.TxnStatus_Received:       1,
+	rpc.TxnStatus_Candidate:      2,
+	rpc.TxnStatus_Pre_confirmed:  3,
+	rpc.TxnStatus_Accepted_On_L2: 4,
+	rpc.TxnStatus_Accepted_On_L1: 5,
+}
+
+// Waiter follows a transaction through its finality statuses until it is accepted or reverts
+type Waiter struct {
+	client Provider
+	opts   WaitOptions
+	logger *logrus.Logger
+}
+
+// NewWaiter creates a waiter polling client with the given options
+func NewWaiter(client Provider, opts WaitOptions, logger *logrus.Logger) *Waiter {
+	return &Waiter{
+		client: client,
+		opts:   opts.withDefaults(),
+		logger: logger,
+	}
+}
+
+// Wait polls starknet_getTransactionStatus with exponential backoff, logging each finality
+// transition, and returns the receipt once the configured finality is reached. A reverted
+// transaction fails with a *RevertError as soon as the node reports it.
+func (w *Waiter) Wait(ctx context.Context, txHash *felt.Felt) (*rpc.TransactionReceiptWithBlockInfo, error) {
+	if err := w.opts.validate(); err != nil {
+		return nil, err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, w.opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	interval := w.opts.InitialInterval
+	var last rpc.TxnStatus
+
+	for {
+		timer := time.NewTimer(interval)
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastSeen := string(last)
+			if lastSeen == "" {
+				lastSeen = "not received"
+			}
+			return nil, fmt.Errorf("%w: %s still %s after %s", ErrReceiptTimeout, txHash.String(), lastSeen, w.opts.Timeout)
+		case <-timer.C:
+		}
+
+		interval = time.Duration(float64(interval) * w.opts.Multiplier)
+		if interval > w.opts.MaxInterval {
+			interval = w.opts.MaxInterval
+		}
+
+		status, err := w.client.GetTransactionStatus(waitCtx, txHash)
+		if err != nil {
+			if nodeErr, ok := AsNodeError(err); ok && nodeErr.Code == rpc.ErrHashNotFound.Code {
+				continue // not propagated to this node yet
+			}
+			if waitCtx.Err() != nil {
+				continue // reported by the Done case
+			}
+			return nil, fmt.Errorf("failed to get transaction status: %w", classifyError(err))
+		}
+
+		if status.FinalityStatus != last {
+			w.logger.WithField("tx_hash", txHash.String()).Infof("🔄 Transaction %s: %s (%s)", shortHash(txHash), status.FinalityStatus, time.Since(start).Round(time.Second))
+			last = status.FinalityStatus
+		}
+
+		if status.ExecutionStatus == rpc.TxnExecutionStatusREVERTED {
+			reason := status.FailureReason
+			receipt, err := w.client.TransactionReceipt(waitCtx, txHash)
+			if err == nil && receipt.RevertReason != "" {
+				reason = receipt.RevertReason
+			}
+			return receipt, &RevertError{TransactionHash: txHash.String(), Reason: reason}
+		}
+
+		if finalityRank[status.FinalityStatus] >= finalityRank[w.opts.Finality] {
+			receipt, err := w.client.TransactionReceipt(waitCtx, txHash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get transaction receipt: %w", classifyError(err))
+			}
+			return receipt, nil
+		}
+	}
+}
+
+// shortHash abbreviates a transaction hash for progress logs
+func shortHash(txHash *felt.Felt) string {
+	s := txHash.String()
+	if len(s) <= 14 {
+		return s
+	}
+	return s[:8] + "…" + s[len(s)-6:]
+}
@@ -0,0 +1,237 @@
+// Package devnet launches or attaches to a local starknet-devnet for integration tests
+package devnet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotInstalled is returned when no starknet-devnet binary is available
+var ErrNotInstalled = errors.New("starknet-devnet not found")
+
+// Account is a predeployed devnet account
+type Account struct {
+	Address    string `json:"address"`
+	PrivateKey string `json:"private_key"`
+	PublicKey  string `json:"public_key"`
+}
+
+// Devnet is a running starknet-devnet, either launched by Launch or attached to by Attach
+type Devnet struct {
+	// URL is the JSON-RPC endpoint
+	URL string
+
+	baseURL string
+	cmd     *exec.Cmd
+	output  *lockedBuffer
+	client  *http.Client
+}
+
+// Binary returns the devnet binary from STARKNET_DEVNET_BIN or starknet-devnet on PATH
+func Binary() (string, error) {
+	if path := os.Getenv("STARKNET_DEVNET_BIN"); path != "" {
+		return path, nil
+	}
+	path, err := exec.LookPath("starknet-devnet")
+	if err != nil {
+		return "", ErrNotInstalled
+	}
+	return path, nil
+}
+
+// Launch starts devnet on a free local port with a fixed seed, so the predeployed
+// accounts are the same on every run, and waits until it accepts requests
+func Launch(ctx context.Context, binary string, seed int) (*Devnet, error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a free port: %w", err)
+	}
+
+	output := &lockedBuffer{}
+	cmd := exec.Command(binary,
+		"--host", "127.0.0.1",
+		"--port", strconv.Itoa(port),
+		"--seed", strconv.Itoa(seed),
+		"--accounts", "3",
+	)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", binary, err)
+	}
+
+	base := fmt.Sprintf("http://127.0.0.1:%d", port)
+	d := &Devnet{
+		URL:     base + "/rpc",
+		baseURL: base,
+		cmd:     cmd,
+		output:  output,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if err := d.waitAlive(ctx, 30*time.Second); err != nil {
+		d.Close()
+		return nil, fmt.Errorf("devnet did not start: %w\n%s", err, output.String())
+	}
+	return d, nil
+}
+
+// Attach connects to an already running devnet, given its base or JSON-RPC URL
+func Attach(ctx context.Context, url string) (*Devnet, error) {
+	base := strings.TrimSuffix(strings.TrimSuffix(url, "/"), "/rpc")
+	d := &Devnet{
+		URL:     base + "/rpc",
+		baseURL: base,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+	if err := d.waitAlive(ctx, 5*time.Second); err != nil {
+		return nil, fmt.Errorf("devnet at %s is not reachable: %w", base, err)
+	}
+	return d, nil
+}
+
+// Close stops a launched devnet; attached devnets are left running
+func (d *Devnet) Close() error {
+	if d.cmd == nil || d.cmd.Process == nil {
+		return nil
+	}
+	if err := d.cmd.Process.Kill(); err != nil {
+		return err
+	}
+	_ = d.cmd.Wait()
+	return nil
+}
+
+// Accounts returns the predeployed accounts with their keys
+func (d *Devnet) Accounts(ctx context.Context) ([]Account, error) {
+	var accounts []Account
+	if err := d.call(ctx, "devnet_getPredeployedAccounts", nil, &accounts); err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("devnet has no predeployed accounts")
+	}
+	return accounts, nil
+}
+
+// ChainID returns the chain ID as a hex string
+func (d *Devnet) ChainID(ctx context.Context) (string, error) {
+	var chainID string
+	if err := d.call(ctx, "starknet_chainId", []any{}, &chainID); err != nil {
+		return "", err
+	}
+	return chainID, nil
+}
+
+// Mint credits an account with amount FRI, so contracts deployed as accounts can pay fees
+func (d *Devnet) Mint(ctx context.Context, address string, amount *big.Int) error {
+	var result json.RawMessage
+	return d.call(ctx, "devnet_mint", map[string]any{"address": address, "amount": amount, "unit": "FRI"}, &result)
+}
+
+// waitAlive polls /is_alive until it answers or the timeout expires
+func (d *Devnet) waitAlive(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseURL+"/is_alive", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := d.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+			return err
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// call performs a JSON-RPC request against the devnet
+func (d *Devnet) call(ctx context.Context, method string, params, result any) error {
+	request := map[string]any{"jsonrpc": "2.0", "id": 1, "method": method}
+	if params != nil {
+		request["params"] = params
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("invalid %s response: %w", method, err)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("%s failed: %d %s", method, response.Error.Code, response.Error.Message)
+	}
+	return json.Unmarshal(response.Result, result)
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// lockedBuffer collects process output written from several goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,113 @@
+package devnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Seed makes devnet predeploy the same accounts on every run
+const Seed = 42
+
+// Start returns a devnet for the test: the one at DEVNET_URL if set, otherwise a new
+// devnet launched from the local binary and stopped when the test ends. The test is
+// skipped when neither is available.
+func Start(t testing.TB) *Devnet {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if url := os.Getenv("DEVNET_URL"); url != "" {
+		d, err := Attach(ctx, url)
+		if err != nil {
+			t.Fatalf("failed to attach to devnet: %s", err)
+		}
+		return d
+	}
+
+	binary, err := Binary()
+	if errors.Is(err, ErrNotInstalled) {
+		t.Skip("starknet-devnet not installed; set STARKNET_DEVNET_BIN or DEVNET_URL to run devnet tests")
+	}
+
+	d, err := Launch(ctx, binary, Seed)
+	if err != nil {
+		t.Fatalf("failed to launch devnet: %s", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// Artifacts returns the sierra and casm paths of a contract compiled by scarb, building
+// the package once if they are missing. The test is skipped when they cannot be built.
+func Artifacts(t testing.TB, contract string) (sierraPath, casmPath string) {
+	t.Helper()
+
+	root, err := repoRoot()
+	if err != nil {
+		t.Skipf("contract artifacts unavailable: %s", err)
+	}
+	sierraPath = filepath.Join(root, "target", "dev", fmt.Sprintf("etheracts_%s.contract_class.json", contract))
+	casmPath = filepath.Join(root, "target", "dev", fmt.Sprintf("etheracts_%s.compiled_contract_class.json", contract))
+
+	if !exists(sierraPath) || !exists(casmPath) {
+		if err := build(root); err != nil {
+			t.Skipf("contract artifacts unavailable: %s", err)
+		}
+		if !exists(sierraPath) || !exists(casmPath) {
+			t.Fatalf("scarb build did not produce %s", sierraPath)
+		}
+	}
+	return sierraPath, casmPath
+}
+
+var (
+	buildOnce sync.Once
+	buildErr  error
+)
+
+// build runs scarb build in the Cairo package once per test binary
+func build(root string) error {
+	buildOnce.Do(func() {
+		scarb, err := exec.LookPath("scarb")
+		if err != nil {
+			buildErr = fmt.Errorf("artifacts not built and scarb not installed")
+			return
+		}
+		cmd := exec.Command(scarb, "build")
+		cmd.Dir = root
+		if output, err := cmd.CombinedOutput(); err != nil {
+			buildErr = fmt.Errorf("scarb build failed: %w\n%s", err, output)
+		}
+	})
+	return buildErr
+}
+
+// repoRoot finds the directory holding Scarb.toml above the working directory
+func repoRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if exists(filepath.Join(dir, "Scarb.toml")) {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("Scarb.toml not found")
+		}
+		dir = parent
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
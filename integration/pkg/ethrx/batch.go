@@ -0,0 +1,212 @@
+package ethrx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+)
+
+// BatchOptions controls how a BatchReader splits array calls
+type BatchOptions struct {
+	// ChunkSize is the initial number of tokens per call (default 100)
+	ChunkSize int
+	// MinChunkSize is the chunk size below which a resource-limit error is returned (default 1)
+	MinChunkSize int
+	// Workers is the number of concurrent calls (default 8)
+	Workers int
+	// Logger receives chunk size changes; nil logs nothing
+	Logger *logrus.Logger
+}
+
+// BatchReader reads the array entrypoints of an Ethrx contract over any number of tokens.
+// Tokens are split into chunks read concurrently and reassembled in order. When a chunk
+// exceeds the node's step or size limits, the chunk size is halved for the rest of the
+// read and the chunk is retried.
+type BatchReader struct {
+	reader    *Reader
+	opts      BatchOptions
+	chunkSize atomic.Int64
+}
+
+// NewBatchReader creates a batch reader over reader, reading at the reader's block
+func NewBatchReader(reader *Reader, opts BatchOptions) *BatchReader {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = 100
+	}
+	if opts.MinChunkSize <= 0 {
+		opts.MinChunkSize = 1
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 8
+	}
+	if opts.Logger == nil {
+		opts.Logger = logrus.New()
+		opts.Logger.SetOutput(io.Discard)
+	}
+
+	b := &BatchReader{reader: reader, opts: opts}
+	b.chunkSize.Store(int64(opts.ChunkSize))
+	return b
+}
+
+// ChunkSize returns the current chunk size, lowered by resource-limit errors so far
+func (b *BatchReader) ChunkSize() int {
+	return int(b.chunkSize.Load())
+}
+
+// TokenRange returns the token IDs first..last
+func TokenRange(first, last uint64) []*big.Int {
+	if last < first {
+		return nil
+	}
+	ids := make([]*big.Int, 0, last-first+1)
+	for id := first; id <= last; id++ {
+		ids = append(ids, new(big.Int).SetUint64(id))
+	}
+	return ids
+}
+
+// ArtifactIDs returns the artifact ID of each token
+func (b *BatchReader) ArtifactIDs(ctx context.Context, tokenIDs []*big.Int) ([]*felt.Felt, error) {
+	ids := make([]*felt.Felt, len(tokenIDs))
+	err := b.run(ctx, len(tokenIDs), func(ctx context.Context, start, end int) error {
+		chunk, err := b.reader.ArtifactIDs(ctx, tokenIDs[start:end])
+		if err == nil {
+			copy(ids[start:end], chunk)
+		}
+		return err
+	})
+	return ids, err
+}
+
+// Artifacts returns the latest official artifact of each token
+func (b *BatchReader) Artifacts(ctx context.Context, tokenIDs []*big.Int) ([]Artifact, error) {
+	artifacts := make([]Artifact, len(tokenIDs))
+	err := b.run(ctx, len(tokenIDs), func(ctx context.Context, start, end int) error {
+		chunk, err := b.reader.Artifacts(ctx, tokenIDs[start:end])
+		if err == nil {
+			copy(artifacts[start:end], chunk)
+		}
+		return err
+	})
+	return artifacts, err
+}
+
+// TagNonces returns the nonce of every tag for each artifact ID, in the order of tags
+func (b *BatchReader) TagNonces(ctx context.Context, artifactIDs, tags []*felt.Felt) ([][]uint64, error) {
+	nonces := make([][]uint64, len(artifactIDs))
+	if len(tags) == 0 {
+		return nonces, nil
+	}
+	err := b.run(ctx, len(artifactIDs), func(ctx context.Context, start, end int) error {
+		// One (artifact ID, tag) pair per artifact and tag
+		var pairIDs, pairTags []*felt.Felt
+		for _, id := range artifactIDs[start:end] {
+			for _, tag := range tags {
+				pairIDs = append(pairIDs, id)
+				pairTags = append(pairTags, tag)
+			}
+		}
+		chunk, err := b.reader.TagNonces(ctx, pairIDs, pairTags)
+		if err != nil {
+			return err
+		}
+		for i := start; i < end; i++ {
+			offset := (i - start) * len(tags)
+			nonces[i] = chunk[offset : offset+len(tags)]
+		}
+		return nil
+	})
+	return nonces, err
+}
+
+// run splits 0..n into chunks and calls read for each on the worker pool. read stores
+// its results at [start, end), which keeps them in order whatever the chunking.
+func (b *BatchReader) run(ctx context.Context, n int, read func(ctx context.Context, start, end int) error) error {
+	size := b.ChunkSize()
+	chunks := (n + size - 1) / size
+	return forEach(ctx, chunks, b.opts.Workers, func(ctx context.Context, c int) error {
+		return b.readRange(ctx, c*size, min((c+1)*size, n), read)
+	})
+}
+
+// readRange reads [start, end) in chunks of the current size, halving it and retrying
+// whenever a chunk hits a resource limit
+func (b *BatchReader) readRange(ctx context.Context, start, end int, read func(ctx context.Context, start, end int) error) error {
+	for start < end {
+		stop := min(start+b.ChunkSize(), end)
+		err := read(ctx, start, stop)
+		if err == nil {
+			start = stop
+			continue
+		}
+		if !IsResourceLimit(err) || ctx.Err() != nil {
+			return err
+		}
+		if stop-start <= b.opts.MinChunkSize {
+			return fmt.Errorf("%d tokens exceed the node limits: %w", stop-start, err)
+		}
+		b.shrink(stop - start)
+	}
+	return nil
+}
+
+// shrink lowers the chunk size to half of a chunk that failed, unless another worker
+// already lowered it further
+func (b *BatchReader) shrink(failed int) {
+	next := int64(max(failed/2, b.opts.MinChunkSize))
+	for {
+		current := b.chunkSize.Load()
+		if current <= next {
+			return
+		}
+		if b.chunkSize.CompareAndSwap(current, next) {
+			b.opts.Logger.Warnf("⚠️  %d tokens per call hit a node limit, retrying with %d", failed, next)
+			return
+		}
+	}
+}
+
+// executionLimitErrors are fragments of the revert errors of calls that ran out of steps
+// or gas, as opposed to calls that failed an assertion
+var executionLimitErrors = []string{
+	"out of gas",
+	"out of resources",
+	"max steps",
+	"max_steps",
+	"n_steps",
+	"no remaining steps",
+}
+
+// IsResourceLimit reports whether err means a call was too large for the node, so a
+// smaller call may succeed: a contract error from running out of steps or gas, or an
+// HTTP 413 for an oversized request
+func IsResourceLimit(err error) bool {
+	nodeErr, ok := deploy.AsNodeError(err)
+	if !ok {
+		return false
+	}
+
+	switch nodeErr.Code {
+	case rpc.ErrContractError.Code:
+		details := strings.ToLower(nodeErr.Message + " " + nodeErr.Data)
+		for _, fragment := range executionLimitErrors {
+			if strings.Contains(details, fragment) {
+				return true
+			}
+		}
+	case http.StatusRequestEntityTooLarge:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package ethrx_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/ethrx"
+)
+
+// limitedCaller answers token_ids_to_artifact_ids with artifact ID 1000+token ID and
+// rejects calls over limit tokens like a node running out of steps
+type limitedCaller struct {
+	limit int
+
+	mu       sync.Mutex
+	calls    int
+	rejected int
+}
+
+func (c *limitedCaller) Call(ctx context.Context, call rpc.FunctionCall, blockID rpc.BlockID) ([]*felt.Felt, error) {
+	if !call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("token_ids_to_artifact_ids")) {
+		return nil, errors.New("unexpected entrypoint")
+	}
+	n := int(call.Calldata[0].Uint64())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if n > c.limit {
+		c.rejected++
+		return nil, contractError("Max steps exceeded")
+	}
+
+	result := []*felt.Felt{new(felt.Felt).SetUint64(uint64(n))}
+	for i := 0; i < n; i++ {
+		low := call.Calldata[1+2*i].Uint64()
+		result = append(result, new(felt.Felt).SetUint64(1000+low))
+	}
+	return result, nil
+}
+
+func TestBatchReaderHalvesChunksOnResourceLimits(t *testing.T) {
+	caller := &limitedCaller{limit: 30}
+	batch := ethrx.NewBatchReader(ethrx.NewReader(caller, new(felt.Felt).SetUint64(1)), ethrx.BatchOptions{ChunkSize: 100, Workers: 4})
+
+	tokenIDs := ethrx.TokenRange(1, 1111)
+	ids, err := batch.ArtifactIDs(t.Context(), tokenIDs)
+	if err != nil {
+		t.Fatalf("ArtifactIDs: %v", err)
+	}
+
+	for i, id := range ids {
+		if want := 1000 + tokenIDs[i].Uint64(); id.Uint64() != want {
+			t.Fatalf("artifact ID %d = %s, want %d", i, id, want)
+		}
+	}
+	if size := batch.ChunkSize(); size > caller.limit {
+		t.Errorf("chunk size = %d, want at most %d", size, caller.limit)
+	}
+	if caller.rejected == 0 || caller.rejected > 16 {
+		t.Errorf("%d calls rejected out of %d", caller.rejected, caller.calls)
+	}
+}
+
+func TestBatchReaderFailsAtMinimumChunkSize(t *testing.T) {
+	caller := &limitedCaller{limit: 0}
+	batch := ethrx.NewBatchReader(ethrx.NewReader(caller, new(felt.Felt).SetUint64(1)), ethrx.BatchOptions{ChunkSize: 8})
+
+	_, err := batch.ArtifactIDs(t.Context(), ethrx.TokenRange(1, 20))
+	if !ethrx.IsResourceLimit(err) {
+		t.Fatalf("got %v, want a resource-limit error", err)
+	}
+}
+
+// contractError is the error of a call that reverted with message
+func contractError(message string) error {
+	return &rpc.RPCError{
+		Code:    rpc.ErrContractError.Code,
+		Message: rpc.ErrContractError.Message,
+		Data:    &rpc.ContractErrData{RevertError: rpc.ContractExecutionError{Message: message}},
+	}
+}
+
+// transportError is how starknet.go reports an HTTP or connection failure
+func transportError(message string) error {
+	return &rpc.RPCError{Code: rpc.InternalError, Message: "The error is not a valid RPC error", Data: rpc.StringErrData(message)}
+}
+
+func TestIsResourceLimit(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"out of steps", contractError("Max steps exceeded"), true},
+		{"out of gas", fmt.Errorf("get_artifacts failed: %w", contractError("Error in the called contract: Out of gas")), true},
+		{"unexpected contract error message", rpc.Err(40, rpc.StringErrData("Contract error RunResources has no remaining steps.")), true},
+		{"request too large", transportError("413 Request Entity Too Large: request body too big"), true},
+		{"request too large without a body", transportError("413"), true},
+		{"failed assertion", contractError("ERC721: amount too large"), false},
+		{"contract not found", rpc.ErrContractNotFound, false},
+		{"rate limited", transportError("429 Too Many Requests"), false},
+		{"transport", transportError("context deadline exceeded"), false},
+		{"not an RPC error", errors.New("Max steps exceeded"), false},
+		{"nil", nil, false},
+	} {
+		if got := ethrx.IsResourceLimit(tc.err); got != tc.want {
+			t.Errorf("%s: IsResourceLimit(%v) = %t, want %t", tc.name, tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package ethrx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+)
+
+// prunedChain is an Ethrx contract whose node only serves state from block prunedBelow
+type prunedChain struct {
+	contract    *felt.Felt
+	latest      uint64
+	prunedBelow uint64
+
+	events []rpc.EmittedEvent
+	txs    map[string]rpc.InvokeTxnV3
+	traces map[string]rpc.TxnTrace
+	// receipts holds the events of each transaction, including other contracts' events
+	receipts map[string][]rpc.Event
+
+	// State at the latest block
+	tags        []*felt.Felt
+	artifactIDs map[uint64]uint64
+	owners      map[uint64]*felt.Felt
+	nonces      map[string]uint64
+	data        map[string][]byte
+	wipes       uint64
+	mintPrice   uint64
+
+	// Owner-level settings at the latest block
+	owner     *felt.Felt
+	classHash *felt.Felt
+	version   uint64
+	isMinting bool
+	mintToken *felt.Felt
+}
+
+func newPrunedChain() *prunedChain {
+	return &prunedChain{
+		contract:    new(felt.Felt).SetUint64(0xe7),
+		artifactIDs: make(map[uint64]uint64),
+		owners:      make(map[uint64]*felt.Felt),
+		nonces:      make(map[string]uint64),
+		data:        make(map[string][]byte),
+		txs:         make(map[string]rpc.InvokeTxnV3),
+		traces:      make(map[string]rpc.TxnTrace),
+		receipts:    make(map[string][]rpc.Event),
+		owner:       new(felt.Felt).SetUint64(0x0a),
+		classHash:   new(felt.Felt).SetUint64(0xc1),
+		mintToken:   new(felt.Felt).SetUint64(0x57),
+	}
+}
+
+func (c *prunedChain) emit(block uint64, tx *felt.Felt, keys, data []*felt.Felt) {
+	event := rpc.Event{FromAddress: c.contract, EventContent: rpc.EventContent{Keys: keys, Data: data}}
+	c.events = append(c.events, rpc.EmittedEvent{Event: event, BlockNumber: block, TransactionHash: tx})
+	c.receipts[tx.String()] = append(c.receipts[tx.String()], event)
+}
+
+// pay records an ERC20 Transfer of token in the receipt of tx
+func (c *prunedChain) pay(tx, token, from *felt.Felt, amount uint64) {
+	keys := []*felt.Felt{utils.GetSelectorFromNameFelt("Transfer"), from, c.owner}
+	event := rpc.Event{FromAddress: token, EventContent: rpc.EventContent{Keys: keys, Data: encodeU256(new(big.Int).SetUint64(amount))}}
+	c.receipts[tx.String()] = append(c.receipts[tx.String()], event)
+}
+
+// fee records the fee Transfer that ends the receipt of tx. v3 transactions pay in STRK,
+// which is also the usual mint token.
+func (c *prunedChain) fee(tx, from *felt.Felt, amount uint64) {
+	sequencer := new(felt.Felt).SetUint64(0x5e)
+	keys := []*felt.Felt{utils.GetSelectorFromNameFelt("Transfer"), from, sequencer}
+	event := rpc.Event{FromAddress: c.mintToken, EventContent: rpc.EventContent{Keys: keys, Data: encodeU256(new(big.Int).SetUint64(amount))}}
+	c.receipts[tx.String()] = append(c.receipts[tx.String()], event)
+}
+
+// transfer moves a token; saved transfers go through transfer_and_save_artifact
+func (c *prunedChain) transfer(block uint64, tx *felt.Felt, from, to *felt.Felt, token uint64, saved bool) {
+	keys := append([]*felt.Felt{utils.GetSelectorFromNameFelt("Transfer"), from, to}, encodeU256(new(big.Int).SetUint64(token))...)
+	c.emit(block, tx, keys, nil)
+	c.owners[token] = to
+
+	function := "transfer_from"
+	if saved {
+		function = "transfer_and_save_artifact"
+	}
+	args := append(encodeFeltArray([]*felt.Felt{from}), encodeFeltArray([]*felt.Felt{to})...)
+	args = append(args, encodeU256Array([]*big.Int{new(big.Int).SetUint64(token)})...)
+	calldata := []*felt.Felt{new(felt.Felt).SetUint64(1), c.contract, utils.GetSelectorFromNameFelt(function)}
+	c.txs[tx.String()] = rpc.InvokeTxnV3{SenderAddress: from, Calldata: append(append(calldata, new(felt.Felt).SetUint64(uint64(len(args)))), args...)}
+
+	if !saved {
+		c.wipes++
+		c.artifactIDs[token] = c.wipes
+	}
+}
+
+// routedTransfer is a transfer made by a multisig owning the token, executing a proposal
+// sent by signer: the contract call only shows in the trace
+func (c *prunedChain) routedTransfer(block uint64, tx, signer, multisig, to *felt.Felt, token uint64, saved bool) {
+	c.transfer(block, tx, multisig, to, token, saved)
+	direct := c.txs[tx.String()].Calldata
+
+	inner := rpc.FnInvocation{
+		FunctionCall: rpc.FunctionCall{ContractAddress: c.contract, EntryPointSelector: direct[2], Calldata: direct[4:]},
+	}
+	execute := rpc.FnInvocation{
+		FunctionCall: rpc.FunctionCall{ContractAddress: multisig, EntryPointSelector: utils.GetSelectorFromNameFelt("execute_transaction"), Calldata: direct},
+		NestedCalls:  []rpc.FnInvocation{inner},
+	}
+	account := rpc.FnInvocation{
+		FunctionCall: rpc.FunctionCall{ContractAddress: signer, EntryPointSelector: utils.GetSelectorFromNameFelt("__execute__")},
+		NestedCalls:  []rpc.FnInvocation{execute},
+	}
+	calldata := []*felt.Felt{new(felt.Felt).SetUint64(1), multisig, execute.EntryPointSelector, new(felt.Felt).SetUint64(uint64(len(direct)))}
+	c.txs[tx.String()] = rpc.InvokeTxnV3{SenderAddress: signer, Calldata: append(calldata, direct...)}
+	c.traces[tx.String()] = rpc.InvokeTxnTrace{ExecuteInvocation: rpc.ExecInvocation{FnInvocation: &account}}
+}
+
+// mint mints a token through the mint entrypoint, paying the mint price
+func (c *prunedChain) mint(block uint64, tx *felt.Felt, to *felt.Felt, token uint64) {
+	c.transfer(block, tx, &felt.Zero, to, token, false)
+	if c.mintPrice > 0 {
+		c.pay(tx, c.mintToken, to, c.mintPrice)
+	}
+	c.fee(tx, to, 1000)
+	c.txs[tx.String()] = rpc.InvokeTxnV3{SenderAddress: to, Calldata: []*felt.Felt{
+		new(felt.Felt).SetUint64(1), c.contract, utils.GetSelectorFromNameFelt("mint"), &felt.Zero,
+	}}
+}
+
+func (c *prunedChain) engrave(block uint64, tx *felt.Felt, token uint64, tag, data string) {
+	aid := c.artifactIDs[token]
+	key := fmt.Sprintf("%d/%s", aid, tag)
+	old := c.data[fmt.Sprintf("%s/%d", key, c.nonces[key])]
+	c.nonces[key]++
+	c.data[fmt.Sprintf("%s/%d", key, c.nonces[key])] = []byte(data)
+
+	tagFelt, _ := TagFelt(tag)
+	payload := encodeU256(new(big.Int).SetUint64(token))
+	payload = append(append(payload, tagFelt), encodeBytes(old)...)
+	payload = append(append(payload, tagFelt), encodeBytes([]byte(data))...)
+	c.emit(block, tx, []*felt.Felt{utils.GetSelectorFromNameFelt("ArtifactEngraved")}, payload)
+	c.txs[tx.String()] = rpc.InvokeTxnV3{SenderAddress: c.owners[token], Calldata: []*felt.Felt{
+		new(felt.Felt).SetUint64(1), c.contract, utils.GetSelectorFromNameFelt("engrave"), &felt.Zero,
+	}}
+}
+
+func (c *prunedChain) Call(ctx context.Context, call rpc.FunctionCall, blockID rpc.BlockID) ([]*felt.Felt, error) {
+	if blockID.Number != nil && *blockID.Number < c.prunedBelow {
+		return nil, rpc.ErrBlockNotFound
+	}
+	d := newDecoder(call.Calldata)
+	switch {
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("official_tags")):
+		return encodeFeltArray(c.tags), nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("total_artifacts")):
+		return []*felt.Felt{new(felt.Felt).SetUint64(c.wipes)}, nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("mint_price")):
+		return encodeU256(new(big.Int).SetUint64(c.mintPrice)), nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("owner")):
+		return []*felt.Felt{c.owner}, nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("version")):
+		return []*felt.Felt{new(felt.Felt).SetUint64(c.version)}, nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("is_minting")):
+		if c.isMinting {
+			return []*felt.Felt{new(felt.Felt).SetUint64(1)}, nil
+		}
+		return []*felt.Felt{&felt.Zero}, nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("mint_token")):
+		return []*felt.Felt{c.mintToken}, nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("max_supply")),
+		call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("total_supply")):
+		return encodeU256(new(big.Int).SetUint64(uint64(len(c.owners)))), nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("token_by_index")):
+		// Tokens are minted as 1..N
+		index, _ := d.u256()
+		return encodeU256(new(big.Int).Add(index, big.NewInt(1))), nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("owner_of")):
+		id, _ := d.u256()
+		return []*felt.Felt{c.owners[id.Uint64()]}, nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("token_ids_to_artifact_ids")):
+		n, _ := d.length()
+		ids := make([]*felt.Felt, n)
+		for i := range ids {
+			id, _ := d.u256()
+			ids[i] = new(felt.Felt).SetUint64(c.artifactIDs[id.Uint64()])
+		}
+		return encodeFeltArray(ids), nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("artifact_tag_nonces")):
+		ids, _ := d.felts252()
+		tags, _ := d.felts252()
+		result := []*felt.Felt{new(felt.Felt).SetUint64(uint64(len(ids)))}
+		for i := range ids {
+			result = append(result, new(felt.Felt).SetUint64(c.nonces[fmt.Sprintf("%d/%s", ids[i].Uint64(), TagName(tags[i]))]))
+		}
+		return result, nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("get_artifacts")):
+		n, _ := d.length()
+		result := []*felt.Felt{new(felt.Felt).SetUint64(uint64(n))}
+		for i := 0; i < n; i++ {
+			id, _ := d.u256()
+			var artifact Artifact
+			for _, tag := range c.tags {
+				key := fmt.Sprintf("%d/%s", c.artifactIDs[id.Uint64()], TagName(tag))
+				artifact = append(artifact, Engraving{Tag: TagName(tag), Data: c.data[fmt.Sprintf("%s/%d", key, c.nonces[key])]})
+			}
+			encoded, _ := encodeArtifact(artifact)
+			result = append(result, encoded...)
+		}
+		return result, nil
+	case call.EntryPointSelector.Equal(utils.GetSelectorFromNameFelt("get_historic_artifacts")):
+		ids, _ := d.felts252()
+		n, _ := d.length()
+		tags := make([][]*felt.Felt, n)
+		for i := range tags {
+			tags[i], _ = d.felts252()
+		}
+		n, _ = d.length()
+		nonces := make([][]uint64, n)
+		for i := range nonces {
+			nonces[i], _ = d.uints()
+		}
+		result := []*felt.Felt{new(felt.Felt).SetUint64(uint64(len(ids)))}
+		for i, id := range ids {
+			var artifact Artifact
+			for j, tag := range tags[i] {
+				artifact = append(artifact, Engraving{Tag: TagName(tag), Data: c.data[fmt.Sprintf("%d/%s/%d", id.Uint64(), TagName(tag), nonces[i][j])]})
+			}
+			encoded, _ := encodeArtifact(artifact)
+			result = append(result, encoded...)
+		}
+		return result, nil
+	}
+	return nil, errors.New("unexpected entrypoint")
+}
+
+func (c *prunedChain) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.latest, nil
+}
+
+func (c *prunedChain) BlockWithTxHashes(ctx context.Context, blockID rpc.BlockID) (interface{}, error) {
+	header := rpc.BlockHeader{Hash: new(felt.Felt).SetUint64(0xb10c + *blockID.Number), Number: *blockID.Number, Timestamp: 1700000000 + *blockID.Number}
+	return &rpc.BlockTxHashes{BlockHeader: header}, nil
+}
+
+func (c *prunedChain) ClassHashAt(ctx context.Context, blockID rpc.BlockID, contractAddress *felt.Felt) (*felt.Felt, error) {
+	return c.classHash, nil
+}
+
+func (c *prunedChain) Events(ctx context.Context, input rpc.EventsInput) (*rpc.EventChunk, error) {
+	var events []rpc.EmittedEvent
+	for _, event := range c.events {
+		if event.BlockNumber >= *input.FromBlock.Number && event.BlockNumber <= *input.ToBlock.Number {
+			events = append(events, event)
+		}
+	}
+	return &rpc.EventChunk{Events: events}, nil
+}
+
+func (c *prunedChain) TransactionByHash(ctx context.Context, hash *felt.Felt) (*rpc.BlockTransaction, error) {
+	tx, ok := c.txs[hash.String()]
+	if !ok {
+		return nil, rpc.ErrHashNotFound
+	}
+	return &rpc.BlockTransaction{Hash: hash, Transaction: tx}, nil
+}
+
+func (c *prunedChain) TransactionReceipt(ctx context.Context, hash *felt.Felt) (*rpc.TransactionReceiptWithBlockInfo, error) {
+	if _, ok := c.txs[hash.String()]; !ok {
+		return nil, rpc.ErrHashNotFound
+	}
+	return &rpc.TransactionReceiptWithBlockInfo{TransactionReceipt: rpc.TransactionReceipt{Hash: hash, Events: c.receipts[hash.String()]}}, nil
+}
+
+func (c *prunedChain) TraceTransaction(ctx context.Context, hash *felt.Felt) (rpc.TxnTrace, error) {
+	trace, ok := c.traces[hash.String()]
+	if !ok {
+		return nil, rpc.ErrNoTraceAvailable
+	}
+	return trace, nil
+}
+
+// encodeBytes serializes alexandria Bytes: the byte size and 16-byte big-endian words,
+// the last one padded with zeros on the right
+func encodeBytes(data []byte) []*felt.Felt {
+	words := (len(data) + 15) / 16
+	calldata := []*felt.Felt{new(felt.Felt).SetUint64(uint64(len(data))), new(felt.Felt).SetUint64(uint64(words))}
+	for i := 0; i < words; i++ {
+		word := make([]byte, 16)
+		copy(word, data[i*16:min(len(data), (i+1)*16)])
+		calldata = append(calldata, new(felt.Felt).SetBytes(word))
+	}
+	return calldata
+}
+
+// encodeArtifact serializes an Artifact as its array of engravings, as get_artifacts returns it
+func encodeArtifact(artifact Artifact) ([]*felt.Felt, error) {
+	calldata := []*felt.Felt{new(felt.Felt).SetUint64(uint64(len(artifact)))}
+	for _, engraving := range artifact {
+		tag, err := TagFelt(engraving.Tag)
+		if err != nil {
+			return nil, err
+		}
+		calldata = append(calldata, tag)
+		calldata = append(calldata, encodeBytes(engraving.Data)...)
+	}
+	return calldata, nil
+}
@@ -0,0 +1,194 @@
+package ethrx
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+// decoder reads Cairo-serialized values from call results or event data
+type decoder struct {
+	felts []*felt.Felt
+	pos   int
+}
+
+func newDecoder(felts []*felt.Felt) *decoder {
+	return &decoder{felts: felts}
+}
+
+func (d *decoder) felt() (*felt.Felt, error) {
+	if d.pos >= len(d.felts) {
+		return nil, fmt.Errorf("unexpected end of data at position %d", d.pos)
+	}
+	f := d.felts[d.pos]
+	d.pos++
+	return f, nil
+}
+
+func (d *decoder) uint() (uint64, error) {
+	f, err := d.felt()
+	if err != nil {
+		return 0, err
+	}
+	v := f.BigInt(new(big.Int))
+	if !v.IsUint64() {
+		return 0, fmt.Errorf("value %s at position %d does not fit in 64 bits", f.String(), d.pos-1)
+	}
+	return v.Uint64(), nil
+}
+
+func (d *decoder) bool() (bool, error) {
+	v, err := d.uint()
+	return v != 0, err
+}
+
+// u256 reads the low and high 128-bit halves of a u256
+func (d *decoder) u256() (*big.Int, error) {
+	low, err := d.felt()
+	if err != nil {
+		return nil, err
+	}
+	high, err := d.felt()
+	if err != nil {
+		return nil, err
+	}
+	value := high.BigInt(new(big.Int))
+	value.Lsh(value, 128)
+	return value.Or(value, low.BigInt(new(big.Int))), nil
+}
+
+// length reads an array length, bounded by the remaining data so corrupt input cannot
+// cause huge allocations
+func (d *decoder) length() (int, error) {
+	n, err := d.uint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.felts)-d.pos) {
+		return 0, fmt.Errorf("array length %d exceeds the remaining %d values", n, len(d.felts)-d.pos)
+	}
+	return int(n), nil
+}
+
+func (d *decoder) felts252() ([]*felt.Felt, error) {
+	n, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]*felt.Felt, n)
+	for i := range values {
+		if values[i], err = d.felt(); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func (d *decoder) uints() ([]uint64, error) {
+	n, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]uint64, n)
+	for i := range values {
+		if values[i], err = d.uint(); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// bytes reads alexandria Bytes: the byte size and 16-byte big-endian words, the last one
+// padded with zeros on the right
+func (d *decoder) bytes() ([]byte, error) {
+	size, err := d.uint()
+	if err != nil {
+		return nil, err
+	}
+	words, err := d.felts252()
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(len(words))*16 {
+		return nil, fmt.Errorf("bytes size %d exceeds its %d words", size, len(words))
+	}
+
+	data := make([]byte, 0, len(words)*16)
+	for _, word := range words {
+		b := word.Bytes()
+		data = append(data, b[len(b)-16:]...)
+	}
+	return data[:size], nil
+}
+
+func (d *decoder) engraving() (Engraving, error) {
+	tag, err := d.felt()
+	if err != nil {
+		return Engraving{}, err
+	}
+	data, err := d.bytes()
+	if err != nil {
+		return Engraving{}, err
+	}
+	return Engraving{Tag: TagName(tag), Data: data}, nil
+}
+
+func (d *decoder) artifact() (Artifact, error) {
+	n, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+	artifact := make(Artifact, n)
+	for i := range artifact {
+		if artifact[i], err = d.engraving(); err != nil {
+			return nil, err
+		}
+	}
+	return artifact, nil
+}
+
+func (d *decoder) artifacts() ([]Artifact, error) {
+	n, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+	artifacts := make([]Artifact, n)
+	for i := range artifacts {
+		if artifacts[i], err = d.artifact(); err != nil {
+			return nil, err
+		}
+	}
+	return artifacts, nil
+}
+
+// done fails if values are left over, which means the result had an unexpected shape
+func (d *decoder) done() error {
+	if d.pos != len(d.felts) {
+		return fmt.Errorf("%d unexpected trailing values", len(d.felts)-d.pos)
+	}
+	return nil
+}
+
+// encodeU256 serializes a u256 as its low and high 128-bit halves
+func encodeU256(value *big.Int) []*felt.Felt {
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	low := new(big.Int).And(value, mask)
+	high := new(big.Int).Rsh(value, 128)
+	return []*felt.Felt{new(felt.Felt).SetBigInt(low), new(felt.Felt).SetBigInt(high)}
+}
+
+// encodeU256Array serializes an Array<u256>
+func encodeU256Array(values []*big.Int) []*felt.Felt {
+	calldata := []*felt.Felt{new(felt.Felt).SetUint64(uint64(len(values)))}
+	for _, v := range values {
+		calldata = append(calldata, encodeU256(v)...)
+	}
+	return calldata
+}
+
+// encodeFeltArray serializes an Array<felt252>
+func encodeFeltArray(values []*felt.Felt) []*felt.Felt {
+	calldata := []*felt.Felt{new(felt.Felt).SetUint64(uint64(len(values)))}
+	return append(calldata, values...)
+}
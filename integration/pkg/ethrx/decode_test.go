@@ -0,0 +1,195 @@
+package ethrx
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+// hexFelts builds a call result from hex values
+func hexFelts(t *testing.T, values ...string) []*felt.Felt {
+	t.Helper()
+	felts := make([]*felt.Felt, len(values))
+	for i, v := range values {
+		f, err := new(felt.Felt).SetString(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		felts[i] = f
+	}
+	return felts
+}
+
+func TestDecodeU256(t *testing.T) {
+	maxU256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	tests := []struct {
+		name   string
+		values []string
+		want   *big.Int
+	}{
+		{"low only", []string{"0x5", "0x0"}, big.NewInt(5)},
+		{"high half", []string{"0x5", "0x1"}, new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(5))},
+		{"max", []string{"0xffffffffffffffffffffffffffffffff", "0xffffffffffffffffffffffffffffffff"}, maxU256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDecoder(hexFelts(t, tt.values...))
+			got, err := d.u256()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Errorf("u256 = %s, want %s", got, tt.want)
+			}
+			if err := d.done(); err != nil {
+				t.Error(err)
+			}
+			if round := encodeU256(tt.want); !round[0].Equal(d.felts[0]) || !round[1].Equal(d.felts[1]) {
+				t.Errorf("encodeU256(%s) = %v", tt.want, round)
+			}
+		})
+	}
+
+	if _, err := newDecoder(hexFelts(t, "0x5")).u256(); err == nil {
+		t.Error("u256 decoded from a single value")
+	}
+}
+
+func TestDecodeBytes(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []byte
+	}{
+		{"empty", []string{"0x0", "0x0"}, []byte{}},
+		{"one full word", []string{"0x10", "0x1", "0x30313233343536373839616263646566"}, []byte("0123456789abcdef")},
+		{
+			"partial last word padded on the right",
+			[]string{"0x13", "0x2", "0x30313233343536373839616263646566", "0x58595a00000000000000000000000000"},
+			[]byte("0123456789abcdefXYZ"),
+		},
+		{"leading zero byte", []string{"0x2", "0x1", "0x10000000000000000000000000000"}, []byte{0x00, 0x01}},
+		{"trailing zero byte kept", []string{"0x2", "0x1", "0x41000000000000000000000000000000"}, []byte{0x41, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := hexFelts(t, tt.values...)
+			d := newDecoder(fixture)
+			got, err := d.bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("bytes = %x, want %x", got, tt.want)
+			}
+			if err := d.done(); err != nil {
+				t.Error(err)
+			}
+
+			encoded := encodeBytes(tt.want)
+			if len(encoded) != len(fixture) {
+				t.Fatalf("encodeBytes wrote %d values, want %d", len(encoded), len(fixture))
+			}
+			for i := range encoded {
+				if !encoded[i].Equal(fixture[i]) {
+					t.Errorf("encodeBytes[%d] = %s, want %s", i, encoded[i], fixture[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeBytesRejectsCorruptData(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		wantErr string
+	}{
+		{"size beyond words", []string{"0x11", "0x1", "0x1"}, "exceeds its 1 words"},
+		{"word count beyond data", []string{"0x10", "0x3", "0x1"}, "exceeds the remaining"},
+		{"huge size", []string{"0x10000000000000000", "0x0"}, "does not fit in 64 bits"},
+		{"truncated", []string{"0x10"}, "unexpected end of data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newDecoder(hexFelts(t, tt.values...)).bytes()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeArtifacts(t *testing.T) {
+	// Two artifacts: TITLE "Binary" with an empty DESC, and no engravings
+	fixture := hexFelts(t,
+		"0x2",
+		"0x2",
+		"0x5449544c45", "0x6", "0x1", "0x42696e61727900000000000000000000",
+		"0x44455343", "0x0", "0x0",
+		"0x0",
+	)
+	d := newDecoder(fixture)
+	artifacts, err := d.artifacts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.done(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(artifacts) != 2 || len(artifacts[0]) != 2 || len(artifacts[1]) != 0 {
+		t.Fatalf("artifacts = %+v", artifacts)
+	}
+	if title, _ := artifacts[0].Get("TITLE"); string(title) != "Binary" {
+		t.Errorf("TITLE = %q", title)
+	}
+	if desc, ok := artifacts[0].Get("DESC"); !ok || len(desc) != 0 {
+		t.Errorf("DESC = %q, %t", desc, ok)
+	}
+	if artifacts[0].IsEmpty() || !artifacts[1].IsEmpty() {
+		t.Error("IsEmpty does not match the engraved data")
+	}
+
+	// encodeArtifact writes the same layout as a single artifact
+	encoded, err := encodeArtifact(artifacts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range fixture[1:9] {
+		if !encoded[i].Equal(want) {
+			t.Errorf("encodeArtifact[%d] = %s, want %s", i, encoded[i], want)
+		}
+	}
+}
+
+func TestDecoderDone(t *testing.T) {
+	d := newDecoder(hexFelts(t, "0x1", "0x7", "0x8"))
+	values, err := d.felts252()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || values[0].Uint64() != 7 {
+		t.Errorf("values = %v", values)
+	}
+	if err := d.done(); err == nil || !strings.Contains(err.Error(), "1 unexpected trailing values") {
+		t.Errorf("done = %v, want the trailing value reported", err)
+	}
+
+	d = newDecoder(hexFelts(t, "0x1", "0x0"))
+	if minting, err := d.bool(); err != nil || !minting {
+		t.Errorf("bool = %t, %v", minting, err)
+	}
+	if minting, err := d.bool(); err != nil || minting {
+		t.Errorf("bool = %t, %v", minting, err)
+	}
+	if err := d.done(); err != nil {
+		t.Error(err)
+	}
+	if _, err := d.felt(); err == nil {
+		t.Error("read past the end of the data")
+	}
+}
@@ -0,0 +1,401 @@
+package ethrx
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/metrics"
+)
+
+// EventClient is the RPC access needed to read Ethrx events and the transactions that
+// emitted them; *rpc.Provider implements it
+type EventClient interface {
+	Events(ctx context.Context, input rpc.EventsInput) (*rpc.EventChunk, error)
+	TransactionByHash(ctx context.Context, hash *felt.Felt) (*rpc.BlockTransaction, error)
+	TraceTransaction(ctx context.Context, transactionHash *felt.Felt) (rpc.TxnTrace, error)
+}
+
+// EventKind identifies a decoded Ethrx event
+type EventKind string
+
+// Ethrx events, including the ones of its ERC721 and Ownable components
+const (
+	EventTransfer             EventKind = "Transfer"
+	EventArtifactEngraved     EventKind = "ArtifactEngraved"
+	EventTagRegistered        EventKind = "TagRegistered"
+	EventTagReregistered      EventKind = "TagReregistered"
+	EventOwnershipTransferred EventKind = "OwnershipTransferred"
+	EventUpgraded             EventKind = "Upgraded"
+)
+
+// Event is a decoded Ethrx event. Only the fields of its kind are set.
+type Event struct {
+	Kind        EventKind
+	BlockNumber uint64
+	TxHash      *felt.Felt
+
+	// Transfer: From is zero for mints. ArtifactEngraved: TokenID, Old and New.
+	From, To *felt.Felt
+	TokenID  *big.Int
+	Old, New Engraving
+
+	// TagRegistered: Tag. TagReregistered: OldTag and Tag.
+	Tag, OldTag string
+
+	// OwnershipTransferred: From and To. Upgraded: ClassHash.
+	ClassHash *felt.Felt
+}
+
+// ReadEvents returns the events of the given kinds (all kinds when none are given)
+// emitted by the contract at address between blocks from and to, in chain order
+func ReadEvents(ctx context.Context, client EventClient, address *felt.Felt, from, to uint64, kinds ...EventKind) ([]Event, error) {
+	if len(kinds) == 0 {
+		kinds = []EventKind{EventTransfer, EventArtifactEngraved, EventTagRegistered, EventTagReregistered, EventOwnershipTransferred, EventUpgraded}
+	}
+	selectors := make([]*felt.Felt, len(kinds))
+	for i, kind := range kinds {
+		selectors[i] = utils.GetSelectorFromNameFelt(string(kind))
+	}
+
+	input := rpc.EventsInput{
+		EventFilter: rpc.EventFilter{
+			FromBlock: rpc.WithBlockNumber(from),
+			ToBlock:   rpc.WithBlockNumber(to),
+			Address:   address,
+			Keys:      [][]*felt.Felt{selectors},
+		},
+		ResultPageRequest: rpc.ResultPageRequest{ChunkSize: 1000},
+	}
+
+	var events []Event
+	for {
+		chunk, err := client.Events(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read events of blocks %d-%d: %w", from, to, err)
+		}
+		for _, emitted := range chunk.Events {
+			event, err := decodeEvent(emitted)
+			if err != nil {
+				return nil, fmt.Errorf("block %d, transaction %s: %w", emitted.BlockNumber, emitted.TransactionHash.String(), err)
+			}
+			if event != nil {
+				events = append(events, *event)
+			}
+		}
+		if chunk.ContinuationToken == "" {
+			return events, nil
+		}
+		input.ContinuationToken = chunk.ContinuationToken
+	}
+}
+
+// decodeEvent decodes an emitted event, or returns nil for events it does not know
+func decodeEvent(emitted rpc.EmittedEvent) (*Event, error) {
+	if len(emitted.Keys) == 0 {
+		return nil, nil
+	}
+	event := &Event{BlockNumber: emitted.BlockNumber, TxHash: emitted.TransactionHash}
+	keys := newDecoder(emitted.Keys[1:])
+	data := newDecoder(emitted.Data)
+
+	var err error
+	switch selector := emitted.Keys[0]; {
+	case selector.Equal(utils.GetSelectorFromNameFelt(string(EventTransfer))):
+		event.Kind = EventTransfer
+		if event.From, err = keys.felt(); err == nil {
+			if event.To, err = keys.felt(); err == nil {
+				event.TokenID, err = keys.u256()
+			}
+		}
+	case selector.Equal(utils.GetSelectorFromNameFelt(string(EventArtifactEngraved))):
+		event.Kind = EventArtifactEngraved
+		if event.TokenID, err = data.u256(); err == nil {
+			if event.Old, err = data.engraving(); err == nil {
+				event.New, err = data.engraving()
+			}
+		}
+	case selector.Equal(utils.GetSelectorFromNameFelt(string(EventTagRegistered))):
+		event.Kind = EventTagRegistered
+		var tag *felt.Felt
+		if tag, err = data.felt(); err == nil {
+			event.Tag = TagName(tag)
+		}
+	case selector.Equal(utils.GetSelectorFromNameFelt(string(EventTagReregistered))):
+		event.Kind = EventTagReregistered
+		var oldTag, newTag *felt.Felt
+		if oldTag, err = data.felt(); err == nil {
+			if newTag, err = data.felt(); err == nil {
+				event.OldTag, event.Tag = TagName(oldTag), TagName(newTag)
+			}
+		}
+	case selector.Equal(utils.GetSelectorFromNameFelt(string(EventOwnershipTransferred))):
+		event.Kind = EventOwnershipTransferred
+		if event.From, err = keys.felt(); err == nil {
+			event.To, err = keys.felt()
+		}
+	case selector.Equal(utils.GetSelectorFromNameFelt(string(EventUpgraded))):
+		event.Kind = EventUpgraded
+		event.ClassHash, err = data.felt()
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s event: %w", event.Kind, err)
+	}
+	return event, nil
+}
+
+// TxInfo is what the Ethrx history needs to know about a transaction
+type TxInfo struct {
+	// Sender is the account that sent the transaction, nil for non-invoke transactions
+	Sender *felt.Felt
+	// SavedTokens holds the token IDs passed to transfer_and_save_artifact
+	SavedTokens map[string]bool
+	// Selectors holds the selectors of the entrypoints the transaction called on the contract
+	Selectors []*felt.Felt
+	// Complete is set when SavedTokens and Selectors hold every call the transaction made
+	// to the contract. Calls decoded from the calldata are complete only when they all go
+	// straight to the contract: another contract, such as a multisig or an account running
+	// an outside execution, may call it in turn, which only the trace shows.
+	Complete bool
+}
+
+// Calls reports whether the transaction called entrypoint on the contract
+func (i *TxInfo) Calls(entrypoint string) bool {
+	selector := utils.GetSelectorFromNameFelt(entrypoint)
+	for _, s := range i.Selectors {
+		if s.Equal(selector) {
+			return true
+		}
+	}
+	return false
+}
+
+// add records a call the transaction made to the contract
+func (i *TxInfo) add(selector *felt.Felt, calldata []*felt.Felt) error {
+	i.Selectors = append(i.Selectors, selector)
+	if !selector.Equal(utils.GetSelectorFromNameFelt("transfer_and_save_artifact")) {
+		return nil
+	}
+	ids, err := savedTokenIDs(calldata)
+	if err != nil {
+		return fmt.Errorf("invalid transfer_and_save_artifact calldata: %w", err)
+	}
+	for _, id := range ids {
+		i.SavedTokens[id.String()] = true
+	}
+	return nil
+}
+
+// Transactions looks up and caches the transactions behind Ethrx events
+type Transactions struct {
+	client  EventClient
+	address *felt.Felt
+	metrics *metrics.Metrics
+
+	mu     sync.Mutex
+	cache  map[string]*TxInfo
+	traces map[string]*TxInfo
+}
+
+// NewTransactions creates a transaction cache for the Ethrx contract at address
+func NewTransactions(client EventClient, address *felt.Felt) *Transactions {
+	return &Transactions{
+		client:  client,
+		address: address,
+		cache:   make(map[string]*TxInfo),
+		traces:  make(map[string]*TxInfo),
+	}
+}
+
+// WithMetrics records the cache hit rates in m as the "transactions" and "traces" caches
+func (t *Transactions) WithMetrics(m *metrics.Metrics) *Transactions {
+	t.metrics = m
+	return t
+}
+
+// Get returns the sender, saved tokens and contract calls of a transaction, decoded from
+// its calldata
+func (t *Transactions) Get(ctx context.Context, txHash *felt.Felt) (*TxInfo, error) {
+	t.mu.Lock()
+	info, ok := t.cache[txHash.String()]
+	t.mu.Unlock()
+	t.metrics.CacheLookup("transactions", ok)
+	if ok {
+		return info, nil
+	}
+
+	tx, err := t.client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction %s: %w", txHash.String(), err)
+	}
+
+	info = &TxInfo{SavedTokens: make(map[string]bool)}
+	var calldata []*felt.Felt
+	switch invoke := tx.Transaction.(type) {
+	case rpc.InvokeTxnV3:
+		info.Sender, calldata = invoke.SenderAddress, invoke.Calldata
+	case rpc.InvokeTxnV1:
+		info.Sender, calldata = invoke.SenderAddress, invoke.Calldata
+	}
+
+	calls, ok := decodeExecuteCalls(calldata)
+	info.Complete = ok
+	for _, call := range calls {
+		if !call.to.Equal(t.address) {
+			info.Complete = false
+			continue
+		}
+		if err := info.add(call.selector, call.calldata); err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", txHash.String(), err)
+		}
+	}
+
+	t.mu.Lock()
+	t.cache[txHash.String()] = info
+	t.mu.Unlock()
+	return info, nil
+}
+
+// Trace returns the sender, saved tokens and contract calls of a transaction at any call
+// depth, read from its execution trace. Calls that reverted are left out.
+func (t *Transactions) Trace(ctx context.Context, txHash *felt.Felt) (*TxInfo, error) {
+	t.mu.Lock()
+	info, ok := t.traces[txHash.String()]
+	t.mu.Unlock()
+	t.metrics.CacheLookup("traces", ok)
+	if ok {
+		return info, nil
+	}
+
+	direct, err := t.Get(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	trace, err := t.client.TraceTransaction(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace transaction %s: %w", txHash.String(), err)
+	}
+
+	var root *rpc.FnInvocation
+	switch trace := trace.(type) {
+	case rpc.InvokeTxnTrace:
+		root = trace.ExecuteInvocation.FnInvocation
+	case rpc.L1HandlerTxnTrace:
+		root = &trace.FunctionInvocation
+	}
+	info = &TxInfo{Sender: direct.Sender, SavedTokens: make(map[string]bool), Complete: true}
+	if root != nil {
+		if err := t.addInvocation(info, root); err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", txHash.String(), err)
+		}
+	}
+
+	t.mu.Lock()
+	t.traces[txHash.String()] = info
+	t.mu.Unlock()
+	return info, nil
+}
+
+// addInvocation records the calls to the contract in an invocation tree
+func (t *Transactions) addInvocation(info *TxInfo, call *rpc.FnInvocation) error {
+	if call.IsReverted {
+		return nil
+	}
+	if call.ContractAddress != nil && call.ContractAddress.Equal(t.address) {
+		if err := info.add(call.EntryPointSelector, call.Calldata); err != nil {
+			return err
+		}
+	}
+	for i := range call.NestedCalls {
+		if err := t.addInvocation(info, &call.NestedCalls[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Wipes reports whether a Transfer event wiped the token's artifact, which every
+// transfer does except the ones made through transfer_and_save_artifact. When the
+// calldata does not show every call to the contract, the transaction is traced, and an
+// error is returned if the node cannot trace it rather than guessing.
+func (t *Transactions) Wipes(ctx context.Context, transfer Event) (bool, error) {
+	if transfer.From.IsZero() {
+		return true, nil
+	}
+	info, err := t.Get(ctx, transfer.TxHash)
+	if err != nil {
+		return false, err
+	}
+	if !info.Complete && !info.SavedTokens[transfer.TokenID.String()] {
+		if info, err = t.Trace(ctx, transfer.TxHash); err != nil {
+			return false, fmt.Errorf("token %s: cannot tell whether its transfer kept the artifact: %w", transfer.TokenID, err)
+		}
+	}
+	return !info.SavedTokens[transfer.TokenID.String()], nil
+}
+
+// executeCall is one call of an account's __execute__ calldata
+type executeCall struct {
+	to, selector *felt.Felt
+	calldata     []*felt.Felt
+}
+
+// decodeExecuteCalls decodes the calls of a Cairo 1 account's __execute__ calldata:
+// the number of calls, then per call the contract, selector and calldata array.
+// It reports false for calldata in another layout.
+func decodeExecuteCalls(calldata []*felt.Felt) ([]executeCall, bool) {
+	d := newDecoder(calldata)
+	n, err := d.length()
+	if err != nil {
+		return nil, false
+	}
+	calls := make([]executeCall, 0, n)
+	for i := 0; i < n; i++ {
+		var call executeCall
+		if call.to, err = d.felt(); err != nil {
+			return nil, false
+		}
+		if call.selector, err = d.felt(); err != nil {
+			return nil, false
+		}
+		if call.calldata, err = d.felts252(); err != nil {
+			return nil, false
+		}
+		calls = append(calls, call)
+	}
+	if d.done() != nil {
+		return nil, false
+	}
+	return calls, true
+}
+
+// savedTokenIDs returns the token_ids argument of transfer_and_save_artifact calldata
+func savedTokenIDs(calldata []*felt.Felt) ([]*big.Int, error) {
+	d := newDecoder(calldata)
+	if _, err := d.felts252(); err != nil { // froms
+		return nil, err
+	}
+	if _, err := d.felts252(); err != nil { // tos
+		return nil, err
+	}
+	n, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]*big.Int, 0, n)
+	for i := 0; i < n; i++ {
+		id, err := d.u256()
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, d.done()
+}
@@ -0,0 +1,131 @@
+package ethrx
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+)
+
+func TestWipes(t *testing.T) {
+	alice, bob := new(felt.Felt).SetUint64(0xa), new(felt.Felt).SetUint64(0xb)
+	signer, multisig := new(felt.Felt).SetUint64(0x516), new(felt.Felt).SetUint64(0x3a1)
+	tx := new(felt.Felt).SetUint64(0x7)
+
+	tests := []struct {
+		name    string
+		send    func(chain *prunedChain)
+		from    *felt.Felt
+		want    bool
+		wantErr string
+	}{
+		{
+			name: "mint",
+			send: func(chain *prunedChain) { chain.mint(1, tx, alice, 1) },
+			from: &felt.Zero,
+			want: true,
+		},
+		{
+			name: "transfer",
+			send: func(chain *prunedChain) { chain.transfer(1, tx, alice, bob, 1, false) },
+			from: alice,
+			want: true,
+		},
+		{
+			name: "transfer_and_save_artifact",
+			send: func(chain *prunedChain) { chain.transfer(1, tx, alice, bob, 1, true) },
+			from: alice,
+			want: false,
+		},
+		{
+			name: "transfer_and_save_artifact through a multisig",
+			send: func(chain *prunedChain) { chain.routedTransfer(1, tx, signer, multisig, bob, 1, true) },
+			from: multisig,
+			want: false,
+		},
+		{
+			name: "transfer through a multisig",
+			send: func(chain *prunedChain) { chain.routedTransfer(1, tx, signer, multisig, bob, 1, false) },
+			from: multisig,
+			want: true,
+		},
+		{
+			name: "routed without a trace",
+			send: func(chain *prunedChain) {
+				chain.routedTransfer(1, tx, signer, multisig, bob, 1, true)
+				delete(chain.traces, tx.String())
+			},
+			from:    multisig,
+			wantErr: "cannot tell whether its transfer kept the artifact",
+		},
+		{
+			name: "undecodable calldata",
+			send: func(chain *prunedChain) {
+				chain.transfer(1, tx, alice, bob, 1, true)
+				chain.txs[tx.String()] = rpc.InvokeTxnV3{SenderAddress: alice, Calldata: []*felt.Felt{new(felt.Felt).SetUint64(3)}}
+			},
+			from:    alice,
+			wantErr: "cannot tell whether its transfer kept the artifact",
+		},
+		{
+			name: "corrupt transfer_and_save_artifact calldata",
+			send: func(chain *prunedChain) {
+				chain.txs[tx.String()] = rpc.InvokeTxnV3{SenderAddress: alice, Calldata: []*felt.Felt{
+					new(felt.Felt).SetUint64(1), chain.contract, utils.GetSelectorFromNameFelt("transfer_and_save_artifact"),
+					new(felt.Felt).SetUint64(1), new(felt.Felt).SetUint64(5),
+				}}
+			},
+			from:    alice,
+			wantErr: "invalid transfer_and_save_artifact calldata",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newPrunedChain()
+			tt.send(chain)
+
+			transfer := Event{Kind: EventTransfer, TxHash: tx, From: tt.from, To: bob, TokenID: big.NewInt(1)}
+			wipes, err := NewTransactions(chain, chain.contract).Wipes(t.Context(), transfer)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wipes != tt.want {
+				t.Errorf("wipes = %t, want %t", wipes, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransactionsTraceSkipsRevertedCalls(t *testing.T) {
+	chain := newPrunedChain()
+	tx := new(felt.Felt).SetUint64(0x7)
+	save := []*felt.Felt{new(felt.Felt).SetUint64(0), new(felt.Felt).SetUint64(0), new(felt.Felt).SetUint64(1), new(felt.Felt).SetUint64(1), &felt.Zero}
+	chain.txs[tx.String()] = rpc.InvokeTxnV3{SenderAddress: new(felt.Felt).SetUint64(0xa), Calldata: []*felt.Felt{&felt.Zero}}
+	chain.traces[tx.String()] = rpc.InvokeTxnTrace{ExecuteInvocation: rpc.ExecInvocation{FnInvocation: &rpc.FnInvocation{
+		NestedCalls: []rpc.FnInvocation{
+			{
+				FunctionCall: rpc.FunctionCall{ContractAddress: chain.contract, EntryPointSelector: utils.GetSelectorFromNameFelt("transfer_and_save_artifact"), Calldata: save},
+				IsReverted:   true,
+			},
+			{FunctionCall: rpc.FunctionCall{ContractAddress: chain.contract, EntryPointSelector: utils.GetSelectorFromNameFelt("transfer_from")}},
+		},
+	}}}
+
+	info, err := NewTransactions(chain, chain.contract).Trace(t.Context(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.SavedTokens) != 0 || !info.Calls("transfer_from") || info.Calls("transfer_and_save_artifact") || !info.Complete {
+		t.Errorf("info = %+v, want only the transfer_from call", info)
+	}
+}
@@ -0,0 +1,172 @@
+package ethrx
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Export file names written by WriteExport
+const (
+	SnapshotFile = "snapshot.json"
+	CSVFile      = "tokens.csv"
+	NDJSONFile   = "tokens.ndjson"
+	ManifestFile = "manifest.json"
+)
+
+// Manifest describes an export: the pinned block, the contract class and the SHA-256 of
+// every written file, so an archived export can be verified later
+type Manifest struct {
+	Network        string            `json:"network,omitempty"`
+	Contract       string            `json:"contract"`
+	ClassHash      string            `json:"class_hash"`
+	BlockNumber    uint64            `json:"block_number"`
+	BlockHash      string            `json:"block_hash"`
+	BlockTimestamp time.Time         `json:"block_timestamp"`
+	TotalSupply    string            `json:"total_supply"`
+	Tokens         int               `json:"tokens"`
+	OfficialTags   []string          `json:"official_tags"`
+	Files          map[string]string `json:"files"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// WriteExport writes the snapshot to dir as JSON, CSV and NDJSON, followed by the manifest
+func WriteExport(dir, network string, snapshot *Snapshot) (*Manifest, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	manifest := &Manifest{
+		Network:        network,
+		Contract:       snapshot.Contract,
+		ClassHash:      snapshot.ClassHash,
+		BlockNumber:    snapshot.BlockNumber,
+		BlockHash:      snapshot.BlockHash,
+		BlockTimestamp: snapshot.BlockTimestamp,
+		TotalSupply:    snapshot.TotalSupply,
+		Tokens:         len(snapshot.Tokens),
+		OfficialTags:   snapshot.OfficialTags,
+		Files:          make(map[string]string),
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	writers := []struct {
+		name  string
+		write func(io.Writer, *Snapshot) error
+	}{
+		{SnapshotFile, writeSnapshotJSON},
+		{CSVFile, writeCSV},
+		{NDJSONFile, writeNDJSON},
+	}
+	for _, w := range writers {
+		sum, err := writeFile(filepath.Join(dir, w.name), func(out io.Writer) error { return w.write(out, snapshot) })
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files[w.name] = sum
+	}
+
+	_, err := writeFile(filepath.Join(dir, ManifestFile), func(out io.Writer) error {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(manifest)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// LoadSnapshot reads a snapshot written by WriteExport
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", path, err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	return &snapshot, nil
+}
+
+// writeFile writes path through write and returns the SHA-256 of its content
+func writeFile(path string, write func(io.Writer) error) (string, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	buffered := bufio.NewWriter(io.MultiWriter(file, hash))
+	if err := write(buffered); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func writeSnapshotJSON(out io.Writer, snapshot *Snapshot) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+// writeNDJSON writes one token per line
+func writeNDJSON(out io.Writer, snapshot *Snapshot) error {
+	encoder := json.NewEncoder(out)
+	for _, token := range snapshot.Tokens {
+		if err := encoder.Encode(token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes one row per token with a data and a nonce column per official tag.
+// Data that is not valid UTF-8 is written as hex.
+func writeCSV(out io.Writer, snapshot *Snapshot) error {
+	w := csv.NewWriter(out)
+
+	header := []string{"token_id", "owner", "artifact_id"}
+	for _, tag := range snapshot.OfficialTags {
+		header = append(header, tag, tag+"_nonce")
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, token := range snapshot.Tokens {
+		row := []string{token.TokenID, token.Owner, token.ArtifactID}
+		for _, tag := range snapshot.OfficialTags {
+			var value string
+			if data, ok := token.Artifact.Get(tag); ok {
+				value = Engraving{Tag: tag, Data: data}.Text()
+				if value == "" && len(data) > 0 {
+					value = "0x" + hex.EncodeToString(data)
+				}
+			}
+			row = append(row, value, strconv.FormatUint(token.TagNonces[tag], 10))
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,164 @@
+package ethrx
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// exportSnapshot has text, quoted text and binary engravings
+func exportSnapshot() *Snapshot {
+	return &Snapshot{
+		Contract:       "0xe7",
+		ClassHash:      "0xc1a55",
+		BlockNumber:    812345,
+		BlockHash:      "0xb10c",
+		BlockTimestamp: time.Unix(1760000000, 0).UTC(),
+		Owner:          "0xa",
+		Version:        2,
+		IsMinting:      true,
+		MintToken:      "0x4718",
+		MintPrice:      "1000",
+		MaxSupply:      "1111",
+		TotalSupply:    "2",
+		TotalArtifacts: "2",
+		OfficialTags:   []string{"TITLE", "DATA"},
+		Tokens: []Token{
+			{
+				TokenID: "1", Owner: "0xa", ArtifactID: "1001",
+				TagNonces: map[string]uint64{"TITLE": 2},
+				Artifact:  Artifact{{Tag: "TITLE", Data: []byte("Binary")}},
+			},
+			{
+				TokenID: "2", Owner: "0xb", ArtifactID: "1002",
+				TagNonces: map[string]uint64{"TITLE": 1, "DATA": 3},
+				Artifact: Artifact{
+					{Tag: "TITLE", Data: []byte(`Hello, "world"`)},
+					{Tag: "DATA", Data: []byte{0xff, 0x00}},
+				},
+			},
+		},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeCSV(&out, exportSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"token_id,owner,artifact_id,TITLE,TITLE_nonce,DATA,DATA_nonce",
+		"1,0xa,1001,Binary,2,,0",
+		`2,0xb,1002,"Hello, ""world""",1,0xff00,3`,
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	snapshot := exportSnapshot()
+	var out bytes.Buffer
+	if err := writeNDJSON(&out, snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	// Binary data keeps its exact bytes in data_hex, with empty text
+	if !strings.Contains(out.String(), `{"tag":"DATA","data":"","data_hex":"0xff00"}`) {
+		t.Errorf("NDJSON does not hold the binary engraving:\n%s", out.String())
+	}
+
+	scanner := bufio.NewScanner(&out)
+	var lines int
+	for scanner.Scan() {
+		var token Token
+		if err := json.Unmarshal(scanner.Bytes(), &token); err != nil {
+			t.Fatalf("line %d: %v", lines+1, err)
+		}
+		if !reflect.DeepEqual(token, snapshot.Tokens[lines]) {
+			t.Errorf("line %d = %+v, want %+v", lines+1, token, snapshot.Tokens[lines])
+		}
+		lines++
+	}
+	if lines != len(snapshot.Tokens) {
+		t.Errorf("lines = %d, want %d", lines, len(snapshot.Tokens))
+	}
+}
+
+func TestWriteExportRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mainnet-812345")
+	snapshot := exportSnapshot()
+
+	manifest, err := WriteExport(dir, "mainnet", snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Network != "mainnet" || manifest.BlockNumber != 812345 || manifest.Tokens != 2 || manifest.TotalSupply != "2" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	// Every file is listed with its SHA-256
+	for _, name := range []string{SnapshotFile, CSVFile, NDJSONFile} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256(data)
+		if got := manifest.Files[name]; got != hex.EncodeToString(sum[:]) {
+			t.Errorf("%s: manifest hash %s does not match the file", name, got)
+		}
+	}
+	if len(manifest.Files) != 3 {
+		t.Errorf("manifest files = %v", manifest.Files)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written Manifest
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(written.Files, manifest.Files) || !written.BlockTimestamp.Equal(snapshot.BlockTimestamp) {
+		t.Errorf("manifest.json = %+v", written)
+	}
+
+	loaded, err := LoadSnapshot(filepath.Join(dir, SnapshotFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.BlockTimestamp.Equal(snapshot.BlockTimestamp) {
+		t.Errorf("block timestamp = %s, want %s", loaded.BlockTimestamp, snapshot.BlockTimestamp)
+	}
+	loaded.BlockTimestamp = snapshot.BlockTimestamp
+	if !reflect.DeepEqual(loaded, snapshot) {
+		t.Errorf("loaded snapshot = %+v\nwant %+v", loaded, snapshot)
+	}
+}
+
+func TestLoadSnapshotErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadSnapshot(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing snapshot loaded")
+	}
+
+	path := filepath.Join(dir, "corrupt.json")
+	corrupt := `{"tokens": [{"token_id": "1", "artifact": [{"tag": "DATA", "data_hex": "0xzz"}]}]}`
+	if err := os.WriteFile(path, []byte(corrupt), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSnapshot(path); err == nil || !strings.Contains(err.Error(), "invalid data_hex for tag DATA") {
+		t.Errorf("err = %v, want the corrupt data reported", err)
+	}
+}
@@ -0,0 +1,265 @@
+package ethrx
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/metrics"
+)
+
+// Kinds of token history entries
+const (
+	// ChangeMint is the mint of the token, which starts its first artifact
+	ChangeMint = "mint"
+	// ChangeWipe is a plain transfer, which starts a new, empty artifact
+	ChangeWipe = "wipe"
+	// ChangeTransfer is a transfer through transfer_and_save_artifact, which keeps the artifact
+	ChangeTransfer = "transfer"
+	// ChangeEngrave is an engraving of one tag
+	ChangeEngrave = "engrave"
+)
+
+// HistoryEntry is one change to a token's owner or artifact
+type HistoryEntry struct {
+	Kind        string    `json:"kind"`
+	BlockNumber uint64    `json:"block_number"`
+	Timestamp   time.Time `json:"timestamp"`
+	TxHash      string    `json:"tx_hash"`
+	// Caller is the account that sent the transaction
+	Caller string `json:"caller,omitempty"`
+
+	// Mints, wipes and transfers
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	// Engravings: the data before and after, and a line diff between them
+	Tag  string     `json:"tag,omitempty"`
+	Old  *Engraving `json:"old,omitempty"`
+	New  *Engraving `json:"new,omitempty"`
+	Diff string     `json:"diff,omitempty"`
+}
+
+// TokenHistory is the audit trail of one token over a range of blocks
+type TokenHistory struct {
+	Contract   string         `json:"contract"`
+	TokenID    string         `json:"token_id"`
+	FromBlock  uint64         `json:"from_block"`
+	ToBlock    uint64         `json:"to_block"`
+	Engravings int            `json:"engravings"`
+	Wipes      int            `json:"wipes"`
+	Entries    []HistoryEntry `json:"entries"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// HistoryOptions controls the block range of a token history
+type HistoryOptions struct {
+	// FromBlock is the first block scanned, such as the contract deployment block (default 0)
+	FromBlock uint64
+	// ToBlock is the last block scanned; zero scans up to the latest block
+	ToBlock uint64
+	// Workers is the number of concurrent transaction and block lookups (default 8)
+	Workers int
+	// Logger receives progress messages; nil logs nothing
+	Logger *logrus.Logger
+	// Metrics records cache lookups; nil records nothing
+	Metrics *metrics.Metrics
+}
+
+// ReadTokenHistory returns every mint, transfer and engraving of a token between
+// opts.FromBlock and opts.ToBlock, in chain order. Engravings made before the token's
+// initial mint in the constructor emit no events and are not listed.
+func ReadTokenHistory(ctx context.Context, client HistoryClient, address *felt.Felt, tokenID *big.Int, opts HistoryOptions) (*TokenHistory, error) {
+	if opts.Logger == nil {
+		opts.Logger = logrus.New()
+		opts.Logger.SetOutput(io.Discard)
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 8
+	}
+	if opts.ToBlock == 0 {
+		latest, err := client.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the latest block: %w", err)
+		}
+		opts.ToBlock = latest
+	}
+	if opts.FromBlock > opts.ToBlock {
+		return nil, fmt.Errorf("from block %d is after to block %d", opts.FromBlock, opts.ToBlock)
+	}
+
+	opts.Logger.Infof("🔎 Reading events of blocks %d-%d", opts.FromBlock, opts.ToBlock)
+	events, err := ReadEvents(ctx, client, address, opts.FromBlock, opts.ToBlock, EventTransfer, EventArtifactEngraved)
+	if err != nil {
+		return nil, err
+	}
+	var own []Event
+	for _, event := range events {
+		if event.TokenID.Cmp(tokenID) == 0 {
+			own = append(own, event)
+		}
+	}
+
+	// Senders, saved transfers and timestamps are looked up once per transaction and block
+	txs := NewTransactions(client, address).WithMetrics(opts.Metrics)
+	infos := make([]*TxInfo, len(own))
+	wiped := make([]bool, len(own))
+	err = forEach(ctx, len(own), opts.Workers, func(ctx context.Context, i int) error {
+		info, err := txs.Get(ctx, own[i].TxHash)
+		if err != nil {
+			return err
+		}
+		infos[i] = info
+		if own[i].Kind == EventTransfer {
+			wiped[i], err = txs.Wipes(ctx, own[i])
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	timestamps, err := blockTimestamps(ctx, client, own, opts.Workers)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &TokenHistory{
+		Contract:  address.String(),
+		TokenID:   tokenID.String(),
+		FromBlock: opts.FromBlock,
+		ToBlock:   opts.ToBlock,
+		Entries:   make([]HistoryEntry, len(own)),
+		CreatedAt: time.Now().UTC(),
+	}
+	for i, event := range own {
+		entry := HistoryEntry{
+			BlockNumber: event.BlockNumber,
+			Timestamp:   timestamps[event.BlockNumber],
+			TxHash:      event.TxHash.String(),
+		}
+		if infos[i].Sender != nil {
+			entry.Caller = infos[i].Sender.String()
+		}
+
+		switch {
+		case event.Kind == EventArtifactEngraved:
+			old, updated := event.Old, event.New
+			entry.Kind, entry.Tag = ChangeEngrave, updated.Tag
+			entry.Old, entry.New = &old, &updated
+			entry.Diff = TextDiff(old.Data, updated.Data)
+			history.Engravings++
+		case event.From.IsZero():
+			entry.Kind, entry.To = ChangeMint, event.To.String()
+		case !wiped[i]:
+			entry.Kind, entry.From, entry.To = ChangeTransfer, event.From.String(), event.To.String()
+		default:
+			entry.Kind, entry.From, entry.To = ChangeWipe, event.From.String(), event.To.String()
+			history.Wipes++
+		}
+		history.Entries[i] = entry
+	}
+	return history, nil
+}
+
+// blockTimestamps returns the timestamp of each block the events were emitted in
+func blockTimestamps(ctx context.Context, client SnapshotClient, events []Event, workers int) (map[uint64]time.Time, error) {
+	var blocks []uint64
+	seen := make(map[uint64]bool)
+	for _, event := range events {
+		if !seen[event.BlockNumber] {
+			seen[event.BlockNumber] = true
+			blocks = append(blocks, event.BlockNumber)
+		}
+	}
+
+	headers := make([]time.Time, len(blocks))
+	err := forEach(ctx, len(blocks), workers, func(ctx context.Context, i int) error {
+		header, err := blockHeader(ctx, client, blocks[i])
+		if err != nil {
+			return err
+		}
+		headers[i] = time.Unix(int64(header.Timestamp), 0).UTC()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	timestamps := make(map[uint64]time.Time, len(blocks))
+	for i, block := range blocks {
+		timestamps[block] = headers[i]
+	}
+	return timestamps, nil
+}
+
+// WriteTokenHistory writes a token history as indented JSON
+func WriteTokenHistory(path string, history *TokenHistory) error {
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode token history: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write token history %s: %w", path, err)
+	}
+	return nil
+}
+
+// TextDiff returns a line diff from before to after: removed lines start with "- ", added
+// lines with "+ " and unchanged lines with "  ". Data that is not valid UTF-8 is
+// compared as hex.
+func TextDiff(before, after []byte) string {
+	oldLines, newLines := diffLines(before), diffLines(after)
+
+	// Longest common subsequence of the lines, from the end
+	common := make([][]int, len(oldLines)+1)
+	for i := range common {
+		common[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var diff strings.Builder
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			diff.WriteString("  " + oldLines[i] + "\n")
+			i, j = i+1, j+1
+		case j == len(newLines) || (i < len(oldLines) && common[i+1][j] >= common[i][j+1]):
+			diff.WriteString("- " + oldLines[i] + "\n")
+			i++
+		default:
+			diff.WriteString("+ " + newLines[j] + "\n")
+			j++
+		}
+	}
+	return diff.String()
+}
+
+// diffLines splits data into lines; empty data has no lines
+func diffLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	if !utf8.Valid(data) {
+		return []string{"0x" + hex.EncodeToString(data)}
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
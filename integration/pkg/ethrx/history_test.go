@@ -0,0 +1,76 @@
+package ethrx
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+func TestReadTokenHistory(t *testing.T) {
+	alice, bob := new(felt.Felt).SetUint64(0xa), new(felt.Felt).SetUint64(0xb)
+	tx := func(n uint64) *felt.Felt { return new(felt.Felt).SetUint64(n) }
+
+	chain := newPrunedChain()
+	chain.transfer(1, tx(1), &felt.Zero, alice, 1, false)
+	chain.transfer(1, tx(1), &felt.Zero, alice, 2, false)
+	chain.engrave(10, tx(2), 1, "TITLE", "first")
+	chain.transfer(20, tx(3), alice, bob, 1, false)
+	chain.transfer(25, tx(4), alice, bob, 2, true)
+	chain.engrave(30, tx(5), 1, "TITLE", "second")
+	chain.latest = 40
+
+	for _, tc := range []struct {
+		token int64
+		kinds []string
+		wipes int
+	}{
+		{1, []string{ChangeMint, ChangeEngrave, ChangeWipe, ChangeEngrave}, 1},
+		{2, []string{ChangeMint, ChangeTransfer}, 0},
+	} {
+		history, err := ReadTokenHistory(t.Context(), chain, chain.contract, big.NewInt(tc.token), HistoryOptions{})
+		if err != nil {
+			t.Fatalf("token %d: %v", tc.token, err)
+		}
+		if len(history.Entries) != len(tc.kinds) || history.Wipes != tc.wipes || history.ToBlock != 40 {
+			t.Fatalf("token %d: got %+v", tc.token, history)
+		}
+		for i, entry := range history.Entries {
+			if entry.Kind != tc.kinds[i] {
+				t.Errorf("token %d entry %d: kind %s, want %s", tc.token, i, entry.Kind, tc.kinds[i])
+			}
+			if entry.Timestamp.Unix() != int64(1700000000+entry.BlockNumber) {
+				t.Errorf("token %d entry %d: timestamp %s for block %d", tc.token, i, entry.Timestamp, entry.BlockNumber)
+			}
+		}
+	}
+
+	history, err := ReadTokenHistory(t.Context(), chain, chain.contract, big.NewInt(1), HistoryOptions{FromBlock: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	engraving := history.Entries[0]
+	if engraving.Kind != ChangeEngrave || engraving.Tag != "TITLE" || engraving.Caller != alice.String() || engraving.Diff != "+ first\n" {
+		t.Errorf("engraving = %+v", engraving)
+	}
+	if second := history.Entries[2]; second.Caller != bob.String() || second.Diff != "+ second\n" {
+		t.Errorf("engraving after the wipe = %+v", second)
+	}
+}
+
+func TestTextDiff(t *testing.T) {
+	for _, tc := range []struct {
+		old, new string
+		want     string
+	}{
+		{"", "", ""},
+		{"", "Binary", "+ Binary\n"},
+		{"Binary", "", "- Binary\n"},
+		{"a\nb\nc", "a\nx\nc\nd", "  a\n- b\n+ x\n  c\n+ d\n"},
+		{"\xff", "ok", "- 0xff\n+ ok\n"},
+	} {
+		if got := TextDiff([]byte(tc.old), []byte(tc.new)); got != tc.want {
+			t.Errorf("TextDiff(%q, %q) = %q, want %q", tc.old, tc.new, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,641 @@
+package ethrx
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/abi"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+)
+
+// Transactor submits and waits for invoke transactions; *deploy.Deployer implements it
+type Transactor interface {
+	Invoke(ctx context.Context, calls []rpc.InvokeFunctionCall) (*felt.Felt, error)
+	// InvokeBatch sends one transaction per batch, pipelined when nonces are tracked locally
+	InvokeBatch(ctx context.Context, batches [][]rpc.InvokeFunctionCall) ([]deploy.BatchResult, error)
+	WaitForReceipt(ctx context.Context, txHash *felt.Felt) (*rpc.TransactionReceiptWithBlockInfo, error)
+	GetAccountAddress() string
+}
+
+// MigrateOptions controls how a snapshot is replayed into a new contract
+type MigrateOptions struct {
+	// ABI is the Ethrx contract ABI the migration calls are encoded with (required)
+	ABI *abi.ABI
+	// BatchSize is the number of tokens per transaction (default 25)
+	BatchSize int
+	// TransferOwnership hands the new contract to the snapshot owner once the tokens are in place
+	TransferOwnership bool
+	// Read controls how the new contract is read for planning and verification
+	Read SnapshotOptions
+}
+
+// MigrationTx is a transaction sent during a migration
+type MigrationTx struct {
+	Step   string `json:"step"`
+	Hash   string `json:"hash"`
+	Tokens int    `json:"tokens,omitempty"`
+}
+
+// TokenCheck is the verification result of one token
+type TokenCheck struct {
+	TokenID     string   `json:"token_id"`
+	Status      string   `json:"status"`
+	Owner       string   `json:"owner"`
+	Differences []string `json:"differences,omitempty"`
+}
+
+// Token check statuses
+const (
+	StatusOK       = "ok"
+	StatusMismatch = "mismatch"
+)
+
+// Reconciliation compares a migrated contract with the snapshot it was migrated from
+type Reconciliation struct {
+	Source      string `json:"source"`
+	SourceBlock uint64 `json:"source_block"`
+	Target      string `json:"target"`
+	TargetBlock uint64 `json:"target_block"`
+
+	Tokens     int `json:"tokens"`
+	Matched    int `json:"matched"`
+	Mismatched int `json:"mismatched"`
+
+	// Settings lists contract settings that differ from the snapshot
+	Settings     []string      `json:"setting_differences,omitempty"`
+	Checks       []TokenCheck  `json:"checks"`
+	Transactions []MigrationTx `json:"transactions,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// OK reports whether every token and setting matches the snapshot
+func (r *Reconciliation) OK() bool {
+	return r.Mismatched == 0 && len(r.Settings) == 0
+}
+
+// Migrate replays source into the Ethrx contract at target, which must be owned by the
+// transactor account. Missing tokens are minted to the account, engraved with their
+// snapshot artifact and moved to their snapshot owner with transfer_and_save_artifact,
+// then the mint settings are restored and the result is verified token by token.
+//
+// Steps only act on the difference between the snapshot and the current state of target,
+// so an interrupted migration can be resumed by running it again. Engraving history (tag
+// nonces and past engravings) is not replayed: only the current artifacts are.
+func Migrate(ctx context.Context, client SnapshotClient, tx Transactor, source *Snapshot, target *felt.Felt, opts MigrateOptions) (*Reconciliation, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 25
+	}
+	opts.Read = opts.Read.withDefaults()
+	if opts.ABI == nil {
+		return nil, fmt.Errorf("the Ethrx ABI is required to encode the migration calls")
+	}
+
+	account, err := utils.HexToFelt(tx.GetAccountAddress())
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address: %w", err)
+	}
+	if err := checkTokenIDs(source); err != nil {
+		return nil, err
+	}
+
+	m := &migration{
+		client:  client,
+		tx:      tx,
+		source:  source,
+		target:  target,
+		account: account,
+		reader:  NewReader(client, target),
+		opts:    opts,
+	}
+
+	owner, err := m.reader.Owner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !owner.Equal(account) {
+		return nil, fmt.Errorf("%s is owned by %s, not by the migrating account %s", target.String(), owner.String(), account.String())
+	}
+
+	steps := []func(context.Context) error{m.syncTags, m.mintMissing, m.engrave, m.transfer, m.restoreSettings}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	m.log().Info("🔍 Verifying the migrated collection")
+	migrated, err := TakeSnapshot(ctx, client, target, opts.Read)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the migrated contract: %w", err)
+	}
+	report := Reconcile(source, migrated, opts.TransferOwnership)
+	report.Transactions = m.sent
+	return report, nil
+}
+
+// Reconcile compares every token and the contract settings of target with source. The
+// contract owner is only compared when ownership was expected to be transferred.
+func Reconcile(source, target *Snapshot, compareOwner bool) *Reconciliation {
+	report := &Reconciliation{
+		Source:      source.Contract,
+		SourceBlock: source.BlockNumber,
+		Target:      target.Contract,
+		TargetBlock: target.BlockNumber,
+		Tokens:      len(source.Tokens),
+		Settings:    compareSettings(source, target, compareOwner),
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	migrated := make(map[string]Token, len(target.Tokens))
+	for _, token := range target.Tokens {
+		migrated[token.TokenID] = token
+	}
+
+	for _, want := range source.Tokens {
+		check := TokenCheck{TokenID: want.TokenID, Owner: want.Owner, Status: StatusOK}
+		if got, ok := migrated[want.TokenID]; !ok {
+			check.Differences = append(check.Differences, "token not minted")
+		} else {
+			if got.Owner != want.Owner {
+				check.Differences = append(check.Differences, fmt.Sprintf("owner is %s", got.Owner))
+			}
+			for _, engraving := range artifactDiff(source.OfficialTags, want.Artifact, got.Artifact) {
+				current, _ := got.Artifact.Get(engraving.Tag)
+				check.Differences = append(check.Differences, fmt.Sprintf("%s is %q, want %q",
+					engraving.Tag, displayData(current), displayData(engraving.Data)))
+			}
+		}
+
+		if len(check.Differences) > 0 {
+			check.Status = StatusMismatch
+			report.Mismatched++
+		} else {
+			report.Matched++
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	if extra := len(target.Tokens) - len(source.Tokens); extra > 0 {
+		report.Settings = append(report.Settings, fmt.Sprintf("%d tokens beyond the snapshot", extra))
+	}
+	return report
+}
+
+// WriteReconciliation writes the report as indented JSON
+func WriteReconciliation(path string, report *Reconciliation) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode reconciliation report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write reconciliation report %s: %w", path, err)
+	}
+	return nil
+}
+
+// migration holds the state of one Migrate run
+type migration struct {
+	client  SnapshotClient
+	tx      Transactor
+	source  *Snapshot
+	target  *felt.Felt
+	account *felt.Felt
+	reader  *Reader
+	opts    MigrateOptions
+	sent    []MigrationTx
+}
+
+func (m *migration) log() *logrus.Logger {
+	return m.opts.Read.Logger
+}
+
+// syncTags renames and registers tags so the official tags match the snapshot
+func (m *migration) syncTags(ctx context.Context) error {
+	current, err := m.reader.OfficialTags(ctx)
+	if err != nil {
+		return err
+	}
+	if len(current) > len(m.source.OfficialTags) {
+		return fmt.Errorf("%s has %d official tags, more than the %d of the snapshot", m.target.String(), len(current), len(m.source.OfficialTags))
+	}
+
+	var renamed, added []any
+	for i, name := range m.source.OfficialTags {
+		tag, err := TagFelt(name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case i >= len(current):
+			added = append(added, tag)
+		case !current[i].Equal(tag):
+			// set_tags indexes the tag registry from 1
+			renamed = append(renamed, []any{i + 1, tag})
+		}
+	}
+	if len(renamed) == 0 && len(added) == 0 {
+		return nil
+	}
+
+	m.log().Infof("🏷️  Registering tags: %d renamed, %d added", len(renamed), len(added))
+	// A nil list is passed as None
+	call, err := m.call("set_tags", map[string]any{"modify_tags": optional(renamed), "new_tags": optional(added)})
+	if err != nil {
+		return err
+	}
+	return m.send(ctx, "set_tags", 0, call)
+}
+
+// mintMissing mints the tokens beyond the current supply to the migrating account
+func (m *migration) mintMissing(ctx context.Context) (err error) {
+	supply, err := m.reader.TotalSupply(ctx)
+	if err != nil {
+		return err
+	}
+	want := big.NewInt(int64(len(m.source.Tokens)))
+	if supply.Cmp(want) > 0 {
+		return fmt.Errorf("%s already has %s tokens, more than the %d of the snapshot", m.target.String(), supply, len(m.source.Tokens))
+	}
+	missing := int(new(big.Int).Sub(want, supply).Int64())
+	if missing == 0 {
+		return nil
+	}
+
+	// The account pays itself, so mints are made free and enabled for the migration
+	// and restoreSettings puts the snapshot values back
+	minting, err := m.reader.IsMinting(ctx)
+	if err != nil {
+		return err
+	}
+	price, err := m.reader.MintPrice(ctx)
+	if err != nil {
+		return err
+	}
+	var setup, undo []rpc.InvokeFunctionCall
+	addSetting := func(function string, during, after map[string]any) error {
+		call, err := m.call(function, during)
+		if err != nil {
+			return err
+		}
+		restore, err := m.call(function, after)
+		if err != nil {
+			return err
+		}
+		setup, undo = append(setup, call), append(undo, restore)
+		return nil
+	}
+	if price.Sign() != 0 {
+		if err := addSetting("set_mint_price", map[string]any{"new_mint_price": 0}, map[string]any{"new_mint_price": price}); err != nil {
+			return err
+		}
+	}
+	if !minting {
+		if err := addSetting("set_is_minting", map[string]any{"enabled": true}, map[string]any{"enabled": false}); err != nil {
+			return err
+		}
+	}
+	if len(setup) > 0 {
+		// A failed migration must not leave free public mints open, so the previous
+		// settings are put back on any error from here on, even when interrupted
+		defer func() {
+			if err != nil {
+				m.log().Warn("↩️  Restoring the mint settings after the failure")
+				if undoErr := m.send(context.WithoutCancel(ctx), "restore_mint", 0, undo...); undoErr != nil {
+					err = errors.Join(err, undoErr)
+				}
+			}
+		}()
+		if err := m.send(ctx, "prepare_mint", 0, setup...); err != nil {
+			return err
+		}
+	}
+
+	m.log().Infof("🪙 Minting %d tokens", missing)
+	var batches [][]rpc.InvokeFunctionCall
+	var tokens []int
+	for done := 0; done < missing; done += m.opts.BatchSize {
+		n := min(m.opts.BatchSize, missing-done)
+		call, err := m.call("mint", map[string]any{"amounts": []any{n}, "tos": []any{m.account}})
+		if err != nil {
+			return err
+		}
+		batches = append(batches, []rpc.InvokeFunctionCall{call})
+		tokens = append(tokens, n)
+	}
+	return m.sendBatch(ctx, "mint", tokens, batches)
+}
+
+// engrave writes the snapshot artifact of every token still held by the migrating account
+// whose current artifact differs. Only the differing tags are engraved.
+func (m *migration) engrave(ctx context.Context) error {
+	current, err := TakeSnapshot(ctx, m.client, m.target, m.opts.Read)
+	if err != nil {
+		return fmt.Errorf("failed to read the new contract: %w", err)
+	}
+
+	if len(current.Tokens) != len(m.source.Tokens) {
+		return fmt.Errorf("%s has %d tokens after minting, want %d", m.target.String(), len(current.Tokens), len(m.source.Tokens))
+	}
+
+	var tokenIDs []*big.Int
+	var artifacts []Artifact
+	for i, want := range m.source.Tokens {
+		got := current.Tokens[i]
+		if got.Owner != m.account.String() {
+			continue
+		}
+		diff := artifactDiff(m.source.OfficialTags, want.Artifact, got.Artifact)
+		if len(diff) == 0 {
+			continue
+		}
+		id, _ := new(big.Int).SetString(want.TokenID, 10)
+		tokenIDs = append(tokenIDs, id)
+		artifacts = append(artifacts, diff)
+	}
+	if len(tokenIDs) == 0 {
+		return nil
+	}
+
+	m.log().Infof("✍️  Engraving %d tokens", len(tokenIDs))
+	var batches [][]rpc.InvokeFunctionCall
+	var tokens []int
+	for start := 0; start < len(tokenIDs); start += m.opts.BatchSize {
+		end := min(start+m.opts.BatchSize, len(tokenIDs))
+		var values []any
+		for _, artifact := range artifacts[start:end] {
+			value, err := artifactValue(artifact)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+		call, err := m.call("engrave", map[string]any{"token_ids": list(tokenIDs[start:end]), "artifacts": values})
+		if err != nil {
+			return err
+		}
+		batches = append(batches, []rpc.InvokeFunctionCall{call})
+		tokens = append(tokens, end-start)
+	}
+	return m.sendBatch(ctx, "engrave", tokens, batches)
+}
+
+// transfer moves the tokens held by the migrating account to their snapshot owners,
+// keeping their artifacts
+func (m *migration) transfer(ctx context.Context) error {
+	var froms, tos []*felt.Felt
+	var tokenIDs []*big.Int
+	for _, want := range m.source.Tokens {
+		if want.Owner == m.account.String() {
+			continue
+		}
+		id, _ := new(big.Int).SetString(want.TokenID, 10)
+		owner, err := m.reader.OwnerOf(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !owner.Equal(m.account) {
+			continue
+		}
+		to, err := utils.HexToFelt(want.Owner)
+		if err != nil {
+			return fmt.Errorf("token %s: invalid owner: %w", want.TokenID, err)
+		}
+		froms = append(froms, m.account)
+		tos = append(tos, to)
+		tokenIDs = append(tokenIDs, id)
+	}
+	if len(tokenIDs) == 0 {
+		return nil
+	}
+
+	m.log().Infof("📦 Transferring %d tokens to their owners", len(tokenIDs))
+	var batches [][]rpc.InvokeFunctionCall
+	var tokens []int
+	for start := 0; start < len(tokenIDs); start += m.opts.BatchSize {
+		end := min(start+m.opts.BatchSize, len(tokenIDs))
+		call, err := m.call("transfer_and_save_artifact", map[string]any{
+			"froms":     list(froms[start:end]),
+			"tos":       list(tos[start:end]),
+			"token_ids": list(tokenIDs[start:end]),
+		})
+		if err != nil {
+			return err
+		}
+		batches = append(batches, []rpc.InvokeFunctionCall{call})
+		tokens = append(tokens, end-start)
+	}
+	return m.sendBatch(ctx, "transfer_and_save_artifact", tokens, batches)
+}
+
+// restoreSettings sets the mint token, price and minting flag of the snapshot, then
+// transfers ownership when requested
+func (m *migration) restoreSettings(ctx context.Context) error {
+	var calls []rpc.InvokeFunctionCall
+	addCall := func(function string, args map[string]any) error {
+		call, err := m.call(function, args)
+		if err != nil {
+			return err
+		}
+		calls = append(calls, call)
+		return nil
+	}
+
+	mintToken, err := m.reader.MintToken(ctx)
+	if err != nil {
+		return err
+	}
+	if mintToken.String() != m.source.MintToken {
+		want, err := utils.HexToFelt(m.source.MintToken)
+		if err != nil {
+			return fmt.Errorf("invalid snapshot mint token: %w", err)
+		}
+		if err := addCall("set_mint_token", map[string]any{"new_mint_token": want}); err != nil {
+			return err
+		}
+	}
+
+	price, err := m.reader.MintPrice(ctx)
+	if err != nil {
+		return err
+	}
+	wantPrice, ok := new(big.Int).SetString(m.source.MintPrice, 10)
+	if !ok {
+		return fmt.Errorf("invalid snapshot mint price %q", m.source.MintPrice)
+	}
+	if price.Cmp(wantPrice) != 0 {
+		if err := addCall("set_mint_price", map[string]any{"new_mint_price": wantPrice}); err != nil {
+			return err
+		}
+	}
+
+	minting, err := m.reader.IsMinting(ctx)
+	if err != nil {
+		return err
+	}
+	if minting != m.source.IsMinting {
+		if err := addCall("set_is_minting", map[string]any{"enabled": m.source.IsMinting}); err != nil {
+			return err
+		}
+	}
+
+	if m.opts.TransferOwnership && m.source.Owner != m.account.String() {
+		owner, err := utils.HexToFelt(m.source.Owner)
+		if err != nil {
+			return fmt.Errorf("invalid snapshot owner: %w", err)
+		}
+		if err := addCall("transfer_ownership", map[string]any{"new_owner": owner}); err != nil {
+			return err
+		}
+	}
+
+	if len(calls) == 0 {
+		return nil
+	}
+	m.log().Infof("⚙️  Restoring %d settings", len(calls))
+	return m.send(ctx, "settings", 0, calls...)
+}
+
+// send submits calls in one transaction and waits for it
+func (m *migration) send(ctx context.Context, step string, tokens int, calls ...rpc.InvokeFunctionCall) error {
+	txHash, err := m.tx.Invoke(ctx, calls)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", step, err)
+	}
+	m.log().WithFields(logrus.Fields{"step": step, "tx_hash": txHash.String()}).Infof("📤 %s: %s", step, txHash.String())
+	if _, err := m.tx.WaitForReceipt(ctx, txHash); err != nil {
+		return fmt.Errorf("%s transaction %s failed: %w", step, txHash.String(), err)
+	}
+	m.sent = append(m.sent, MigrationTx{Step: step, Hash: txHash.String(), Tokens: tokens})
+	return nil
+}
+
+// sendBatch sends one transaction per batch and waits for all of them. With nonce tracking
+// the transactions are pipelined instead of confirmed one by one; tokens holds the number
+// of tokens of each batch.
+func (m *migration) sendBatch(ctx context.Context, step string, tokens []int, batches [][]rpc.InvokeFunctionCall) error {
+	results, err := m.tx.InvokeBatch(ctx, batches)
+	for i, result := range results {
+		if result.TransactionHash == nil || result.Err != nil {
+			continue
+		}
+		m.log().WithFields(logrus.Fields{"step": step, "tx_hash": result.TransactionHash.String()}).Infof("📤 %s: %s", step, result.TransactionHash.String())
+		m.sent = append(m.sent, MigrationTx{Step: step, Hash: result.TransactionHash.String(), Tokens: tokens[i]})
+	}
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", step, err)
+	}
+	return nil
+}
+
+// call encodes a call to the target with the Ethrx ABI, from arguments keyed by input name
+func (m *migration) call(function string, args map[string]any) (rpc.InvokeFunctionCall, error) {
+	calldata, err := m.opts.ABI.EncodeFunction(function, args)
+	if err != nil {
+		return rpc.InvokeFunctionCall{}, fmt.Errorf("failed to encode %s: %w", function, err)
+	}
+	return rpc.InvokeFunctionCall{ContractAddress: m.target, FunctionName: function, CallData: calldata}, nil
+}
+
+// checkTokenIDs verifies the snapshot holds tokens 1..N in order, which is what minting
+// into a fresh contract reproduces
+func checkTokenIDs(snapshot *Snapshot) error {
+	for i, token := range snapshot.Tokens {
+		if token.TokenID != fmt.Sprint(i+1) {
+			return fmt.Errorf("snapshot token %d has ID %s: only collections numbered 1..N can be migrated", i+1, token.TokenID)
+		}
+	}
+	return nil
+}
+
+// compareSettings lists the contract settings of target that differ from source
+func compareSettings(source, target *Snapshot, compareOwner bool) []string {
+	var differences []string
+	compare := func(name, want, got string) {
+		if want != got {
+			differences = append(differences, fmt.Sprintf("%s is %s, want %s", name, got, want))
+		}
+	}
+	if compareOwner {
+		compare("owner", source.Owner, target.Owner)
+	}
+	compare("mint_token", source.MintToken, target.MintToken)
+	compare("mint_price", source.MintPrice, target.MintPrice)
+	compare("max_supply", source.MaxSupply, target.MaxSupply)
+	compare("is_minting", fmt.Sprint(source.IsMinting), fmt.Sprint(target.IsMinting))
+	compare("official_tags", fmt.Sprint(source.OfficialTags), fmt.Sprint(target.OfficialTags))
+	return differences
+}
+
+// artifactDiff returns the engravings of want whose data differs from got, for each tag
+func artifactDiff(tags []string, want, got Artifact) Artifact {
+	var diff Artifact
+	for _, tag := range tags {
+		wantData, _ := want.Get(tag)
+		gotData, _ := got.Get(tag)
+		if !bytes.Equal(wantData, gotData) {
+			diff = append(diff, Engraving{Tag: tag, Data: wantData})
+		}
+	}
+	return diff
+}
+
+// list converts values to an ABI array argument
+func list[T any](values []T) []any {
+	items := make([]any, len(values))
+	for i, v := range values {
+		items[i] = v
+	}
+	return items
+}
+
+// optional returns an ABI Option argument: None for an empty list
+func optional(items []any) any {
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
+
+// artifactValue returns an Artifact as an ABI struct argument; engraving data is
+// alexandria Bytes: the byte size and 16-byte big-endian words, the last one padded
+// with zeros on the right
+func artifactValue(artifact Artifact) (map[string]any, error) {
+	collection := make([]any, 0, len(artifact))
+	for _, engraving := range artifact {
+		tag, err := TagFelt(engraving.Tag)
+		if err != nil {
+			return nil, err
+		}
+		var words []any
+		for start := 0; start < len(engraving.Data); start += 16 {
+			word := make([]byte, 16)
+			copy(word, engraving.Data[start:])
+			words = append(words, new(felt.Felt).SetBytes(word))
+		}
+		collection = append(collection, map[string]any{
+			"tag":  tag,
+			"data": map[string]any{"size": len(engraving.Data), "data": words},
+		})
+	}
+	return map[string]any{"collection": collection}, nil
+}
+
+// displayData returns engraving data as text, or as hex when it is not valid UTF-8
+func displayData(data []byte) string {
+	if text := (Engraving{Data: data}).Text(); text != "" || len(data) == 0 {
+		return text
+	}
+	return "0x" + hex.EncodeToString(data)
+}
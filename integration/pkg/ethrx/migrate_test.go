@@ -0,0 +1,425 @@
+package ethrx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/abi"
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+)
+
+// fakeTransactor records every transaction it is asked to send and fails those whose
+// first call is listed in fail
+type fakeTransactor struct {
+	account *felt.Felt
+	fail    map[string]error
+	sent    [][]rpc.InvokeFunctionCall
+}
+
+func (f *fakeTransactor) Invoke(ctx context.Context, calls []rpc.InvokeFunctionCall) (*felt.Felt, error) {
+	f.sent = append(f.sent, calls)
+	if err := f.fail[calls[0].FunctionName]; err != nil {
+		return nil, err
+	}
+	return new(felt.Felt).SetUint64(uint64(len(f.sent))), nil
+}
+
+func (f *fakeTransactor) InvokeBatch(ctx context.Context, batches [][]rpc.InvokeFunctionCall) ([]deploy.BatchResult, error) {
+	var results []deploy.BatchResult
+	for _, calls := range batches {
+		txHash, err := f.Invoke(ctx, calls)
+		results = append(results, deploy.BatchResult{TransactionHash: txHash, Err: err})
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+func (f *fakeTransactor) WaitForReceipt(ctx context.Context, txHash *felt.Felt) (*rpc.TransactionReceiptWithBlockInfo, error) {
+	return &rpc.TransactionReceiptWithBlockInfo{}, nil
+}
+
+func (f *fakeTransactor) GetAccountAddress() string {
+	return f.account.String()
+}
+
+// functions lists the function names of every sent transaction
+func (f *fakeTransactor) functions() []string {
+	var names []string
+	for _, calls := range f.sent {
+		for _, call := range calls {
+			names = append(names, call.FunctionName)
+		}
+	}
+	return names
+}
+
+func newTestMigration(t *testing.T, chain *prunedChain, tx *fakeTransactor, source *Snapshot) *migration {
+	return &migration{
+		client:  chain,
+		tx:      tx,
+		source:  source,
+		target:  chain.contract,
+		account: tx.account,
+		reader:  NewReader(chain, chain.contract),
+		opts:    MigrateOptions{ABI: testEthrxABI(t), BatchSize: 25, Read: SnapshotOptions{}.withDefaults()},
+	}
+}
+
+// ethrxABI is the part of the Ethrx ABI the migration writes to
+const ethrxABI = `[
+  {"type": "struct", "name": "core::integer::u256", "members": [
+    {"name": "low", "type": "core::integer::u128"}, {"name": "high", "type": "core::integer::u128"}]},
+  {"type": "struct", "name": "alexandria_bytes::bytes::Bytes", "members": [
+    {"name": "size", "type": "core::integer::u32"},
+    {"name": "data", "type": "core::array::Array::<core::integer::u128>"}]},
+  {"type": "struct", "name": "etheracts::types::engraving::Engraving", "members": [
+    {"name": "tag", "type": "core::felt252"}, {"name": "data", "type": "alexandria_bytes::bytes::Bytes"}]},
+  {"type": "struct", "name": "etheracts::types::engraving::Artifact", "members": [
+    {"name": "collection", "type": "core::array::Array::<etheracts::types::engraving::Engraving>"}]},
+  {"type": "interface", "name": "etheracts::ethrx::interface::IEthrx", "items": [
+    {"type": "function", "name": "mint", "inputs": [
+      {"name": "amounts", "type": "core::array::Array::<core::integer::u256>"},
+      {"name": "tos", "type": "core::array::Array::<core::starknet::contract_address::ContractAddress>"}]},
+    {"type": "function", "name": "engrave", "inputs": [
+      {"name": "token_ids", "type": "core::array::Array::<core::integer::u256>"},
+      {"name": "artifacts", "type": "core::array::Array::<etheracts::types::engraving::Artifact>"}]},
+    {"type": "function", "name": "transfer_and_save_artifact", "inputs": [
+      {"name": "froms", "type": "core::array::Array::<core::starknet::contract_address::ContractAddress>"},
+      {"name": "tos", "type": "core::array::Array::<core::starknet::contract_address::ContractAddress>"},
+      {"name": "token_ids", "type": "core::array::Array::<core::integer::u256>"}]},
+    {"type": "function", "name": "set_mint_price", "inputs": [{"name": "new_mint_price", "type": "core::integer::u256"}]},
+    {"type": "function", "name": "set_mint_token", "inputs": [
+      {"name": "new_mint_token", "type": "core::starknet::contract_address::ContractAddress"}]},
+    {"type": "function", "name": "set_is_minting", "inputs": [{"name": "enabled", "type": "core::bool"}]},
+    {"type": "function", "name": "set_tags", "inputs": [
+      {"name": "modify_tags", "type": "core::option::Option::<core::array::Array::<(core::integer::u32, core::felt252)>>"},
+      {"name": "new_tags", "type": "core::option::Option::<core::array::Array::<core::felt252>>"}]}
+  ]},
+  {"type": "interface", "name": "openzeppelin_access::ownable::interface::IOwnable", "items": [
+    {"type": "function", "name": "transfer_ownership", "inputs": [
+      {"name": "new_owner", "type": "core::starknet::contract_address::ContractAddress"}]}
+  ]}
+]`
+
+func testEthrxABI(t *testing.T) *abi.ABI {
+	t.Helper()
+	contractABI, err := abi.Parse([]byte(ethrxABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return contractABI
+}
+
+func tagFelts(t *testing.T, names ...string) []*felt.Felt {
+	t.Helper()
+	tags := make([]*felt.Felt, len(names))
+	for i, name := range names {
+		tag, err := TagFelt(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tags[i] = tag
+	}
+	return tags
+}
+
+func TestSyncTags(t *testing.T) {
+	data, desc := tagFelts(t, "DATA")[0], tagFelts(t, "DESC")[0]
+
+	tests := []struct {
+		name     string
+		current  []string
+		snapshot []string
+		// want is the set_tags calldata, nil when no transaction is expected
+		want    []string
+		wantErr string
+	}{
+		{
+			name:     "rename and add",
+			current:  []string{"TITLE", "DSC"},
+			snapshot: []string{"TITLE", "DATA", "DESC"},
+			want:     []string{"0x0", "0x1", "0x2", data.String(), "0x0", "0x1", desc.String()},
+		},
+		{
+			name:     "add only",
+			current:  []string{"TITLE"},
+			snapshot: []string{"TITLE", "DESC"},
+			want:     []string{"0x1", "0x0", "0x1", desc.String()},
+		},
+		{
+			name:     "up to date",
+			current:  []string{"TITLE", "DESC"},
+			snapshot: []string{"TITLE", "DESC"},
+		},
+		{
+			name:     "more tags than the snapshot",
+			current:  []string{"TITLE", "DESC"},
+			snapshot: []string{"TITLE"},
+			wantErr:  "has 2 official tags, more than the 1 of the snapshot",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newPrunedChain()
+			chain.tags = tagFelts(t, tt.current...)
+			tx := &fakeTransactor{account: chain.owner}
+			m := newTestMigration(t, chain, tx, &Snapshot{OfficialTags: tt.snapshot})
+
+			err := m.syncTags(t.Context())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.want == nil {
+				if len(tx.sent) != 0 {
+					t.Errorf("sent %v, want no transaction", tx.functions())
+				}
+				return
+			}
+			if len(tx.sent) != 1 || tx.sent[0][0].FunctionName != "set_tags" {
+				t.Fatalf("sent %v, want one set_tags", tx.functions())
+			}
+			if got := feltStrings(tx.sent[0][0].CallData); !slices.Equal(got, tt.want) {
+				t.Errorf("calldata = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMintMissingRestoresSettingsOnFailure(t *testing.T) {
+	source := &Snapshot{Tokens: []Token{{TokenID: "1"}, {TokenID: "2"}}}
+
+	tests := []struct {
+		name      string
+		mintPrice uint64
+		isMinting bool
+		fail      map[string]error
+		want      []string
+		wantErr   bool
+		restored  bool
+	}{
+		{
+			name:      "mint fails",
+			mintPrice: 7,
+			fail:      map[string]error{"mint": errors.New("reverted")},
+			want:      []string{"set_mint_price", "set_is_minting", "mint", "set_mint_price", "set_is_minting"},
+			wantErr:   true,
+			restored:  true,
+		},
+		{
+			name:      "prepare fails",
+			mintPrice: 7,
+			fail:      map[string]error{"set_mint_price": errors.New("timeout")},
+			want:      []string{"set_mint_price", "set_is_minting", "set_mint_price", "set_is_minting"},
+			wantErr:   true,
+			restored:  true,
+		},
+		{
+			name:      "mint succeeds",
+			mintPrice: 7,
+			want:      []string{"set_mint_price", "set_is_minting", "mint"},
+		},
+		{
+			name:      "free and open already",
+			isMinting: true,
+			fail:      map[string]error{"mint": errors.New("reverted")},
+			want:      []string{"mint"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newPrunedChain()
+			chain.mintPrice, chain.isMinting = tt.mintPrice, tt.isMinting
+			tx := &fakeTransactor{account: chain.owner, fail: tt.fail}
+
+			err := newTestMigration(t, chain, tx, source).mintMissing(t.Context())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %t", err, tt.wantErr)
+			}
+			if got := tx.functions(); !slices.Equal(got, tt.want) {
+				t.Fatalf("sent %v, want %v", got, tt.want)
+			}
+
+			if tt.restored {
+				restore := tx.sent[len(tx.sent)-1]
+				if got := feltStrings(restore[0].CallData); !slices.Equal(got, []string{"0x7", "0x0"}) {
+					t.Errorf("restored mint price = %v, want 7", got)
+				}
+				if got := feltStrings(restore[1].CallData); !slices.Equal(got, []string{"0x0"}) {
+					t.Errorf("restored is_minting = %v, want false", got)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name         string
+		change       func(target *Snapshot)
+		compareOwner bool
+		wantSettings []string
+		// wantDiffs holds the differences of each mismatched token
+		wantDiffs map[string][]string
+	}{
+		{
+			name:   "identical",
+			change: func(target *Snapshot) {},
+		},
+		{
+			name:      "owner",
+			change:    func(target *Snapshot) { target.Tokens[1].Owner = "0xc" },
+			wantDiffs: map[string][]string{"2": {"owner is 0xc"}},
+		},
+		{
+			name: "artifact",
+			change: func(target *Snapshot) {
+				target.Tokens[0].Artifact = Artifact{{Tag: "TITLE", Data: []byte("Other")}}
+				target.Tokens[1].Artifact[1].Data = []byte{0xfe}
+			},
+			wantDiffs: map[string][]string{
+				"1": {`TITLE is "Other", want "Binary"`},
+				"2": {`DATA is "0xfe", want "0xff00"`},
+			},
+		},
+		{
+			name:      "token not minted",
+			change:    func(target *Snapshot) { target.Tokens = target.Tokens[:1] },
+			wantDiffs: map[string][]string{"2": {"token not minted"}},
+		},
+		{
+			name: "tokens beyond the snapshot",
+			change: func(target *Snapshot) {
+				target.Tokens = append(target.Tokens, Token{TokenID: "3", Owner: "0xa"})
+			},
+			wantSettings: []string{"1 tokens beyond the snapshot"},
+		},
+		{
+			name:         "settings",
+			change:       func(target *Snapshot) { target.MintPrice, target.IsMinting = "0", false },
+			wantSettings: []string{"mint_price is 0, want 1000", "is_minting is false, want true"},
+		},
+		{
+			name:   "owner not compared",
+			change: func(target *Snapshot) { target.Owner = "0xd" },
+		},
+		{
+			name:         "owner compared",
+			change:       func(target *Snapshot) { target.Owner = "0xd" },
+			compareOwner: true,
+			wantSettings: []string{"owner is 0xd, want 0xa"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, target := exportSnapshot(), exportSnapshot()
+			target.Contract = "0xe8"
+			tt.change(target)
+
+			report := Reconcile(source, target, tt.compareOwner)
+			if report.Source != "0xe7" || report.Target != "0xe8" || report.Tokens != 2 {
+				t.Errorf("report = %+v", report)
+			}
+			if !slices.Equal(report.Settings, tt.wantSettings) {
+				t.Errorf("settings = %q, want %q", report.Settings, tt.wantSettings)
+			}
+
+			diffs := make(map[string][]string)
+			for _, check := range report.Checks {
+				if check.Status == StatusMismatch {
+					diffs[check.TokenID] = check.Differences
+				} else if check.Status != StatusOK || len(check.Differences) > 0 {
+					t.Errorf("check = %+v", check)
+				}
+			}
+			if len(diffs) != 0 || len(tt.wantDiffs) != 0 {
+				if !reflect.DeepEqual(diffs, tt.wantDiffs) {
+					t.Errorf("differences = %q, want %q", diffs, tt.wantDiffs)
+				}
+			}
+			if report.Mismatched != len(tt.wantDiffs) || report.Matched != 2-len(tt.wantDiffs) {
+				t.Errorf("matched %d, mismatched %d", report.Matched, report.Mismatched)
+			}
+			if ok := len(tt.wantDiffs) == 0 && len(tt.wantSettings) == 0; report.OK() != ok {
+				t.Errorf("OK = %t, want %t", report.OK(), ok)
+			}
+		})
+	}
+}
+
+func TestArtifactDiff(t *testing.T) {
+	tags := []string{"TITLE", "DATA", "DESC", "NOTE"}
+	want := Artifact{
+		{Tag: "TITLE", Data: []byte("same")},
+		{Tag: "DATA", Data: []byte("new")},
+		{Tag: "NOTE", Data: []byte{}},
+	}
+	got := Artifact{
+		{Tag: "TITLE", Data: []byte("same")},
+		{Tag: "DATA", Data: []byte("old")},
+		{Tag: "DESC", Data: []byte("stale")},
+		{Tag: "EXTRA", Data: []byte("not official")},
+	}
+
+	// A missing tag and empty data are the same; tags outside the list are ignored
+	diff := artifactDiff(tags, want, got)
+	wantDiff := Artifact{{Tag: "DATA", Data: []byte("new")}, {Tag: "DESC"}}
+	if !reflect.DeepEqual(diff, wantDiff) {
+		t.Errorf("diff = %+v, want %+v", diff, wantDiff)
+	}
+
+	if diff := artifactDiff(tags, want, want); diff != nil {
+		t.Errorf("diff of an artifact with itself = %+v", diff)
+	}
+}
+
+func TestArtifactValue(t *testing.T) {
+	contractABI := testEthrxABI(t)
+	artifact := Artifact{
+		{Tag: "TITLE", Data: []byte("Hello from the migration, longer than sixteen bytes")},
+		{Tag: "DESC"},
+	}
+
+	value, err := artifactValue(artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := contractABI.Encode("etheracts::types::engraving::Artifact", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := encodeArtifact(artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(feltStrings(got), feltStrings(want)) {
+		t.Errorf("calldata = %v, want %v", feltStrings(got), feltStrings(want))
+	}
+}
+
+func feltStrings(felts []*felt.Felt) []string {
+	out := make([]string, len(felts))
+	for i, f := range felts {
+		out[i] = f.String()
+	}
+	return out
+}
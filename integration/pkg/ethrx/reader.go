@@ -0,0 +1,249 @@
+package ethrx
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+)
+
+// Caller performs starknet_call requests; *rpc.Provider implements it
+type Caller interface {
+	Call(ctx context.Context, call rpc.FunctionCall, blockID rpc.BlockID) ([]*felt.Felt, error)
+}
+
+// Reader calls the read entrypoints of an Ethrx contract at a fixed block
+type Reader struct {
+	client  Caller
+	address *felt.Felt
+	block   rpc.BlockID
+}
+
+// NewReader creates a reader for the Ethrx contract at address, reading the latest block
+func NewReader(client Caller, address *felt.Felt) *Reader {
+	return &Reader{
+		client:  client,
+		address: address,
+		block:   rpc.WithBlockTag(rpc.BlockTagLatest),
+	}
+}
+
+// At returns a reader pinned to block
+func (r *Reader) At(block rpc.BlockID) *Reader {
+	pinned := *r
+	pinned.block = block
+	return &pinned
+}
+
+// Address returns the contract address
+func (r *Reader) Address() *felt.Felt {
+	return r.address
+}
+
+// call invokes a read entrypoint and returns a decoder over its result
+func (r *Reader) call(ctx context.Context, function string, calldata []*felt.Felt) (*decoder, error) {
+	result, err := r.client.Call(ctx, rpc.FunctionCall{
+		ContractAddress:    r.address,
+		EntryPointSelector: utils.GetSelectorFromNameFelt(function),
+		Calldata:           calldata,
+	}, r.block)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", function, err)
+	}
+	return newDecoder(result), nil
+}
+
+// TotalSupply returns the number of minted tokens
+func (r *Reader) TotalSupply(ctx context.Context) (*big.Int, error) {
+	return r.callU256(ctx, "total_supply", nil)
+}
+
+// MaxSupply returns the maximum number of tokens
+func (r *Reader) MaxSupply(ctx context.Context) (*big.Int, error) {
+	return r.callU256(ctx, "max_supply", nil)
+}
+
+// MintPrice returns the price per token in mint token units
+func (r *Reader) MintPrice(ctx context.Context) (*big.Int, error) {
+	return r.callU256(ctx, "mint_price", nil)
+}
+
+// TokenByIndex returns the token at a zero-based enumeration index
+func (r *Reader) TokenByIndex(ctx context.Context, index *big.Int) (*big.Int, error) {
+	return r.callU256(ctx, "token_by_index", encodeU256(index))
+}
+
+// OwnerOf returns the owner of a token
+func (r *Reader) OwnerOf(ctx context.Context, tokenID *big.Int) (*felt.Felt, error) {
+	return r.callFelt(ctx, "owner_of", encodeU256(tokenID))
+}
+
+// Owner returns the contract owner
+func (r *Reader) Owner(ctx context.Context) (*felt.Felt, error) {
+	return r.callFelt(ctx, "owner", nil)
+}
+
+// MintToken returns the ERC20 token mints are paid in
+func (r *Reader) MintToken(ctx context.Context) (*felt.Felt, error) {
+	return r.callFelt(ctx, "mint_token", nil)
+}
+
+// TotalArtifacts returns the number of artifact IDs handed out so far
+func (r *Reader) TotalArtifacts(ctx context.Context) (*felt.Felt, error) {
+	return r.callFelt(ctx, "total_artifacts", nil)
+}
+
+// IsMinting reports whether public minting is enabled
+func (r *Reader) IsMinting(ctx context.Context) (bool, error) {
+	d, err := r.call(ctx, "is_minting", nil)
+	if err != nil {
+		return false, err
+	}
+	enabled, err := d.bool()
+	if err != nil {
+		return false, fmt.Errorf("invalid is_minting result: %w", err)
+	}
+	return enabled, nil
+}
+
+// Version returns the contract version, bumped by every upgrade
+func (r *Reader) Version(ctx context.Context) (uint64, error) {
+	d, err := r.call(ctx, "version", nil)
+	if err != nil {
+		return 0, err
+	}
+	version, err := d.uint()
+	if err != nil {
+		return 0, fmt.Errorf("invalid version result: %w", err)
+	}
+	return version, nil
+}
+
+// OfficialTags returns the registered tags in registration order
+func (r *Reader) OfficialTags(ctx context.Context) ([]*felt.Felt, error) {
+	d, err := r.call(ctx, "official_tags", nil)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := d.felts252()
+	if err == nil {
+		err = d.done()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid official_tags result: %w", err)
+	}
+	return tags, nil
+}
+
+// ArtifactIDs returns the artifact ID of each token
+func (r *Reader) ArtifactIDs(ctx context.Context, tokenIDs []*big.Int) ([]*felt.Felt, error) {
+	d, err := r.call(ctx, "token_ids_to_artifact_ids", encodeU256Array(tokenIDs))
+	if err != nil {
+		return nil, err
+	}
+	ids, err := d.felts252()
+	if err == nil && len(ids) != len(tokenIDs) {
+		err = fmt.Errorf("expected %d artifact IDs, got %d", len(tokenIDs), len(ids))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid token_ids_to_artifact_ids result: %w", err)
+	}
+	return ids, nil
+}
+
+// TagNonces returns the current nonce of each (artifact ID, tag) pair
+func (r *Reader) TagNonces(ctx context.Context, artifactIDs, tags []*felt.Felt) ([]uint64, error) {
+	if len(artifactIDs) != len(tags) {
+		return nil, fmt.Errorf("got %d artifact IDs but %d tags", len(artifactIDs), len(tags))
+	}
+	calldata := append(encodeFeltArray(artifactIDs), encodeFeltArray(tags)...)
+	d, err := r.call(ctx, "artifact_tag_nonces", calldata)
+	if err != nil {
+		return nil, err
+	}
+	nonces, err := d.uints()
+	if err == nil && len(nonces) != len(tags) {
+		err = fmt.Errorf("expected %d nonces, got %d", len(tags), len(nonces))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact_tag_nonces result: %w", err)
+	}
+	return nonces, nil
+}
+
+// Artifacts returns the latest official artifact of each token
+func (r *Reader) Artifacts(ctx context.Context, tokenIDs []*big.Int) ([]Artifact, error) {
+	d, err := r.call(ctx, "get_artifacts", encodeU256Array(tokenIDs))
+	if err != nil {
+		return nil, err
+	}
+	artifacts, err := d.artifacts()
+	if err == nil && len(artifacts) != len(tokenIDs) {
+		err = fmt.Errorf("expected %d artifacts, got %d", len(tokenIDs), len(artifacts))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid get_artifacts result: %w", err)
+	}
+	return artifacts, nil
+}
+
+// HistoricArtifacts returns, for each artifact ID, the engravings of the given tags at
+// the given tag nonces
+func (r *Reader) HistoricArtifacts(ctx context.Context, artifactIDs []*felt.Felt, tags [][]*felt.Felt, nonces [][]uint64) ([]Artifact, error) {
+	if len(artifactIDs) != len(tags) || len(tags) != len(nonces) {
+		return nil, fmt.Errorf("got %d artifact IDs, %d tag lists and %d nonce lists", len(artifactIDs), len(tags), len(nonces))
+	}
+
+	calldata := encodeFeltArray(artifactIDs)
+	calldata = append(calldata, new(felt.Felt).SetUint64(uint64(len(tags))))
+	for _, list := range tags {
+		calldata = append(calldata, encodeFeltArray(list)...)
+	}
+	calldata = append(calldata, new(felt.Felt).SetUint64(uint64(len(nonces))))
+	for _, list := range nonces {
+		calldata = append(calldata, new(felt.Felt).SetUint64(uint64(len(list))))
+		for _, nonce := range list {
+			calldata = append(calldata, new(felt.Felt).SetUint64(nonce))
+		}
+	}
+
+	d, err := r.call(ctx, "get_historic_artifacts", calldata)
+	if err != nil {
+		return nil, err
+	}
+	artifacts, err := d.artifacts()
+	if err == nil && len(artifacts) != len(artifactIDs) {
+		err = fmt.Errorf("expected %d artifacts, got %d", len(artifactIDs), len(artifacts))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid get_historic_artifacts result: %w", err)
+	}
+	return artifacts, nil
+}
+
+func (r *Reader) callU256(ctx context.Context, function string, calldata []*felt.Felt) (*big.Int, error) {
+	d, err := r.call(ctx, function, calldata)
+	if err != nil {
+		return nil, err
+	}
+	value, err := d.u256()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s result: %w", function, err)
+	}
+	return value, nil
+}
+
+func (r *Reader) callFelt(ctx context.Context, function string, calldata []*felt.Felt) (*felt.Felt, error) {
+	d, err := r.call(ctx, function, calldata)
+	if err != nil {
+		return nil, err
+	}
+	value, err := d.felt()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s result: %w", function, err)
+	}
+	return value, nil
+}
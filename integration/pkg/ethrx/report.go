@@ -0,0 +1,482 @@
+package ethrx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// ReportClient is the RPC access needed to build a report; *rpc.Provider implements it
+type ReportClient interface {
+	HistoryClient
+	TransactionReceipt(ctx context.Context, transactionHash *felt.Felt) (*rpc.TransactionReceiptWithBlockInfo, error)
+}
+
+// Report file names written by WriteReport
+const (
+	ReportJSONFile     = "report.json"
+	ReportMarkdownFile = "report.md"
+)
+
+// HolderCount is the number of tokens held by one account
+type HolderCount struct {
+	Holder string `json:"holder"`
+	Tokens int    `json:"tokens"`
+}
+
+// HolderBucket is the number of holders holding a range of token counts
+type HolderBucket struct {
+	Range   string `json:"range"`
+	Holders int    `json:"holders"`
+	Tokens  int    `json:"tokens"`
+}
+
+// TagCount is the number of engravings of one tag
+type TagCount struct {
+	Tag        string `json:"tag"`
+	Official   bool   `json:"official"`
+	Engravings int    `json:"engravings"`
+	Tokens     int    `json:"tokens"`
+}
+
+// TokenCount is the number of engravings of one token
+type TokenCount struct {
+	TokenID    string `json:"token_id"`
+	Engravings int    `json:"engravings"`
+}
+
+// Report is a summary of a collection's holders and activity, built from a snapshot and
+// the events up to its block
+type Report struct {
+	Contract       string    `json:"contract"`
+	Network        string    `json:"network,omitempty"`
+	BlockNumber    uint64    `json:"block_number"`
+	BlockTimestamp time.Time `json:"block_timestamp"`
+	FromBlock      uint64    `json:"from_block"`
+
+	Owner           string `json:"owner"`
+	TotalSupply     string `json:"total_supply"`
+	MaxSupply       string `json:"max_supply"`
+	RemainingSupply string `json:"remaining_supply"`
+
+	Holders      int            `json:"holders"`
+	OwnerTokens  int            `json:"owner_tokens"`
+	OtherTokens  int            `json:"other_tokens"`
+	Distribution []HolderBucket `json:"distribution"`
+	TopHolders   []HolderCount  `json:"top_holders"`
+
+	Engravings     int          `json:"engravings"`
+	EngravedTokens int          `json:"engraved_tokens"`
+	Tags           []TagCount   `json:"tags"`
+	MostEngraved   []TokenCount `json:"most_engraved"`
+
+	MintToken string `json:"mint_token"`
+	MintPrice string `json:"mint_price"`
+	// PaidMints counts the tokens whose mint paid the mint token; the constructor mints
+	// and mints at price zero are free
+	PaidMints   int    `json:"paid_mints"`
+	MintRevenue string `json:"mint_revenue"`
+	// UnknownMints counts the tokens whose mint paid in another token than the snapshot's
+	// mint token; their payments are not part of MintRevenue
+	UnknownMints int `json:"unknown_mints"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReportOptions controls how a report is built
+type ReportOptions struct {
+	// Network is recorded in the report
+	Network string
+	// FromBlock is the first block scanned for events, such as the contract deployment block
+	FromBlock uint64
+	// Top is the number of holders and tokens in the rankings (default 10)
+	Top int
+	// Workers is the number of concurrent RPC calls (default 8)
+	Workers int
+	// Logger receives progress messages; nil logs nothing
+	Logger *logrus.Logger
+}
+
+// holderBuckets are the token count ranges of the holder distribution
+var holderBuckets = []struct {
+	label    string
+	min, max int
+}{
+	{"1", 1, 1},
+	{"2-4", 2, 4},
+	{"5-9", 5, 9},
+	{"10-49", 10, 49},
+	{"50+", 50, int(^uint(0) >> 1)},
+}
+
+// BuildReport computes holder and engraving statistics for the snapshot's contract.
+// Holders come from the snapshot. Engravings and mints come from the events between
+// opts.FromBlock and the snapshot block; the constructor engravings of tokens 1–11 emit
+// no events and are not counted. Mint revenue is what the mints paid, read from the
+// mint token transfers of the minting transactions.
+func BuildReport(ctx context.Context, client ReportClient, snapshot *Snapshot, opts ReportOptions) (*Report, error) {
+	if opts.Logger == nil {
+		opts.Logger = logrus.New()
+		opts.Logger.SetOutput(io.Discard)
+	}
+	if opts.Top <= 0 {
+		opts.Top = 10
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 8
+	}
+	if opts.FromBlock > snapshot.BlockNumber {
+		return nil, fmt.Errorf("from block %d is after the snapshot block %d", opts.FromBlock, snapshot.BlockNumber)
+	}
+	address, err := new(felt.Felt).SetString(snapshot.Contract)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot contract %q: %w", snapshot.Contract, err)
+	}
+
+	report := &Report{
+		Contract:       snapshot.Contract,
+		Network:        opts.Network,
+		BlockNumber:    snapshot.BlockNumber,
+		BlockTimestamp: snapshot.BlockTimestamp,
+		FromBlock:      opts.FromBlock,
+		Owner:          snapshot.Owner,
+		TotalSupply:    snapshot.TotalSupply,
+		MaxSupply:      snapshot.MaxSupply,
+		MintToken:      snapshot.MintToken,
+		MintPrice:      snapshot.MintPrice,
+		CreatedAt:      time.Now().UTC(),
+	}
+	total, ok := new(big.Int).SetString(snapshot.TotalSupply, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid snapshot total supply %q", snapshot.TotalSupply)
+	}
+	maxSupply, ok := new(big.Int).SetString(snapshot.MaxSupply, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid snapshot max supply %q", snapshot.MaxSupply)
+	}
+	if _, ok := new(big.Int).SetString(snapshot.MintPrice, 10); !ok {
+		return nil, fmt.Errorf("invalid snapshot mint price %q", snapshot.MintPrice)
+	}
+	report.RemainingSupply = new(big.Int).Sub(maxSupply, total).String()
+
+	addHolders(report, snapshot, opts.Top)
+
+	opts.Logger.Infof("🔎 Reading events of blocks %d-%d", opts.FromBlock, snapshot.BlockNumber)
+	events, err := ReadEvents(ctx, client, address, opts.FromBlock, snapshot.BlockNumber, EventTransfer, EventArtifactEngraved, EventOwnershipTransferred)
+	if err != nil {
+		return nil, err
+	}
+	addEngravings(report, snapshot, events, opts.Top)
+	if err := addMintRevenue(ctx, client, address, report, events, opts); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// addHolders computes the holder distribution of the snapshot
+func addHolders(report *Report, snapshot *Snapshot, top int) {
+	counts := make(map[string]int)
+	for _, token := range snapshot.Tokens {
+		counts[token.Owner]++
+		if token.Owner == snapshot.Owner {
+			report.OwnerTokens++
+		} else {
+			report.OtherTokens++
+		}
+	}
+	report.Holders = len(counts)
+
+	holders := make([]HolderCount, 0, len(counts))
+	for holder, tokens := range counts {
+		holders = append(holders, HolderCount{Holder: holder, Tokens: tokens})
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		if holders[i].Tokens != holders[j].Tokens {
+			return holders[i].Tokens > holders[j].Tokens
+		}
+		return holders[i].Holder < holders[j].Holder
+	})
+	report.TopHolders = holders[:min(top, len(holders))]
+
+	for _, bucket := range holderBuckets {
+		entry := HolderBucket{Range: bucket.label}
+		for _, holder := range holders {
+			if holder.Tokens >= bucket.min && holder.Tokens <= bucket.max {
+				entry.Holders++
+				entry.Tokens += holder.Tokens
+			}
+		}
+		report.Distribution = append(report.Distribution, entry)
+	}
+}
+
+// addEngravings counts the ArtifactEngraved events per tag and per token
+func addEngravings(report *Report, snapshot *Snapshot, events []Event, top int) {
+	official := make(map[string]bool, len(snapshot.OfficialTags))
+	for _, tag := range snapshot.OfficialTags {
+		official[tag] = true
+	}
+
+	perToken := make(map[string]int)
+	perTag := make(map[string]int)
+	tagTokens := make(map[string]map[string]bool)
+	for _, event := range events {
+		if event.Kind != EventArtifactEngraved {
+			continue
+		}
+		id, tag := event.TokenID.String(), event.New.Tag
+		report.Engravings++
+		perToken[id]++
+		perTag[tag]++
+		if tagTokens[tag] == nil {
+			tagTokens[tag] = make(map[string]bool)
+		}
+		tagTokens[tag][id] = true
+	}
+	report.EngravedTokens = len(perToken)
+
+	// Official tags come first in registry order, then the others by use
+	for _, tag := range snapshot.OfficialTags {
+		report.Tags = append(report.Tags, TagCount{Tag: tag, Official: true, Engravings: perTag[tag], Tokens: len(tagTokens[tag])})
+	}
+	var others []TagCount
+	for tag, count := range perTag {
+		if !official[tag] {
+			others = append(others, TagCount{Tag: tag, Engravings: count, Tokens: len(tagTokens[tag])})
+		}
+	}
+	sort.Slice(others, func(i, j int) bool {
+		if others[i].Engravings != others[j].Engravings {
+			return others[i].Engravings > others[j].Engravings
+		}
+		return others[i].Tag < others[j].Tag
+	})
+	report.Tags = append(report.Tags, others...)
+
+	tokens := make([]TokenCount, 0, len(perToken))
+	for id, count := range perToken {
+		tokens = append(tokens, TokenCount{TokenID: id, Engravings: count})
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].Engravings != tokens[j].Engravings {
+			return tokens[i].Engravings > tokens[j].Engravings
+		}
+		a, _ := new(big.Int).SetString(tokens[i].TokenID, 10)
+		b, _ := new(big.Int).SetString(tokens[j].TokenID, 10)
+		return a.Cmp(b) < 0
+	})
+	report.MostEngraved = tokens[:min(top, len(tokens))]
+}
+
+// addMintRevenue sums what the mints paid, read from the receipts of the minting
+// transactions. mint pays for each recipient with one mint token transfer_from to the
+// contract owner right after minting its tokens, so the first Transfer event of another
+// contract to an owner after a run of mints, and before the next Ethrx event, is their
+// payment. This holds however the transaction reached mint, directly or through another
+// contract. Other transfers, such as the transaction fee paid in the same token, are not
+// payments. Runs without a payment are free, such as the constructor mints and mints at
+// price 0. A payment in another token than the snapshot's mint token cannot be valued:
+// its mints are counted as unknown.
+func addMintRevenue(ctx context.Context, client ReportClient, address *felt.Felt, report *Report, events []Event, opts ReportOptions) error {
+	mintToken, err := new(felt.Felt).SetString(report.MintToken)
+	if err != nil {
+		return fmt.Errorf("invalid snapshot mint token %q: %w", report.MintToken, err)
+	}
+
+	// Payments go to the owner at the time of the mint
+	owners := map[string]bool{report.Owner: true}
+	var txHashes []*felt.Felt
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if event.Kind == EventOwnershipTransferred {
+			owners[event.From.String()], owners[event.To.String()] = true, true
+		}
+		if event.Kind == EventTransfer && event.From.IsZero() && !seen[event.TxHash.String()] {
+			seen[event.TxHash.String()] = true
+			txHashes = append(txHashes, event.TxHash)
+		}
+	}
+
+	payments := make([]mintPayments, len(txHashes))
+	err = forEach(ctx, len(txHashes), opts.Workers, func(ctx context.Context, i int) error {
+		receipt, err := client.TransactionReceipt(ctx, txHashes[i])
+		if err != nil {
+			return fmt.Errorf("failed to get the receipt of transaction %s: %w", txHashes[i].String(), err)
+		}
+		if payments[i], err = readMintPayments(receipt.Events, address, mintToken, owners); err != nil {
+			return fmt.Errorf("transaction %s: %w", txHashes[i].String(), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	revenue := new(big.Int)
+	for _, payment := range payments {
+		report.PaidMints += payment.paid
+		report.UnknownMints += payment.unknown
+		revenue.Add(revenue, payment.revenue)
+	}
+	report.MintRevenue = revenue.String()
+	if report.UnknownMints > 0 {
+		opts.Logger.Warnf("⚠️  %d mints paid in another token than %s and are not part of the mint revenue", report.UnknownMints, report.MintToken)
+	}
+	return nil
+}
+
+// mintPayments is what the mints of one transaction paid
+type mintPayments struct {
+	paid, unknown int
+	revenue       *big.Int
+}
+
+// readMintPayments matches the mints of address among the events of a transaction
+// receipt with the payments to one of owners that follow them
+func readMintPayments(events []rpc.Event, address, mintToken *felt.Felt, owners map[string]bool) (mintPayments, error) {
+	result := mintPayments{revenue: new(big.Int)}
+	transfer := utils.GetSelectorFromNameFelt("Transfer")
+
+	minted := 0
+	for _, event := range events {
+		isTransfer := len(event.Keys) > 0 && event.Keys[0].Equal(transfer)
+		if event.FromAddress.Equal(address) {
+			if isTransfer && len(event.Keys) > 1 && event.Keys[1].IsZero() {
+				minted++
+			} else {
+				// Another Ethrx event ends the run: it was free
+				minted = 0
+			}
+			continue
+		}
+		if minted == 0 || !isTransfer {
+			continue
+		}
+
+		to, amount, err := decodeTransfer(event)
+		if err != nil {
+			return result, err
+		}
+		if to == nil || !owners[to.String()] {
+			continue
+		}
+		if !event.FromAddress.Equal(mintToken) {
+			result.unknown += minted
+		} else if amount.Sign() > 0 {
+			result.paid += minted
+			result.revenue.Add(result.revenue, amount)
+		}
+		minted = 0
+	}
+	return result, nil
+}
+
+// decodeTransfer returns the recipient and amount of an ERC20 Transfer event, whose from
+// and to are keys (Cairo 1 tokens) or data (Cairo 0 tokens). Other Transfer events give
+// no recipient.
+func decodeTransfer(event rpc.Event) (*felt.Felt, *big.Int, error) {
+	var to *felt.Felt
+	data := event.Data
+	switch {
+	case len(event.Keys) == 3:
+		to = event.Keys[2]
+	case len(event.Keys) == 1 && len(data) == 4:
+		to, data = data[1], data[2:]
+	default:
+		// Not an ERC20 transfer, such as an ERC721 one with the token ID in the keys
+		return nil, nil, nil
+	}
+	d := newDecoder(data)
+	amount, err := d.u256()
+	if err == nil {
+		err = d.done()
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid Transfer event of %s: %w", event.FromAddress.String(), err)
+	}
+	return to, amount, nil
+}
+
+// WriteReport writes the report to dir as JSON and Markdown
+func WriteReport(dir string, report *Report) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	_, err := writeFile(filepath.Join(dir, ReportJSONFile), func(out io.Writer) error {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = writeFile(filepath.Join(dir, ReportMarkdownFile), func(out io.Writer) error {
+		_, err := io.WriteString(out, report.Markdown())
+		return err
+	})
+	return err
+}
+
+// Markdown renders the report as a Markdown document
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Ethrx Collection Report\n\n")
+	fmt.Fprintf(&b, "- Contract: `%s`\n", r.Contract)
+	if r.Network != "" {
+		fmt.Fprintf(&b, "- Network: %s\n", r.Network)
+	}
+	fmt.Fprintf(&b, "- Block: %d (%s)\n", r.BlockNumber, r.BlockTimestamp.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Events scanned from block: %d\n\n", r.FromBlock)
+
+	fmt.Fprintf(&b, "## Supply\n\n")
+	fmt.Fprintf(&b, "| Total supply | Max supply | Remaining |\n|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| %s | %s | %s |\n\n", r.TotalSupply, r.MaxSupply, r.RemainingSupply)
+
+	fmt.Fprintf(&b, "## Holders\n\n")
+	fmt.Fprintf(&b, "%d holders. The owner `%s` holds %d tokens, other accounts hold %d.\n\n", r.Holders, r.Owner, r.OwnerTokens, r.OtherTokens)
+	fmt.Fprintf(&b, "| Tokens held | Holders | Tokens |\n|---|---:|---:|\n")
+	for _, bucket := range r.Distribution {
+		fmt.Fprintf(&b, "| %s | %d | %d |\n", bucket.Range, bucket.Holders, bucket.Tokens)
+	}
+	fmt.Fprintf(&b, "\n| Top holder | Tokens |\n|---|---:|\n")
+	for _, holder := range r.TopHolders {
+		fmt.Fprintf(&b, "| `%s` | %d |\n", holder.Holder, holder.Tokens)
+	}
+
+	fmt.Fprintf(&b, "\n## Engravings\n\n")
+	fmt.Fprintf(&b, "%d engravings on %d tokens.\n\n", r.Engravings, r.EngravedTokens)
+	fmt.Fprintf(&b, "| Tag | Official | Engravings | Tokens |\n|---|---|---:|---:|\n")
+	for _, tag := range r.Tags {
+		official := "no"
+		if tag.Official {
+			official = "yes"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %d | %d |\n", tag.Tag, official, tag.Engravings, tag.Tokens)
+	}
+	fmt.Fprintf(&b, "\n| Most engraved token | Engravings |\n|---:|---:|\n")
+	for _, token := range r.MostEngraved {
+		fmt.Fprintf(&b, "| %s | %d |\n", token.TokenID, token.Engravings)
+	}
+
+	fmt.Fprintf(&b, "\n## Mints\n\n")
+	fmt.Fprintf(&b, "- Mint token: `%s`\n", r.MintToken)
+	fmt.Fprintf(&b, "- Current mint price: %s\n", r.MintPrice)
+	fmt.Fprintf(&b, "- Paid mints: %d\n", r.PaidMints)
+	fmt.Fprintf(&b, "- Mint revenue: %s\n", r.MintRevenue)
+	if r.UnknownMints > 0 {
+		fmt.Fprintf(&b, "- Mints paid in another token (not in the revenue): %d\n", r.UnknownMints)
+	}
+	return b.String()
+}
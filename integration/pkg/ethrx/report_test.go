@@ -0,0 +1,140 @@
+package ethrx
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+)
+
+func TestBuildReport(t *testing.T) {
+	alice, bob := new(felt.Felt).SetUint64(0xa), new(felt.Felt).SetUint64(0xb)
+	tx := func(n uint64) *felt.Felt { return new(felt.Felt).SetUint64(n) }
+
+	chain := newPrunedChain()
+	chain.transfer(1, tx(1), &felt.Zero, alice, 1, false)
+	chain.transfer(1, tx(1), &felt.Zero, alice, 2, false)
+	chain.fee(tx(1), alice, 5000)
+	chain.engrave(10, tx(2), 1, "TITLE", "first")
+	chain.mintPrice = 7
+	chain.mint(12, tx(3), bob, 3)
+	chain.engrave(20, tx(4), 1, "TITLE", "second")
+	chain.engrave(21, tx(5), 3, "NOTE", "unofficial")
+	chain.mintPrice = 9
+	chain.mint(36, tx(6), bob, 4)
+	chain.latest, chain.prunedBelow = 40, 35
+
+	snapshot := &Snapshot{
+		Contract:     chain.contract.String(),
+		BlockNumber:  40,
+		Owner:        alice.String(),
+		MintToken:    chain.mintToken.String(),
+		MintPrice:    "9",
+		MaxSupply:    "10",
+		TotalSupply:  "4",
+		OfficialTags: []string{"TITLE"},
+		Tokens: []Token{
+			{TokenID: "1", Owner: alice.String()},
+			{TokenID: "2", Owner: alice.String()},
+			{TokenID: "3", Owner: bob.String()},
+			{TokenID: "4", Owner: bob.String()},
+		},
+	}
+	report, err := BuildReport(t.Context(), chain, snapshot, ReportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Holders != 2 || report.OwnerTokens != 2 || report.OtherTokens != 2 || report.RemainingSupply != "6" {
+		t.Errorf("holders = %d, owner/other tokens = %d/%d, remaining = %s", report.Holders, report.OwnerTokens, report.OtherTokens, report.RemainingSupply)
+	}
+	if report.Distribution[1] != (HolderBucket{Range: "2-4", Holders: 2, Tokens: 4}) {
+		t.Errorf("distribution = %+v", report.Distribution)
+	}
+	if report.Engravings != 3 || report.EngravedTokens != 2 {
+		t.Errorf("engravings = %d on %d tokens", report.Engravings, report.EngravedTokens)
+	}
+	wantTags := []TagCount{{Tag: "TITLE", Official: true, Engravings: 2, Tokens: 1}, {Tag: "NOTE", Engravings: 1, Tokens: 1}}
+	if len(report.Tags) != 2 || report.Tags[0] != wantTags[0] || report.Tags[1] != wantTags[1] {
+		t.Errorf("tags = %+v", report.Tags)
+	}
+	if report.MostEngraved[0] != (TokenCount{TokenID: "1", Engravings: 2}) {
+		t.Errorf("most engraved = %+v", report.MostEngraved)
+	}
+	// Each mint is valued at what it paid, the constructor mints at block 1 are free
+	if report.PaidMints != 2 || report.MintRevenue != "16" || report.UnknownMints != 0 {
+		t.Errorf("paid mints = %d, revenue = %s, unknown = %d", report.PaidMints, report.MintRevenue, report.UnknownMints)
+	}
+	if markdown := report.Markdown(); !strings.Contains(markdown, "| NOTE | no | 1 | 1 |") {
+		t.Errorf("markdown is missing the unofficial tag:\n%s", markdown)
+	}
+}
+
+func TestReadMintPayments(t *testing.T) {
+	ethrx, token, other := new(felt.Felt).SetUint64(0xe7), new(felt.Felt).SetUint64(0x57), new(felt.Felt).SetUint64(0x58)
+	alice, owner, sequencer := new(felt.Felt).SetUint64(0xa), new(felt.Felt).SetUint64(0x0f), new(felt.Felt).SetUint64(0x5e)
+	owners := map[string]bool{owner.String(): true}
+	transfer := utils.GetSelectorFromNameFelt("Transfer")
+	u256 := func(v uint64) []*felt.Felt { return encodeU256(new(big.Int).SetUint64(v)) }
+
+	mint := rpc.Event{FromAddress: ethrx, EventContent: rpc.EventContent{Keys: append([]*felt.Felt{transfer, &felt.Zero, alice}, u256(1)...)}}
+	engrave := rpc.Event{FromAddress: ethrx, EventContent: rpc.EventContent{Keys: []*felt.Felt{utils.GetSelectorFromNameFelt("ArtifactEngraved")}}}
+	approval := rpc.Event{FromAddress: token, EventContent: rpc.EventContent{Keys: []*felt.Felt{utils.GetSelectorFromNameFelt("Approval"), alice, ethrx}, Data: u256(0)}}
+	pay := func(from *felt.Felt, amount uint64) rpc.Event {
+		return rpc.Event{FromAddress: from, EventContent: rpc.EventContent{Keys: []*felt.Felt{transfer, alice, owner}, Data: u256(amount)}}
+	}
+	// The fee Transfer ends every receipt, in STRK like the usual mint token
+	fee := rpc.Event{FromAddress: token, EventContent: rpc.EventContent{Keys: []*felt.Felt{transfer, alice, sequencer}, Data: u256(900)}}
+	// The UDC deploys the contract after its constructor ran
+	deployed := rpc.Event{FromAddress: new(felt.Felt).SetUint64(0x41a), EventContent: rpc.EventContent{Keys: []*felt.Felt{utils.GetSelectorFromNameFelt("ContractDeployed")}}}
+	// ERC721 transfers of another collection have the token ID in the keys
+	nft := rpc.Event{FromAddress: other, EventContent: rpc.EventContent{Keys: append([]*felt.Felt{transfer, alice, owner}, u256(3)...)}}
+	// Cairo 0 tokens have from and to in the data
+	payCairo0 := rpc.Event{FromAddress: token, EventContent: rpc.EventContent{Keys: []*felt.Felt{transfer}, Data: append([]*felt.Felt{alice, owner}, u256(5)...)}}
+
+	tests := []struct {
+		name    string
+		events  []rpc.Event
+		paid    int
+		unknown int
+		revenue int64
+		wantErr string
+	}{
+		{name: "paid mint", events: []rpc.Event{mint, approval, pay(token, 7), fee}, paid: 1, revenue: 7},
+		{name: "two recipients", events: []rpc.Event{mint, mint, pay(token, 14), mint, pay(token, 9), fee}, paid: 3, revenue: 23},
+		// The constructor engraves without events
+		{name: "constructor mints", events: []rpc.Event{mint, mint, mint, deployed, fee}},
+		// mint skips transfer_from when the cost is 0
+		{name: "zero price", events: []rpc.Event{mint, fee}},
+		{name: "transfer after another Ethrx event", events: []rpc.Event{mint, engrave, pay(token, 7), fee}},
+		{name: "NFT transfer before the payment", events: []rpc.Event{mint, nft, pay(token, 7), fee}, paid: 1, revenue: 7},
+		{name: "Cairo 0 mint token", events: []rpc.Event{mint, payCairo0, fee}, paid: 1, revenue: 5},
+		{name: "another token", events: []rpc.Event{mint, mint, pay(other, 7), fee}, unknown: 2},
+		{
+			name:    "corrupt payment",
+			events:  []rpc.Event{mint, {FromAddress: token, EventContent: rpc.EventContent{Keys: []*felt.Felt{transfer, alice, owner}}}},
+			wantErr: "invalid Transfer event of 0x57",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments, err := readMintPayments(tt.events, ethrx, token, owners)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if payments.paid != tt.paid || payments.unknown != tt.unknown || payments.revenue.Int64() != tt.revenue {
+				t.Errorf("paid %d, unknown %d, revenue %s; want %d, %d, %d", payments.paid, payments.unknown, payments.revenue, tt.paid, tt.unknown, tt.revenue)
+			}
+		})
+	}
+}
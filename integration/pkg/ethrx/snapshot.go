@@ -0,0 +1,312 @@
+package ethrx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+// SnapshotClient is the RPC access needed to take a snapshot; *rpc.Provider implements it
+type SnapshotClient interface {
+	Caller
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockWithTxHashes(ctx context.Context, blockID rpc.BlockID) (interface{}, error)
+	ClassHashAt(ctx context.Context, blockID rpc.BlockID, contractAddress *felt.Felt) (*felt.Felt, error)
+}
+
+// SnapshotOptions controls how a snapshot is read
+type SnapshotOptions struct {
+	// Block is the block to read at; zero pins the latest block when the snapshot starts
+	Block uint64
+	// ChunkSize is the initial number of tokens per array call (default 100); it is
+	// halved when calls hit the node's resource limits
+	ChunkSize int
+	// Workers is the number of concurrent single-token calls (default 8)
+	Workers int
+	// Logger receives progress messages; nil logs nothing
+	Logger *logrus.Logger
+}
+
+// Token is the state of one token at the snapshot block
+type Token struct {
+	TokenID    string            `json:"token_id"`
+	Owner      string            `json:"owner"`
+	ArtifactID string            `json:"artifact_id"`
+	TagNonces  map[string]uint64 `json:"tag_nonces"`
+	Artifact   Artifact          `json:"artifact"`
+}
+
+// Snapshot is the state of the whole collection at one block
+type Snapshot struct {
+	Contract       string    `json:"contract"`
+	ClassHash      string    `json:"class_hash"`
+	BlockNumber    uint64    `json:"block_number"`
+	BlockHash      string    `json:"block_hash"`
+	BlockTimestamp time.Time `json:"block_timestamp"`
+
+	Owner          string   `json:"owner"`
+	Version        uint64   `json:"version"`
+	IsMinting      bool     `json:"is_minting"`
+	MintToken      string   `json:"mint_token"`
+	MintPrice      string   `json:"mint_price"`
+	MaxSupply      string   `json:"max_supply"`
+	TotalSupply    string   `json:"total_supply"`
+	TotalArtifacts string   `json:"total_artifacts"`
+	OfficialTags   []string `json:"official_tags"`
+
+	Tokens []Token `json:"tokens"`
+}
+
+// TakeSnapshot reads every token of the Ethrx contract at address, with its owner,
+// artifact ID, tag nonces and current artifact, all at the same block
+func TakeSnapshot(ctx context.Context, client SnapshotClient, address *felt.Felt, opts SnapshotOptions) (*Snapshot, error) {
+	opts = opts.withDefaults()
+	log := opts.Logger
+
+	blockNumber := opts.Block
+	if blockNumber == 0 {
+		latest, err := client.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the latest block: %w", err)
+		}
+		blockNumber = latest
+	}
+	block, err := blockHeader(ctx, client, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	blockID := rpc.WithBlockNumber(blockNumber)
+	log.Infof("📌 Reading at block %d (%s)", block.Number, block.Hash.String())
+
+	classHash, err := client.ClassHashAt(ctx, blockID, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the class hash of %s: %w", address.String(), err)
+	}
+
+	snapshot := &Snapshot{
+		Contract:       address.String(),
+		ClassHash:      classHash.String(),
+		BlockNumber:    block.Number,
+		BlockHash:      block.Hash.String(),
+		BlockTimestamp: time.Unix(int64(block.Timestamp), 0).UTC(),
+	}
+
+	reader := NewReader(client, address).At(blockID)
+	tags, err := readSettings(ctx, reader, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenIDs, err := enumerateTokens(ctx, reader, snapshot.TotalSupply, opts)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("🔢 %d tokens", len(tokenIDs))
+
+	snapshot.Tokens, err = readTokens(ctx, reader, tokenIDs, tags, opts)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (o SnapshotOptions) withDefaults() SnapshotOptions {
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = 100
+	}
+	if o.Workers <= 0 {
+		o.Workers = 8
+	}
+	if o.Logger == nil {
+		o.Logger = logrus.New()
+		o.Logger.SetOutput(io.Discard)
+	}
+	return o
+}
+
+// blockHeader returns the header of an accepted block
+func blockHeader(ctx context.Context, client SnapshotClient, number uint64) (*rpc.BlockHeader, error) {
+	result, err := client.BlockWithTxHashes(ctx, rpc.WithBlockNumber(number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %d: %w", number, err)
+	}
+	block, ok := result.(*rpc.BlockTxHashes)
+	if !ok {
+		return nil, fmt.Errorf("block %d is not accepted yet", number)
+	}
+	return &block.BlockHeader, nil
+}
+
+// readSettings fills in the contract-level values and returns the official tags
+func readSettings(ctx context.Context, reader *Reader, snapshot *Snapshot) ([]*felt.Felt, error) {
+	owner, err := reader.Owner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, err := reader.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	isMinting, err := reader.IsMinting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mintToken, err := reader.MintToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mintPrice, err := reader.MintPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	maxSupply, err := reader.MaxSupply(ctx)
+	if err != nil {
+		return nil, err
+	}
+	totalSupply, err := reader.TotalSupply(ctx)
+	if err != nil {
+		return nil, err
+	}
+	totalArtifacts, err := reader.TotalArtifacts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := reader.OfficialTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Owner = owner.String()
+	snapshot.Version = version
+	snapshot.IsMinting = isMinting
+	snapshot.MintToken = mintToken.String()
+	snapshot.MintPrice = mintPrice.String()
+	snapshot.MaxSupply = maxSupply.String()
+	snapshot.TotalSupply = totalSupply.String()
+	snapshot.TotalArtifacts = totalArtifacts.Text(10)
+	for _, tag := range tags {
+		snapshot.OfficialTags = append(snapshot.OfficialTags, TagName(tag))
+	}
+	return tags, nil
+}
+
+// enumerateTokens lists all token IDs through token_by_index, sorted by ID
+func enumerateTokens(ctx context.Context, reader *Reader, totalSupply string, opts SnapshotOptions) ([]*big.Int, error) {
+	total, _ := new(big.Int).SetString(totalSupply, 10)
+	if !total.IsInt64() {
+		return nil, fmt.Errorf("total supply %s is too large to enumerate", totalSupply)
+	}
+
+	tokenIDs := make([]*big.Int, total.Int64())
+	err := forEach(ctx, len(tokenIDs), opts.Workers, func(ctx context.Context, i int) error {
+		id, err := reader.TokenByIndex(ctx, big.NewInt(int64(i)))
+		if err != nil {
+			return fmt.Errorf("token index %d: %w", i, err)
+		}
+		tokenIDs[i] = id
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(tokenIDs, func(i, j int) bool { return tokenIDs[i].Cmp(tokenIDs[j]) < 0 })
+	return tokenIDs, nil
+}
+
+// readTokens reads owners one call per token and the artifact state through a BatchReader
+func readTokens(ctx context.Context, reader *Reader, tokenIDs []*big.Int, tags []*felt.Felt, opts SnapshotOptions) ([]Token, error) {
+	tokens := make([]Token, len(tokenIDs))
+	for i, id := range tokenIDs {
+		tokens[i] = Token{TokenID: id.String(), TagNonces: make(map[string]uint64, len(tags))}
+	}
+
+	err := forEach(ctx, len(tokenIDs), opts.Workers, func(ctx context.Context, i int) error {
+		owner, err := reader.OwnerOf(ctx, tokenIDs[i])
+		if err != nil {
+			return fmt.Errorf("token %s: %w", tokenIDs[i], err)
+		}
+		tokens[i].Owner = owner.String()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	opts.Logger.Info("👤 Owners read")
+
+	batch := NewBatchReader(reader, BatchOptions{ChunkSize: opts.ChunkSize, Workers: opts.Workers, Logger: opts.Logger})
+	artifactIDs, err := batch.ArtifactIDs(ctx, tokenIDs)
+	if err != nil {
+		return nil, err
+	}
+	artifacts, err := batch.Artifacts(ctx, tokenIDs)
+	if err != nil {
+		return nil, err
+	}
+	nonces, err := batch.TagNonces(ctx, artifactIDs, tags)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tokens {
+		tokens[i].ArtifactID = artifactIDs[i].String()
+		tokens[i].Artifact = artifacts[i]
+		for j, tag := range tags {
+			tokens[i].TagNonces[TagName(tag)] = nonces[i][j]
+		}
+	}
+	opts.Logger.Info("🎨 Artifacts read")
+
+	return tokens, nil
+}
+
+// forEach runs fn for 0..n-1 on up to workers goroutines and returns the first error,
+// cancelling the remaining calls
+func forEach(ctx context.Context, n, workers int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	indexes := make(chan int)
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,89 @@
+package ethrx
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+func TestTakeSnapshot(t *testing.T) {
+	alice, bob := new(felt.Felt).SetUint64(0xa), new(felt.Felt).SetUint64(0xb)
+	tx := func(n uint64) *felt.Felt { return new(felt.Felt).SetUint64(n) }
+	title, _ := TagFelt("TITLE")
+	note, _ := TagFelt("NOTE")
+
+	chain := newPrunedChain()
+	chain.tags = []*felt.Felt{title, note}
+	chain.mint(1, tx(1), alice, 1)
+	chain.mint(2, tx(2), alice, 2)
+	chain.mint(3, tx(3), bob, 3)
+	chain.engrave(4, tx(4), 1, "TITLE", "first")
+	chain.engrave(5, tx(5), 1, "TITLE", "Binary")
+	chain.engrave(6, tx(6), 3, "NOTE", "\xff\x00")
+	chain.transfer(7, tx(7), alice, bob, 1, true)
+	chain.latest, chain.version, chain.isMinting, chain.mintPrice = 40, 2, true, 7
+
+	// Chunks of two split the three tokens over several batch calls
+	snapshot, err := TakeSnapshot(t.Context(), chain, chain.contract, SnapshotOptions{ChunkSize: 2, Workers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snapshot.BlockNumber != 40 || snapshot.ClassHash != chain.classHash.String() || snapshot.BlockTimestamp.Unix() != 1700000040 {
+		t.Errorf("block = %d, class = %s, timestamp = %s", snapshot.BlockNumber, snapshot.ClassHash, snapshot.BlockTimestamp)
+	}
+	if snapshot.Owner != chain.owner.String() || snapshot.Version != 2 || !snapshot.IsMinting || snapshot.MintPrice != "7" ||
+		snapshot.MintToken != chain.mintToken.String() || snapshot.TotalSupply != "3" || snapshot.TotalArtifacts != "3" {
+		t.Errorf("settings = %+v", snapshot)
+	}
+	if !reflect.DeepEqual(snapshot.OfficialTags, []string{"TITLE", "NOTE"}) {
+		t.Errorf("official tags = %v", snapshot.OfficialTags)
+	}
+
+	want := []Token{
+		{
+			TokenID: "1", Owner: bob.String(), ArtifactID: "0x1",
+			TagNonces: map[string]uint64{"TITLE": 2, "NOTE": 0},
+			Artifact:  Artifact{{Tag: "TITLE", Data: []byte("Binary")}, {Tag: "NOTE", Data: []byte{}}},
+		},
+		{
+			TokenID: "2", Owner: alice.String(), ArtifactID: "0x2",
+			TagNonces: map[string]uint64{"TITLE": 0, "NOTE": 0},
+			Artifact:  Artifact{{Tag: "TITLE", Data: []byte{}}, {Tag: "NOTE", Data: []byte{}}},
+		},
+		{
+			TokenID: "3", Owner: bob.String(), ArtifactID: "0x3",
+			TagNonces: map[string]uint64{"TITLE": 0, "NOTE": 1},
+			Artifact:  Artifact{{Tag: "TITLE", Data: []byte{}}, {Tag: "NOTE", Data: []byte{0xff, 0x00}}},
+		},
+	}
+	if !reflect.DeepEqual(snapshot.Tokens, want) {
+		t.Errorf("tokens = %+v\nwant %+v", snapshot.Tokens, want)
+	}
+
+	// The saved snapshot loads back unchanged
+	dir := t.TempDir()
+	if _, err := WriteExport(dir, "testnet", snapshot); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSnapshot(filepath.Join(dir, SnapshotFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.BlockTimestamp = snapshot.BlockTimestamp
+	if !reflect.DeepEqual(loaded, snapshot) {
+		t.Errorf("loaded snapshot = %+v\nwant %+v", loaded, snapshot)
+	}
+}
+
+func TestTakeSnapshotAtPrunedBlock(t *testing.T) {
+	chain := newPrunedChain()
+	chain.mint(1, new(felt.Felt).SetUint64(1), new(felt.Felt).SetUint64(0xa), 1)
+	chain.latest, chain.prunedBelow = 40, 10
+
+	if _, err := TakeSnapshot(t.Context(), chain, chain.contract, SnapshotOptions{Block: 5}); err == nil {
+		t.Error("snapshot taken at a pruned block")
+	}
+}
@@ -0,0 +1,367 @@
+package ethrx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/deploy"
+	"github.com/NovemberFork/etheracts/integration/pkg/metrics"
+)
+
+// HistoryClient is the RPC access needed to read past Ethrx state; *rpc.Provider implements it
+type HistoryClient interface {
+	SnapshotClient
+	EventClient
+}
+
+// Where the state of a TokenAt or the change of an Alert comes from
+const (
+	SourceState  = "state"
+	SourceEvents = "events"
+)
+
+// TokenAt is the state of a token at the end of a past block
+type TokenAt struct {
+	TokenID    string            `json:"token_id"`
+	Block      uint64            `json:"block"`
+	Owner      string            `json:"owner"`
+	ArtifactID string            `json:"artifact_id"`
+	TagNonces  map[string]uint64 `json:"tag_nonces"`
+	Artifact   Artifact          `json:"artifact"`
+	// Source is SourceState when read from the node at Block, SourceEvents when rebuilt
+	// from events because the node no longer has that state
+	Source string `json:"source"`
+}
+
+// AtBlockOptions controls how past token state is read
+type AtBlockOptions struct {
+	// FromBlock is the first block scanned when rebuilding state from events, such as
+	// the contract deployment block (default 0)
+	FromBlock uint64
+	// Batch controls the array calls
+	Batch BatchOptions
+	// Logger receives progress messages; nil logs nothing
+	Logger *logrus.Logger
+	// Metrics records cache lookups; nil records nothing
+	Metrics *metrics.Metrics
+}
+
+// TokensAt returns the owner and official artifact of each token at the end of block.
+//
+// The reads are made at block first. When the node has pruned that state, they are
+// rebuilt instead: artifact IDs and tag nonces at block are derived from the Transfer and
+// ArtifactEngraved events since opts.FromBlock, and the engravings, which are never
+// overwritten, are read at the latest block with get_historic_artifacts.
+func TokensAt(ctx context.Context, client HistoryClient, address *felt.Felt, tokenIDs []*big.Int, block uint64, opts AtBlockOptions) ([]TokenAt, error) {
+	if opts.Logger == nil {
+		opts.Logger = logrus.New()
+		opts.Logger.SetOutput(io.Discard)
+	}
+	if opts.Batch.Logger == nil {
+		opts.Batch.Logger = opts.Logger
+	}
+	if opts.Batch.Workers <= 0 {
+		opts.Batch.Workers = 8
+	}
+
+	tokens, err := tokensFromState(ctx, client, address, tokenIDs, block, opts)
+	if err == nil || !IsStateUnavailable(err) {
+		return tokens, err
+	}
+	opts.Logger.Warnf("⚠️  State at block %d is not available (%s), rebuilding it from events", block, err)
+	return tokensFromEvents(ctx, client, address, tokenIDs, block, opts)
+}
+
+// prunedStateErrors are fragments of the unexpected-error data nodes return for calls at
+// blocks whose state they have pruned
+var prunedStateErrors = []string{
+	"pruned",
+	"state is unavailable",
+	"no state",
+}
+
+// IsStateUnavailable reports whether err means the node has no state for the requested
+// block: a block not found error, or an unexpected error about pruned state
+func IsStateUnavailable(err error) bool {
+	nodeErr, ok := deploy.AsNodeError(err)
+	if !ok {
+		return false
+	}
+
+	switch nodeErr.Code {
+	case rpc.ErrBlockNotFound.Code:
+		return true
+	case rpc.ErrUnexpectedError.Code:
+		details := strings.ToLower(nodeErr.Message + " " + nodeErr.Data)
+		for _, fragment := range prunedStateErrors {
+			if strings.Contains(details, fragment) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tokensFromState reads the tokens with calls pinned to block
+func tokensFromState(ctx context.Context, client HistoryClient, address *felt.Felt, tokenIDs []*big.Int, block uint64, opts AtBlockOptions) ([]TokenAt, error) {
+	reader := NewReader(client, address).At(rpc.WithBlockNumber(block))
+	tags, err := reader.OfficialTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := NewBatchReader(reader, opts.Batch)
+	artifactIDs, err := batch.ArtifactIDs(ctx, tokenIDs)
+	if err != nil {
+		return nil, err
+	}
+	nonces, err := batch.TagNonces(ctx, artifactIDs, tags)
+	if err != nil {
+		return nil, err
+	}
+	artifacts, err := batch.Artifacts(ctx, tokenIDs)
+	if err != nil {
+		return nil, err
+	}
+	owners := make([]*felt.Felt, len(tokenIDs))
+	err = forEach(ctx, len(tokenIDs), opts.Batch.Workers, func(ctx context.Context, i int) error {
+		owner, err := reader.OwnerOf(ctx, tokenIDs[i])
+		if err != nil {
+			return fmt.Errorf("token %s: %w", tokenIDs[i], err)
+		}
+		owners[i] = owner
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]TokenAt, len(tokenIDs))
+	for i, id := range tokenIDs {
+		tokens[i] = newTokenAt(id, block, SourceState, owners[i], artifactIDs[i], tags, nonces[i], artifacts[i])
+	}
+	return tokens, nil
+}
+
+// tokensFromEvents rebuilds the tokens at block from the events up to the latest block
+func tokensFromEvents(ctx context.Context, client HistoryClient, address *felt.Felt, tokenIDs []*big.Int, block uint64, opts AtBlockOptions) ([]TokenAt, error) {
+	latest, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest block: %w", err)
+	}
+	if block > latest {
+		return nil, fmt.Errorf("block %d is after the latest block %d", block, latest)
+	}
+	if block < opts.FromBlock {
+		return nil, fmt.Errorf("block %d is before the first scanned block %d", block, opts.FromBlock)
+	}
+
+	opts.Logger.Infof("🔎 Reading events of blocks %d-%d", opts.FromBlock, latest)
+	events, err := ReadEvents(ctx, client, address, opts.FromBlock, latest,
+		EventTransfer, EventArtifactEngraved, EventTagRegistered, EventTagReregistered)
+	if err != nil {
+		return nil, err
+	}
+
+	head := NewReader(client, address).At(rpc.WithBlockNumber(latest))
+	assigned, err := assignArtifactIDs(ctx, NewTransactions(client, address).WithMetrics(opts.Metrics), head, events)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := tagsAt(ctx, head, events, block)
+	if err != nil {
+		return nil, err
+	}
+	batch := NewBatchReader(head, opts.Batch)
+	latestIDs, err := batch.ArtifactIDs(ctx, tokenIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	artifactIDs := make([]*felt.Felt, len(tokenIDs))
+	owners := make([]*felt.Felt, len(tokenIDs))
+	later := make([]map[string]uint64, len(tokenIDs))
+	for i, id := range tokenIDs {
+		lastTransfer, nextTransfer, lastWipe, nextWipe := -1, -1, -1, -1
+		for j, event := range events {
+			if event.Kind != EventTransfer || event.TokenID.Cmp(id) != 0 {
+				continue
+			}
+			_, wipes := assigned[j]
+			if event.BlockNumber <= block {
+				lastTransfer = j
+				if wipes {
+					lastWipe = j
+				}
+				continue
+			}
+			if nextTransfer < 0 {
+				nextTransfer = j
+			}
+			if wipes && nextWipe < 0 {
+				nextWipe = j
+			}
+		}
+
+		if lastTransfer < 0 && nextTransfer >= 0 {
+			if opts.FromBlock == 0 {
+				return nil, fmt.Errorf("token %s was not minted at block %d", id, block)
+			}
+			return nil, fmt.Errorf("token %s: its owner at block %d was set before block %d", id, block, opts.FromBlock)
+		}
+		switch {
+		case lastWipe >= 0:
+			artifactIDs[i] = assigned[lastWipe]
+		case nextWipe < 0:
+			artifactIDs[i] = latestIDs[i]
+		default:
+			return nil, fmt.Errorf("token %s: its artifact at block %d was assigned before block %d", id, block, opts.FromBlock)
+		}
+		if lastTransfer >= 0 {
+			owners[i] = events[lastTransfer].To
+		} else if owners[i], err = head.OwnerOf(ctx, id); err != nil {
+			return nil, err
+		}
+
+		// Engravings made on the same artifact after block are undone from the latest nonces
+		later[i] = make(map[string]uint64)
+		for j, event := range events {
+			if nextWipe >= 0 && j >= nextWipe {
+				break
+			}
+			if event.Kind == EventArtifactEngraved && event.TokenID.Cmp(id) == 0 && event.BlockNumber > block {
+				later[i][event.New.Tag]++
+			}
+		}
+	}
+
+	nonces, err := batch.TagNonces(ctx, artifactIDs, tags)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tokenIDs {
+		for j, tag := range tags {
+			undo := later[i][TagName(tag)]
+			if undo > nonces[i][j] {
+				return nil, fmt.Errorf("token %s: %d engravings of %s after block %d, but its nonce is %d", tokenIDs[i], undo, TagName(tag), block, nonces[i][j])
+			}
+			nonces[i][j] -= undo
+		}
+	}
+
+	artifacts := make([]Artifact, len(tokenIDs))
+	err = forEach(ctx, len(tokenIDs), opts.Batch.Workers, func(ctx context.Context, i int) error {
+		result, err := head.HistoricArtifacts(ctx, artifactIDs[i:i+1], [][]*felt.Felt{tags}, [][]uint64{nonces[i]})
+		if err != nil {
+			return fmt.Errorf("token %s: %w", tokenIDs[i], err)
+		}
+		artifacts[i] = result[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]TokenAt, len(tokenIDs))
+	for i, id := range tokenIDs {
+		tokens[i] = newTokenAt(id, block, SourceEvents, owners[i], artifactIDs[i], tags, nonces[i], artifacts[i])
+	}
+	return tokens, nil
+}
+
+// assignArtifactIDs returns the artifact ID assigned by each wiping Transfer, keyed by
+// event index. Artifact IDs come from a counter bumped by every wipe, so they are
+// numbered back from the counter's latest value.
+func assignArtifactIDs(ctx context.Context, txs *Transactions, head *Reader, events []Event) (map[int]*felt.Felt, error) {
+	var wipes []int
+	for i, event := range events {
+		if event.Kind != EventTransfer {
+			continue
+		}
+		wiped, err := txs.Wipes(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+		if wiped {
+			wipes = append(wipes, i)
+		}
+	}
+
+	total, err := head.TotalArtifacts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	next := new(big.Int).Sub(total.BigInt(new(big.Int)), big.NewInt(int64(len(wipes))))
+	if next.Sign() < 0 {
+		return nil, fmt.Errorf("found %d artifact wipes but total_artifacts is %s", len(wipes), total.Text(10))
+	}
+
+	assigned := make(map[int]*felt.Felt, len(wipes))
+	for _, i := range wipes {
+		next.Add(next, big.NewInt(1))
+		assigned[i] = new(felt.Felt).SetBigInt(next)
+	}
+	return assigned, nil
+}
+
+// tagsAt returns the official tags at block by undoing the later tag events on the
+// latest official tags
+func tagsAt(ctx context.Context, head *Reader, events []Event, block uint64) ([]*felt.Felt, error) {
+	latest, err := head.OfficialTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(latest))
+	for i, tag := range latest {
+		names[i] = TagName(tag)
+	}
+
+	for i := len(events) - 1; i >= 0 && events[i].BlockNumber > block; i-- {
+		switch event := events[i]; event.Kind {
+		case EventTagRegistered:
+			if n := len(names); n > 0 && names[n-1] == event.Tag {
+				names = names[:n-1]
+			}
+		case EventTagReregistered:
+			for j, name := range names {
+				if name == event.Tag {
+					names[j] = event.OldTag
+					break
+				}
+			}
+		}
+	}
+
+	tags := make([]*felt.Felt, len(names))
+	for i, name := range names {
+		if tags[i], err = TagFelt(name); err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+func newTokenAt(id *big.Int, block uint64, source string, owner, artifactID *felt.Felt, tags []*felt.Felt, nonces []uint64, artifact Artifact) TokenAt {
+	token := TokenAt{
+		TokenID:    id.String(),
+		Block:      block,
+		ArtifactID: artifactID.String(),
+		TagNonces:  make(map[string]uint64, len(tags)),
+		Artifact:   artifact,
+		Source:     source,
+	}
+	if owner != nil {
+		token.Owner = owner.String()
+	}
+	for j, tag := range tags {
+		token.TagNonces[TagName(tag)] = nonces[j]
+	}
+	return token
+}
@@ -0,0 +1,102 @@
+package ethrx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+)
+
+func TestTokensAtRebuildsPrunedStateFromEvents(t *testing.T) {
+	alice, bob := new(felt.Felt).SetUint64(0xa), new(felt.Felt).SetUint64(0xb)
+	title, _ := TagFelt("TITLE")
+	tx := func(n uint64) *felt.Felt { return new(felt.Felt).SetUint64(n) }
+
+	chain := newPrunedChain()
+	chain.tags = []*felt.Felt{title}
+	chain.transfer(1, tx(1), &felt.Zero, alice, 1, false)
+	chain.transfer(1, tx(1), &felt.Zero, alice, 2, false)
+	chain.engrave(10, tx(2), 1, "TITLE", "first")
+	chain.transfer(20, tx(3), alice, bob, 1, false)
+	chain.transfer(25, tx(4), alice, bob, 2, true)
+	chain.engrave(30, tx(5), 1, "TITLE", "after the wipe")
+	chain.engrave(30, tx(5), 2, "TITLE", "kept")
+	chain.latest, chain.prunedBelow = 40, 35
+
+	for _, tc := range []struct {
+		block      uint64
+		token      int64
+		owner      *felt.Felt
+		artifactID uint64
+		title      string
+	}{
+		{15, 1, alice, 1, "first"},
+		{15, 2, alice, 2, ""},
+		{22, 1, bob, 3, ""},
+		{26, 2, bob, 2, ""},
+		{30, 1, bob, 3, "after the wipe"},
+		{30, 2, bob, 2, "kept"},
+	} {
+		tokens, err := TokensAt(t.Context(), chain, chain.contract, []*big.Int{big.NewInt(tc.token)}, tc.block, AtBlockOptions{})
+		if err != nil {
+			t.Fatalf("token %d at block %d: %v", tc.token, tc.block, err)
+		}
+		got := tokens[0]
+		data, _ := got.Artifact.Get("TITLE")
+		if got.Source != SourceEvents || got.Owner != tc.owner.String() || got.ArtifactID != new(felt.Felt).SetUint64(tc.artifactID).String() || string(data) != tc.title {
+			t.Errorf("token %d at block %d = %+v, want owner %s, artifact %d titled %q", tc.token, tc.block, got, tc.owner, tc.artifactID, tc.title)
+		}
+	}
+
+	tokens, err := TokensAt(t.Context(), chain, chain.contract, []*big.Int{big.NewInt(1)}, 38, AtBlockOptions{})
+	if err != nil || tokens[0].Source != SourceState {
+		t.Fatalf("block 38: %+v, %v; want a state read", tokens, err)
+	}
+}
+
+func TestTokensAtKeepsArtifactsSavedThroughMultisig(t *testing.T) {
+	alice, signer, multisig := new(felt.Felt).SetUint64(0xa), new(felt.Felt).SetUint64(0x516), new(felt.Felt).SetUint64(0x3a1)
+	title, _ := TagFelt("TITLE")
+	tx := func(n uint64) *felt.Felt { return new(felt.Felt).SetUint64(n) }
+
+	chain := newPrunedChain()
+	chain.tags = []*felt.Felt{title}
+	chain.mint(1, tx(1), multisig, 1)
+	chain.engrave(5, tx(2), 1, "TITLE", "kept")
+	chain.routedTransfer(10, tx(3), signer, multisig, alice, 1, true)
+	chain.latest, chain.prunedBelow = 40, 35
+
+	tokens, err := TokensAt(t.Context(), chain, chain.contract, []*big.Int{big.NewInt(1)}, 20, AtBlockOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := tokens[0].Artifact.Get("TITLE")
+	if tokens[0].Owner != alice.String() || tokens[0].ArtifactID != "0x1" || string(data) != "kept" {
+		t.Errorf("token = %+v, want the artifact kept by the multisig transfer", tokens[0])
+	}
+}
+
+func TestIsStateUnavailable(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"block not found", rpc.ErrBlockNotFound, true},
+		{"wrapped", fmt.Errorf("official_tags failed: %w", rpc.ErrBlockNotFound), true},
+		{"pruned state", rpc.Err(63, rpc.StringErrData("An unexpected error occurred Historic state is pruned")), true},
+		{"unexpected error about something else", rpc.Err(63, rpc.StringErrData("An unexpected error occurred database is locked")), false},
+		{"contract not deployed yet", rpc.ErrContractNotFound, false},
+		{"contract error", &rpc.RPCError{Code: 40, Message: "Contract error", Data: &rpc.ContractErrData{RevertError: rpc.ContractExecutionError{Message: "Token not available"}}}, false},
+		{"transport", &rpc.RPCError{Code: rpc.InternalError, Message: "The error is not a valid RPC error", Data: rpc.StringErrData("502 Bad Gateway: no state")}, false},
+		{"not an RPC error", errors.New("block not found"), false},
+		{"nil", nil, false},
+	} {
+		if got := IsStateUnavailable(tc.err); got != tc.want {
+			t.Errorf("%s: IsStateUnavailable(%v) = %t, want %t", tc.name, tc.err, got, tc.want)
+		}
+	}
+}
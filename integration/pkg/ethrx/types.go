@@ -0,0 +1,95 @@
+// Package ethrx reads Ethrx collection state: tokens, owners and their engraved artifacts
+package ethrx
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/utils"
+)
+
+// Engraving is one tagged entry of an artifact, such as TITLE: "Binary"
+type Engraving struct {
+	Tag  string
+	Data []byte
+}
+
+// engravingJSON is the exported form of an engraving. Data holds the text when it is
+// valid UTF-8; DataHex always holds the exact bytes.
+type engravingJSON struct {
+	Tag     string `json:"tag"`
+	Data    string `json:"data"`
+	DataHex string `json:"data_hex"`
+}
+
+// MarshalJSON writes the engraving data both as text and as hex
+func (e Engraving) MarshalJSON() ([]byte, error) {
+	return json.Marshal(engravingJSON{Tag: e.Tag, Data: e.Text(), DataHex: "0x" + hex.EncodeToString(e.Data)})
+}
+
+// UnmarshalJSON reads an engraving, preferring the exact bytes from data_hex
+func (e *Engraving) UnmarshalJSON(data []byte) error {
+	var v engravingJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	e.Tag = v.Tag
+	if v.DataHex == "" {
+		e.Data = []byte(v.Data)
+		return nil
+	}
+	decoded, err := hex.DecodeString(strings.TrimPrefix(v.DataHex, "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid data_hex for tag %s: %w", v.Tag, err)
+	}
+	e.Data = decoded
+	return nil
+}
+
+// Text returns the data as a string, or empty if it is not valid UTF-8
+func (e Engraving) Text() string {
+	if !utf8.Valid(e.Data) {
+		return ""
+	}
+	return string(e.Data)
+}
+
+// Artifact is the collection of engravings of a token
+type Artifact []Engraving
+
+// Get returns the data engraved under tag
+func (a Artifact) Get(tag string) ([]byte, bool) {
+	for _, e := range a {
+		if e.Tag == tag {
+			return e.Data, true
+		}
+	}
+	return nil, false
+}
+
+// IsEmpty reports whether no tag holds any data
+func (a Artifact) IsEmpty() bool {
+	for _, e := range a {
+		if len(e.Data) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// TagName decodes a felt252 tag as a Cairo short string ('TITLE')
+func TagName(tag *felt.Felt) string {
+	return utils.HexToShortStr(tag.String())
+}
+
+// TagFelt encodes a tag name as a Cairo short string
+func TagFelt(tag string) (*felt.Felt, error) {
+	if tag == "" || len(tag) > 31 {
+		return nil, fmt.Errorf("invalid tag %q: must be 1-31 characters", tag)
+	}
+	return utils.HexToFelt(utils.StrToHex(tag))
+}
@@ -0,0 +1,473 @@
+package ethrx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/metrics"
+)
+
+// Owner-level settings of an Ethrx contract compared by the watcher
+const (
+	SettingOwner        = "owner"
+	SettingClassHash    = "class_hash"
+	SettingVersion      = "version"
+	SettingIsMinting    = "is_minting"
+	SettingMintToken    = "mint_token"
+	SettingMintPrice    = "mint_price"
+	SettingOfficialTags = "official_tags"
+)
+
+// ownerEvents are the events emitted by owner-only entrypoints, with the settings they change
+var ownerEvents = map[EventKind][]string{
+	EventOwnershipTransferred: {SettingOwner},
+	EventUpgraded:             {SettingClassHash, SettingVersion},
+	EventTagRegistered:        {SettingOfficialTags},
+	EventTagReregistered:      {SettingOfficialTags},
+}
+
+// OwnerState is the owner-controlled configuration of an Ethrx contract at a block
+type OwnerState struct {
+	BlockNumber  uint64   `json:"block_number"`
+	Owner        string   `json:"owner"`
+	ClassHash    string   `json:"class_hash"`
+	Version      uint64   `json:"version"`
+	IsMinting    bool     `json:"is_minting"`
+	MintToken    string   `json:"mint_token"`
+	MintPrice    string   `json:"mint_price"`
+	OfficialTags []string `json:"official_tags"`
+}
+
+// ReadOwnerState reads the owner-controlled configuration of the contract at block
+func ReadOwnerState(ctx context.Context, client SnapshotClient, address *felt.Felt, block uint64) (*OwnerState, error) {
+	blockID := rpc.WithBlockNumber(block)
+	classHash, err := client.ClassHashAt(ctx, blockID, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the class hash of %s: %w", address.String(), err)
+	}
+
+	var snapshot Snapshot
+	if _, err := readSettings(ctx, NewReader(client, address).At(blockID), &snapshot); err != nil {
+		return nil, err
+	}
+	return &OwnerState{
+		BlockNumber:  block,
+		Owner:        snapshot.Owner,
+		ClassHash:    classHash.String(),
+		Version:      snapshot.Version,
+		IsMinting:    snapshot.IsMinting,
+		MintToken:    snapshot.MintToken,
+		MintPrice:    snapshot.MintPrice,
+		OfficialTags: snapshot.OfficialTags,
+	}, nil
+}
+
+// settings returns the comparable values of the state by setting name
+func (s *OwnerState) settings() [][2]string {
+	return [][2]string{
+		{SettingOwner, s.Owner},
+		{SettingClassHash, s.ClassHash},
+		{SettingVersion, strconv.FormatUint(s.Version, 10)},
+		{SettingIsMinting, strconv.FormatBool(s.IsMinting)},
+		{SettingMintToken, s.MintToken},
+		{SettingMintPrice, s.MintPrice},
+		{SettingOfficialTags, strings.Join(s.OfficialTags, ",")},
+	}
+}
+
+// Alert is an owner-level change of an Ethrx contract. Source is SourceEvents when an
+// owner-level event was emitted, with the event as Kind, and SourceState when a read found
+// a changed setting, with the setting as Kind. State alerts carry the block the settings
+// were read at, since the setters emit no event.
+type Alert struct {
+	Contract    string    `json:"contract"`
+	Network     string    `json:"network,omitempty"`
+	Source      string    `json:"source"`
+	Kind        string    `json:"kind"`
+	Old         string    `json:"old,omitempty"`
+	New         string    `json:"new,omitempty"`
+	BlockNumber uint64    `json:"block_number"`
+	TxHash      string    `json:"tx_hash,omitempty"`
+	DetectedAt  time.Time `json:"detected_at"`
+}
+
+// String describes the alert in one line
+func (a Alert) String() string {
+	switch {
+	case a.Old != "" && a.New != "":
+		return fmt.Sprintf("%s changed from %s to %s at block %d", a.Kind, a.Old, a.New, a.BlockNumber)
+	case a.New != "":
+		return fmt.Sprintf("%s: %s at block %d", a.Kind, a.New, a.BlockNumber)
+	default:
+		return fmt.Sprintf("%s at block %d", a.Kind, a.BlockNumber)
+	}
+}
+
+// WatchOptions controls a Watcher
+type WatchOptions struct {
+	// Network is recorded in the alerts
+	Network string
+	// FromBlock is the first block scanned when there is no saved state; zero starts at
+	// the latest block
+	FromBlock uint64
+	// StatePath keeps the last known state and undelivered alerts across restarts;
+	// empty keeps them in memory only
+	StatePath string
+	// Logger receives progress messages; nil logs nothing
+	Logger *logrus.Logger
+	// Metrics records the last processed block and event lag; nil records nothing
+	Metrics *metrics.Metrics
+}
+
+func (o WatchOptions) withDefaults() WatchOptions {
+	if o.Logger == nil {
+		o.Logger = logrus.New()
+		o.Logger.SetOutput(io.Discard)
+	}
+	return o
+}
+
+// Notifier delivers alerts, such as to a webhook or a file
+type Notifier interface {
+	Notify(ctx context.Context, alerts []Alert) error
+}
+
+// Notifiers are the destinations alerts are delivered to, by name. The names key the
+// undelivered alerts in the saved state, so they must stay the same across restarts.
+type Notifiers map[string]Notifier
+
+// watchState is what the watcher persists between runs
+type watchState struct {
+	Contract string      `json:"contract"`
+	Block    uint64      `json:"block"`
+	State    *OwnerState `json:"state"`
+	// Pending holds the alerts not yet delivered, by notifier name
+	Pending map[string][]Alert `json:"pending,omitempty"`
+}
+
+// Watcher polls an Ethrx contract for owner-level changes. Each poll that covers new
+// blocks reads the events of owner-only entrypoints (ownership transfers, upgrades, tag
+// registrations) and compares the settings at the latest block with the last known
+// state, which notices the setters that emit no event (mint price, mint token, minting
+// switch).
+type Watcher struct {
+	client  HistoryClient
+	address *felt.Felt
+	opts    WatchOptions
+
+	saved watchState
+}
+
+// NewWatcher creates a watcher, resuming from opts.StatePath when it exists
+func NewWatcher(client HistoryClient, address *felt.Felt, opts WatchOptions) (*Watcher, error) {
+	w := &Watcher{client: client, address: address, opts: opts.withDefaults()}
+	w.saved.Contract = address.String()
+	if w.opts.StatePath == "" {
+		return w, nil
+	}
+
+	data, err := os.ReadFile(w.opts.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return w, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watch state %s: %w", w.opts.StatePath, err)
+	}
+	var saved watchState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse watch state %s: %w", w.opts.StatePath, err)
+	}
+	if saved.Contract != address.String() {
+		return nil, fmt.Errorf("watch state %s is for contract %s", w.opts.StatePath, saved.Contract)
+	}
+	w.saved = saved
+	w.opts.Logger.Infof("📂 Resuming from block %d", saved.Block)
+	return w, nil
+}
+
+// State returns the last known owner state, nil before the first poll
+func (w *Watcher) State() *OwnerState {
+	return w.saved.State
+}
+
+// Poll scans the blocks since the previous poll and returns the alerts they raised. The
+// first poll without saved state only records the current state.
+func (w *Watcher) Poll(ctx context.Context) ([]Alert, error) {
+	log := w.opts.Logger
+	head, err := w.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest block: %w", err)
+	}
+
+	if w.saved.State == nil {
+		start := head
+		if w.opts.FromBlock != 0 {
+			start = min(w.opts.FromBlock, head)
+		}
+		state, err := ReadOwnerState(ctx, w.client, w.address, start)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("📌 Watching from block %d: owner %s, mint price %s, minting %t", start, state.Owner, state.MintPrice, state.IsMinting)
+		w.saved.State, w.saved.Block = state, start
+		w.opts.Metrics.SetProcessedBlock(start, head)
+		if start == head {
+			return nil, nil
+		}
+	}
+
+	from := w.saved.Block + 1
+	if from > head {
+		return nil, nil
+	}
+	w.opts.Metrics.SetProcessedBlock(w.saved.Block, head)
+
+	events, err := ReadEvents(ctx, w.client, w.address, from, head, EventOwnershipTransferred, EventUpgraded, EventTagRegistered, EventTagReregistered)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var alerts []Alert
+	explained := make(map[string]bool)
+	classHash := w.saved.State.ClassHash
+	for _, event := range events {
+		settings, ok := ownerEvents[event.Kind]
+		if !ok {
+			continue
+		}
+		for _, setting := range settings {
+			explained[setting] = true
+		}
+		alert := Alert{
+			Contract:    w.address.String(),
+			Network:     w.opts.Network,
+			Source:      SourceEvents,
+			Kind:        string(event.Kind),
+			BlockNumber: event.BlockNumber,
+			TxHash:      event.TxHash.String(),
+			DetectedAt:  now,
+		}
+		switch event.Kind {
+		case EventOwnershipTransferred:
+			alert.Old, alert.New = event.From.String(), event.To.String()
+		case EventUpgraded:
+			alert.Old, alert.New = classHash, event.ClassHash.String()
+			classHash = alert.New
+		case EventTagRegistered:
+			alert.New = event.Tag
+		case EventTagReregistered:
+			alert.Old, alert.New = event.OldTag, event.Tag
+		}
+		alerts = append(alerts, alert)
+	}
+
+	state, err := ReadOwnerState(ctx, w.client, w.address, head)
+	if err != nil {
+		return nil, err
+	}
+	alerts = append(alerts, w.compare(state, explained, now)...)
+	w.saved.State = state
+
+	w.saved.Block = head
+	w.opts.Metrics.SetProcessedBlock(head, head)
+	return alerts, nil
+}
+
+// compare returns an alert per setting that differs from the last known state, except
+// the ones already reported by an event
+func (w *Watcher) compare(state *OwnerState, explained map[string]bool, now time.Time) []Alert {
+	var alerts []Alert
+	before := w.saved.State.settings()
+	for i, after := range state.settings() {
+		setting, old, value := after[0], before[i][1], after[1]
+		if old == value || explained[setting] {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			Contract:    w.address.String(),
+			Network:     w.opts.Network,
+			Source:      SourceState,
+			Kind:        setting,
+			Old:         old,
+			New:         value,
+			BlockNumber: state.BlockNumber,
+			DetectedAt:  now,
+		})
+	}
+	return alerts
+}
+
+// Run calls Check every interval until ctx is cancelled. Failed checks are logged and
+// retried on the next interval.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, notifiers Notifiers, onAlert func(Alert)) {
+	log := w.opts.Logger
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Check(ctx, notifiers, onAlert); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warnf("⚠️  %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check polls once, delivers the new and previously undelivered alerts to each notifier
+// and saves the state. Alerts a notifier failed to take are kept for its next delivery,
+// without sending them again to the others. Each new alert is passed to onAlert, which
+// may be nil, before delivery.
+func (w *Watcher) Check(ctx context.Context, notifiers Notifiers, onAlert func(Alert)) error {
+	alerts, err := w.Poll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, alert := range alerts {
+		w.opts.Metrics.AlertRaised(alert.Kind)
+		if onAlert != nil {
+			onAlert(alert)
+		}
+	}
+
+	var errs []error
+	// Sorted so failures are reported in the same order on every check
+	for _, name := range slices.Sorted(maps.Keys(notifiers)) {
+		pending := append(w.saved.Pending[name], alerts...)
+		if len(pending) == 0 {
+			continue
+		}
+		if err := notifiers[name].Notify(ctx, pending); err != nil {
+			errs = append(errs, fmt.Errorf("failed to deliver %d alerts to %s, will retry: %w", len(pending), name, err))
+			if w.saved.Pending == nil {
+				w.saved.Pending = make(map[string][]Alert)
+			}
+			w.saved.Pending[name] = pending
+			continue
+		}
+		delete(w.saved.Pending, name)
+	}
+
+	if err := w.save(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// save writes the state to opts.StatePath
+func (w *Watcher) save() error {
+	if w.opts.StatePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(w.saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode watch state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(w.opts.StatePath), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(w.opts.StatePath), err)
+	}
+	// Written next to the state and renamed, so a crash never leaves a truncated file
+	tmp := w.opts.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write watch state %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, w.opts.StatePath); err != nil {
+		return fmt.Errorf("failed to write watch state %s: %w", w.opts.StatePath, err)
+	}
+	return nil
+}
+
+// WebhookNotifier posts alerts as JSON to a URL. The body carries a "text" summary so
+// chat webhooks (Slack, Mattermost) can show it as is.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// webhookPayload is the body posted by WebhookNotifier
+type webhookPayload struct {
+	Text   string  `json:"text"`
+	Alerts []Alert `json:"alerts"`
+}
+
+// Notify posts the alerts in a single request and fails unless the response is 2xx
+func (n *WebhookNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	lines := make([]string, len(alerts))
+	for i, alert := range alerts {
+		lines[i] = fmt.Sprintf("Ethrx %s: %s", alert.Contract, alert)
+	}
+	body, err := json.Marshal(webhookPayload{Text: strings.Join(lines, "\n"), Alerts: alerts})
+	if err != nil {
+		return fmt.Errorf("failed to encode alerts: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FileNotifier appends alerts to a file, one JSON object per line
+type FileNotifier struct {
+	Path string
+}
+
+// Notify appends the alerts to the file
+func (n *FileNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, alert := range alerts {
+		if err := encoder.Encode(alert); err != nil {
+			return fmt.Errorf("failed to encode alert: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(n.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open alerts file %s: %w", n.Path, err)
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write alerts file %s: %w", n.Path, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,157 @@
+package ethrx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/utils"
+)
+
+// failingNotifier fails every delivery
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(context.Context, []Alert) error {
+	return errors.New("webhook down")
+}
+
+// recordingNotifier keeps the alerts it receives
+type recordingNotifier struct {
+	alerts []Alert
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, alerts []Alert) error {
+	n.alerts = append(n.alerts, alerts...)
+	return nil
+}
+
+func TestWatcherAlertsOnOwnerChanges(t *testing.T) {
+	ctx := t.Context()
+	chain := newPrunedChain()
+	chain.latest = 100
+	statePath := filepath.Join(t.TempDir(), "watch.json")
+
+	w, err := NewWatcher(chain, chain.contract, WatchOptions{Network: "testnet", StatePath: statePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alerts, err := w.Poll(ctx); err != nil || len(alerts) != 0 {
+		t.Fatalf("first poll = %v, %v; want the state recorded without alerts", alerts, err)
+	}
+
+	// The owner transfers the contract, which emits an event, and changes the mint price,
+	// which does not
+	newOwner := new(felt.Felt).SetUint64(0x0b)
+	chain.emit(105, new(felt.Felt).SetUint64(0x501), []*felt.Felt{utils.GetSelectorFromNameFelt("OwnershipTransferred"), chain.owner, newOwner}, nil)
+	chain.owner = newOwner
+	chain.mintPrice = 5
+	chain.latest = 110
+
+	alerts, err := w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("alerts = %+v, want the ownership event and the mint price", alerts)
+	}
+	ownership, price := alerts[0], alerts[1]
+	if ownership.Source != SourceEvents || ownership.Kind != string(EventOwnershipTransferred) ||
+		ownership.New != newOwner.String() || ownership.BlockNumber != 105 || ownership.TxHash != "0x501" {
+		t.Errorf("ownership alert = %+v", ownership)
+	}
+	if price.Source != SourceState || price.Kind != SettingMintPrice || price.Old != "0" || price.New != "5" ||
+		price.BlockNumber != 110 || price.Network != "testnet" {
+		t.Errorf("mint price alert = %+v", price)
+	}
+	if alerts, err := w.Poll(ctx); err != nil || len(alerts) != 0 {
+		t.Errorf("poll without changes = %v, %v", alerts, err)
+	}
+
+	// A setter without an event is noticed on the next poll
+	mintToken := new(felt.Felt).SetUint64(0x58)
+	chain.mintToken = mintToken
+	chain.latest = 111
+	alerts, err = w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].Kind != SettingMintToken || alerts[0].New != mintToken.String() || alerts[0].BlockNumber != 111 {
+		t.Errorf("alerts = %+v, want the mint token", alerts)
+	}
+
+	// An alert the webhook fails to take is kept for it, while the alerts file gets it once
+	chain.isMinting = true
+	chain.latest = 112
+	alertsPath := filepath.Join(t.TempDir(), "alerts.jsonl")
+	file := &FileNotifier{Path: alertsPath}
+	var raised []Alert
+	err = w.Check(ctx, Notifiers{"webhook": failingNotifier{}, "alerts-file": file}, func(a Alert) { raised = append(raised, a) })
+	if err == nil || !strings.Contains(err.Error(), "failed to deliver 1 alerts to webhook") {
+		t.Fatalf("err = %v, want the failed webhook delivery", err)
+	}
+	if len(raised) != 1 || raised[0].Kind != SettingIsMinting || raised[0].New != "true" {
+		t.Errorf("raised = %+v, want is_minting turned on", raised)
+	}
+
+	// Undelivered alerts are kept in the state file and delivered after a restart
+	w, err = NewWatcher(chain, chain.contract, WatchOptions{StatePath: statePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.State().IsMinting || w.State().Owner != newOwner.String() {
+		t.Errorf("resumed state = %+v", w.State())
+	}
+	webhook := &recordingNotifier{}
+	if err := w.Check(ctx, Notifiers{"webhook": webhook, "alerts-file": file}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(webhook.alerts) != 1 || webhook.alerts[0].Kind != SettingIsMinting {
+		t.Errorf("webhook alerts = %+v, want the undelivered is_minting alert", webhook.alerts)
+	}
+	data, err := os.ReadFile(alertsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"kind":"is_minting"`) {
+		t.Errorf("alerts file = %q, want the alert written once", data)
+	}
+
+	// Nothing is left to deliver
+	webhook.alerts = nil
+	chain.latest = 113
+	if err := w.Check(ctx, Notifiers{"webhook": webhook, "alerts-file": file}, nil); err != nil || len(webhook.alerts) != 0 {
+		t.Errorf("check without changes = %v, delivered %+v", err, webhook.alerts)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var payload webhookPayload
+	status := http.StatusOK
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer hook.Close()
+
+	alert := Alert{Contract: "0xe7", Source: SourceState, Kind: SettingMintPrice, Old: "0", New: "5", BlockNumber: 9}
+	if err := (&WebhookNotifier{URL: hook.URL}).Notify(t.Context(), []Alert{alert}); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Text != "Ethrx 0xe7: mint_price changed from 0 to 5 at block 9" || len(payload.Alerts) != 1 {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	status = http.StatusBadGateway
+	if err := (&WebhookNotifier{URL: hook.URL}).Notify(t.Context(), []Alert{alert}); err == nil {
+		t.Error("HTTP 502 was not reported")
+	}
+}
@@ -0,0 +1,170 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
+)
+
+// KeystoreFile is the JSON layout of an encrypted key file (Web3 Secret Storage v3).
+// This is the format written by `starkli signer keystore new` and by Argent X exports.
+type KeystoreFile struct {
+	Crypto  CryptoJSON `json:"crypto"`
+	ID      string     `json:"id,omitempty"`
+	Version int        `json:"version"`
+}
+
+// CryptoJSON holds the cipher and key derivation parameters of a keystore file
+type CryptoJSON struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams CipherParamsJSON       `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+// CipherParamsJSON holds the cipher initialization vector
+type CipherParamsJSON struct {
+	IV string `json:"iv"`
+}
+
+// LoadFile reads and decrypts the keystore file at path with the given passphrase
+func LoadFile(path string, passphrase []byte) (*big.Int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore %s: %w", path, err)
+	}
+
+	key, err := Decrypt(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: %w", path, err)
+	}
+
+	return key, nil
+}
+
+// Decrypt decrypts a JSON keystore and returns the private key it contains
+func Decrypt(data []byte, passphrase []byte) (*big.Int, error) {
+	var ks KeystoreFile
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("invalid keystore JSON: %w", err)
+	}
+
+	if ks.Version != 3 {
+		return nil, fmt.Errorf("unsupported keystore version: %d", ks.Version)
+	}
+	if ks.Crypto.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("unsupported keystore cipher: %s", ks.Crypto.Cipher)
+	}
+
+	cipherText, err := decodeHex(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	iv, err := decodeHex(ks.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cipher IV: %w", err)
+	}
+	mac, err := decodeHex(ks.Crypto.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAC: %w", err)
+	}
+
+	derivedKey, err := deriveKey(ks.Crypto, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	// MAC = keccak256(derivedKey[16:32] ++ ciphertext)
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(derivedKey[16:32])
+	hasher.Write(cipherText)
+	if !hmac.Equal(hasher.Sum(nil), mac) {
+		return nil, fmt.Errorf("wrong passphrase or corrupted keystore (MAC mismatch)")
+	}
+
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+	plainText := make([]byte, len(cipherText))
+	cipher.NewCTR(block, iv).XORKeyStream(plainText, cipherText)
+
+	return new(big.Int).SetBytes(plainText), nil
+}
+
+// deriveKey runs the keystore KDF (scrypt or pbkdf2) over the passphrase
+func deriveKey(c CryptoJSON, passphrase []byte) ([]byte, error) {
+	salt, err := decodeHex(stringParam(c.KDFParams, "salt"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid KDF salt: %w", err)
+	}
+	dkLen := intParam(c.KDFParams, "dklen")
+	if dkLen < 32 {
+		return nil, fmt.Errorf("invalid KDF dklen: %d", dkLen)
+	}
+
+	switch c.KDF {
+	case "scrypt":
+		n := intParam(c.KDFParams, "n")
+		r := intParam(c.KDFParams, "r")
+		p := intParam(c.KDFParams, "p")
+		key, err := scrypt.Key(passphrase, salt, n, r, p, dkLen)
+		if err != nil {
+			return nil, fmt.Errorf("scrypt failed: %w", err)
+		}
+		return key, nil
+	case "pbkdf2":
+		if prf := stringParam(c.KDFParams, "prf"); prf != "hmac-sha256" {
+			return nil, fmt.Errorf("unsupported pbkdf2 PRF: %s", prf)
+		}
+		c := intParam(c.KDFParams, "c")
+		if c <= 0 {
+			return nil, fmt.Errorf("invalid pbkdf2 iteration count: %d", c)
+		}
+		return pbkdf2.Key(passphrase, salt, c, dkLen, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("unsupported keystore KDF: %s", c.KDF)
+	}
+}
+
+// ReadPasswordFile reads a passphrase from a file, dropping the trailing newline
+func ReadPasswordFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read password file %s: %w", path, err)
+	}
+	return bytes.TrimRight(data, "\r\n"), nil
+}
+
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
+
+func stringParam(params map[string]interface{}, key string) string {
+	if v, ok := params[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func intParam(params map[string]interface{}, key string) int {
+	// JSON numbers decode as float64
+	if v, ok := params[key].(float64); ok {
+		return int(v)
+	}
+	return 0
+}
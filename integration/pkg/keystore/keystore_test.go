@@ -0,0 +1,108 @@
+package keystore_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/keystore"
+)
+
+// The test vectors of the Web3 Secret Storage Definition: the same key encrypted with
+// "testpassword" under each KDF
+const (
+	vectorKey = "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d"
+
+	pbkdf2Vector = `{
+		"crypto": {
+			"cipher": "aes-128-ctr",
+			"cipherparams": {"iv": "6087dab2f9fdbbfaddc31a909735c1e6"},
+			"ciphertext": "5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46",
+			"kdf": "pbkdf2",
+			"kdfparams": {"c": 262144, "dklen": 32, "prf": "hmac-sha256", "salt": "ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},
+			"mac": "517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"
+		},
+		"id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+		"version": 3
+	}`
+
+	scryptVector = `{
+		"crypto": {
+			"cipher": "aes-128-ctr",
+			"cipherparams": {"iv": "83dbcc02d8ccb40e466191a123791e0e"},
+			"ciphertext": "d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c",
+			"kdf": "scrypt",
+			"kdfparams": {"dklen": 32, "n": 262144, "p": 8, "r": 1, "salt": "ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},
+			"mac": "2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"
+		},
+		"id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+		"version": 3
+	}`
+)
+
+func TestDecryptKnownAnswers(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		keystore string
+	}{
+		{"pbkdf2", pbkdf2Vector},
+		{"scrypt", scryptVector},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := keystore.Decrypt([]byte(tc.keystore), []byte("testpassword"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := key.Text(16); got != vectorKey {
+				t.Errorf("key = %s, want %s", got, vectorKey)
+			}
+		})
+	}
+}
+
+func TestDecryptRejects(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		keystore   string
+		passphrase string
+		wantErr    string
+	}{
+		{"wrong passphrase", pbkdf2Vector, "wrongpassword", "MAC mismatch"},
+		{"bad MAC", strings.Replace(pbkdf2Vector, `"mac": "517e`, `"mac": "617e`, 1), "testpassword", "MAC mismatch"},
+		{"tampered ciphertext", strings.Replace(pbkdf2Vector, `"ciphertext": "5318`, `"ciphertext": "6318`, 1), "testpassword", "MAC mismatch"},
+		{"version", strings.Replace(pbkdf2Vector, `"version": 3`, `"version": 1`, 1), "testpassword", "unsupported keystore version"},
+		{"cipher", strings.Replace(pbkdf2Vector, "aes-128-ctr", "aes-128-cbc", 1), "testpassword", "unsupported keystore cipher"},
+		{"prf", strings.Replace(pbkdf2Vector, "hmac-sha256", "hmac-sha512", 1), "testpassword", "unsupported pbkdf2 PRF"},
+		{"kdf", strings.Replace(pbkdf2Vector, `"kdf": "pbkdf2"`, `"kdf": "argon2"`, 1), "testpassword", "unsupported keystore KDF"},
+		{"json", "{", "testpassword", "invalid keystore JSON"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := keystore.Decrypt([]byte(tc.keystore), []byte(tc.passphrase))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deployer.json")
+	passwordPath := filepath.Join(dir, "password")
+	if err := os.WriteFile(path, []byte(pbkdf2Vector), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(passwordPath, []byte("testpassword\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unlock reads the passphrase file without its trailing newline
+	key, err := keystore.Unlock(path, passwordPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := key.Text(16); got != vectorKey {
+		t.Errorf("key = %s, want %s", got, vectorKey)
+	}
+}
@@ -0,0 +1,44 @@
+package keystore
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Unlock decrypts the keystore at path, reading the passphrase from passwordFile
+// when set and prompting on the terminal otherwise
+func Unlock(path, passwordFile string) (*big.Int, error) {
+	var passphrase []byte
+	var err error
+	if passwordFile != "" {
+		passphrase, err = ReadPasswordFile(passwordFile)
+	} else {
+		passphrase, err = PromptPassphrase(fmt.Sprintf("🔐 Enter passphrase for %s: ", path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadFile(path, passphrase)
+}
+
+// PromptPassphrase reads a passphrase from the terminal without echoing it.
+// The prompt goes to stderr so stdout stays clean for command output.
+func PromptPassphrase(prompt string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("cannot prompt for passphrase: stdin is not a terminal (use a password file)")
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	return passphrase, nil
+}
@@ -0,0 +1,162 @@
+// Package metrics exposes the health of long-running Ethrx services (indexers, metadata
+// servers, watchers) to Prometheus. All Metrics methods are no-ops on a nil *Metrics, so
+// instrumented code does not need to check whether metrics are enabled.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultBuckets are histogram buckets in seconds suited to RPC latencies
+var DefaultBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics are the metrics recorded by the deploy, provider and ethrx packages
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	rpcRequests *prometheus.CounterVec
+	rpcErrors   *prometheus.CounterVec
+	rpcDuration *prometheus.HistogramVec
+
+	lastBlock prometheus.Gauge
+	eventLag  prometheus.Gauge
+
+	txSubmitted *prometheus.CounterVec
+	txConfirmed prometheus.Counter
+	txReverted  prometheus.Counter
+	feesPaid    *prometheus.CounterVec
+
+	cacheRequests *prometheus.CounterVec
+
+	alerts *prometheus.CounterVec
+}
+
+// New creates the metrics on a new registry
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+
+		rpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "ethrx_rpc_requests_total", Help: "JSON-RPC calls by method."}, []string{"method"}),
+		rpcErrors:   prometheus.NewCounterVec(prometheus.CounterOpts{Name: "ethrx_rpc_errors_total", Help: "Failed JSON-RPC calls by method and kind (transport, http or rpc)."}, []string{"method", "kind"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "ethrx_rpc_request_duration_seconds", Help: "JSON-RPC call latency by method, including failover retries.", Buckets: DefaultBuckets}, []string{"method"}),
+
+		lastBlock: prometheus.NewGauge(prometheus.GaugeOpts{Name: "ethrx_last_processed_block", Help: "Last block whose events were processed."}),
+		eventLag:  prometheus.NewGauge(prometheus.GaugeOpts{Name: "ethrx_event_lag_blocks", Help: "Blocks between the chain head and the last processed block."}),
+
+		txSubmitted: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "ethrx_transactions_submitted_total", Help: "Transactions submitted by type."}, []string{"type"}),
+		txConfirmed: prometheus.NewCounter(prometheus.CounterOpts{Name: "ethrx_transactions_confirmed_total", Help: "Submitted transactions accepted without reverting."}),
+		txReverted:  prometheus.NewCounter(prometheus.CounterOpts{Name: "ethrx_transactions_reverted_total", Help: "Submitted transactions that reverted."}),
+		feesPaid:    prometheus.NewCounterVec(prometheus.CounterOpts{Name: "ethrx_fees_paid_total", Help: "Actual fees paid by awaited transactions, in the smallest unit (FRI or WEI)."}, []string{"unit"}),
+
+		cacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "ethrx_cache_requests_total", Help: "Cache lookups by cache and result (hit or miss)."}, []string{"cache", "result"}),
+
+		alerts: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "ethrx_alerts_total", Help: "Owner-level changes detected by the watcher, by event or setting."}, []string{"kind"}),
+	}
+	m.Registry.MustRegister(
+		m.rpcRequests, m.rpcErrors, m.rpcDuration,
+		m.lastBlock, m.eventLag,
+		m.txSubmitted, m.txConfirmed, m.txReverted, m.feesPaid,
+		m.cacheRequests,
+		m.alerts,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRPC records a JSON-RPC call. errKind is empty for successful calls.
+func (m *Metrics) ObserveRPC(method string, duration time.Duration, errKind string) {
+	if m == nil {
+		return
+	}
+	m.rpcRequests.WithLabelValues(method).Inc()
+	m.rpcDuration.WithLabelValues(method).Observe(duration.Seconds())
+	if errKind != "" {
+		m.rpcErrors.WithLabelValues(method, errKind).Inc()
+	}
+}
+
+// SetProcessedBlock records the last processed block and its lag behind the chain head
+func (m *Metrics) SetProcessedBlock(block, head uint64) {
+	if m == nil {
+		return
+	}
+	m.lastBlock.Set(float64(block))
+	m.eventLag.Set(float64(head - min(block, head)))
+}
+
+// TransactionSubmitted records a transaction accepted by the node, by type (invoke, declare)
+func (m *Metrics) TransactionSubmitted(txType string) {
+	if m == nil {
+		return
+	}
+	m.txSubmitted.WithLabelValues(txType).Inc()
+}
+
+// TransactionIncluded records an awaited transaction and the fee it paid; fee may be nil
+// when the receipt is unavailable
+func (m *Metrics) TransactionIncluded(reverted bool, fee *big.Int, unit string) {
+	if m == nil {
+		return
+	}
+	if reverted {
+		m.txReverted.Inc()
+	} else {
+		m.txConfirmed.Inc()
+	}
+	if fee != nil {
+		amount, _ := new(big.Float).SetInt(fee).Float64()
+		m.feesPaid.WithLabelValues(unit).Add(amount)
+	}
+}
+
+// CacheLookup records a hit or miss of the named cache
+func (m *Metrics) CacheLookup(cache string, hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// AlertRaised records an alert of the watcher
+func (m *Metrics) AlertRaised(kind string) {
+	if m == nil {
+		return
+	}
+	m.alerts.WithLabelValues(kind).Inc()
+}
+
+// Serve exposes the metrics on addr at /metrics until ctx is cancelled. It returns the
+// address listened on, which tells the port when addr is ":0".
+func (m *Metrics) Serve(ctx context.Context, addr string) (net.Addr, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	go server.Serve(listener)
+	return listener.Addr(), nil
+}
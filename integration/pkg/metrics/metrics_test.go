@@ -0,0 +1,170 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/metrics"
+)
+
+// scrape returns the metrics as served at /metrics
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	return rec.Body.String()
+}
+
+// parseText parses the served metrics with Prometheus' text parser
+func parseText(t *testing.T, m *metrics.Metrics) map[string]*dto.MetricFamily {
+	t.Helper()
+	text := scrape(t, m)
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("Prometheus cannot parse the output: %v\n%s", err, text)
+	}
+	return families
+}
+
+func TestHandlerServesEveryMetric(t *testing.T) {
+	m := metrics.New()
+	m.ObserveRPC("starknet_call", 20*time.Millisecond, "")
+	m.ObserveRPC("starknet_call", 7*time.Second, metrics.ErrorRPC)
+	m.ObserveRPC("starknet_call", time.Minute, "")
+	m.SetProcessedBlock(90, 100)
+	m.TransactionSubmitted("INVOKE")
+	m.TransactionIncluded(false, big.NewInt(1500), "FRI")
+	m.TransactionIncluded(true, nil, "")
+	m.CacheLookup("transactions", true)
+	m.AlertRaised("OwnershipTransferred")
+
+	families := parseText(t, m)
+	if len(families) != 11 {
+		t.Errorf("families = %d, want the 11 metrics", len(families))
+	}
+
+	if g := families["ethrx_event_lag_blocks"]; g.GetType() != dto.MetricType_GAUGE || g.Metric[0].GetGauge().GetValue() != 10 {
+		t.Errorf("ethrx_event_lag_blocks = %v", g)
+	}
+	if c := families["ethrx_fees_paid_total"]; c.GetType() != dto.MetricType_COUNTER || c.Metric[0].GetCounter().GetValue() != 1500 {
+		t.Errorf("ethrx_fees_paid_total = %v", c)
+	}
+
+	h := families["ethrx_rpc_request_duration_seconds"]
+	if h.GetType() != dto.MetricType_HISTOGRAM || len(h.Metric) != 1 {
+		t.Fatalf("ethrx_rpc_request_duration_seconds = %v", h)
+	}
+	histogram := h.Metric[0].GetHistogram()
+	// The parser includes the +Inf bucket
+	if histogram.GetSampleCount() != 3 || histogram.GetSampleSum() != 67.02 || len(histogram.Bucket) != len(metrics.DefaultBuckets)+1 {
+		t.Errorf("histogram = %v", histogram)
+	}
+	for _, b := range histogram.Bucket {
+		var want uint64
+		for _, v := range []float64{0.02, 7, 60} {
+			if v <= b.GetUpperBound() {
+				want++
+			}
+		}
+		if b.GetCumulativeCount() != want {
+			t.Errorf("bucket le=%g = %d, want %d", b.GetUpperBound(), b.GetCumulativeCount(), want)
+		}
+	}
+}
+
+func TestNilMetricsRecordNothing(t *testing.T) {
+	var m *metrics.Metrics
+	m.ObserveRPC("starknet_call", time.Second, "")
+	m.SetProcessedBlock(10, 12)
+	m.TransactionSubmitted("INVOKE")
+	m.TransactionIncluded(false, big.NewInt(1), "FRI")
+	m.CacheLookup("transactions", true)
+	m.AlertRaised("OwnershipTransferred")
+	if m.Transport(http.DefaultTransport) != http.DefaultTransport {
+		t.Error("nil metrics wrapped the transport")
+	}
+}
+
+func TestTransportRecordsCalls(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.HasPrefix(string(body), "[") {
+			io.WriteString(w, `[{"jsonrpc":"2.0","id":1,"result":"0x1"},{"jsonrpc":"2.0","id":2,"error":{"code":20,"message":"Contract not found"}}]`)
+			return
+		}
+		io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":100}`)
+	}))
+	defer node.Close()
+
+	m := metrics.New()
+	client := &http.Client{Transport: m.Transport(http.DefaultTransport)}
+	post := func(body string) {
+		t.Helper()
+		resp, err := client.Post(node.URL, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The response body is still readable by the RPC client
+		if data, _ := io.ReadAll(resp.Body); len(data) == 0 {
+			t.Error("empty response body")
+		}
+		resp.Body.Close()
+	}
+	post(`{"jsonrpc":"2.0","id":1,"method":"starknet_blockNumber","params":[]}`)
+	post(`[{"jsonrpc":"2.0","id":1,"method":"starknet_call","params":[]},{"jsonrpc":"2.0","id":2,"method":"starknet_getClassHashAt","params":[]}]`)
+	m.SetProcessedBlock(90, 100)
+	m.CacheLookup("transactions", true)
+	m.CacheLookup("transactions", false)
+	m.TransactionSubmitted("INVOKE")
+	m.TransactionIncluded(true, big.NewInt(1500), "FRI")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	addr, err := m.Serve(ctx, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get("http://" + addr.String() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	text := string(data)
+
+	for _, line := range []string{
+		`ethrx_rpc_requests_total{method="starknet_blockNumber"} 1`,
+		`ethrx_rpc_requests_total{method="starknet_call"} 1`,
+		`ethrx_rpc_errors_total{kind="rpc",method="starknet_getClassHashAt"} 1`,
+		`ethrx_rpc_request_duration_seconds_count{method="starknet_call"} 1`,
+		`ethrx_last_processed_block 90`,
+		`ethrx_event_lag_blocks 10`,
+		`ethrx_cache_requests_total{cache="transactions",result="hit"} 1`,
+		`ethrx_cache_requests_total{cache="transactions",result="miss"} 1`,
+		`ethrx_transactions_submitted_total{type="INVOKE"} 1`,
+		`ethrx_transactions_reverted_total 1`,
+		`ethrx_fees_paid_total{unit="FRI"} 1500`,
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("metrics lack %q", line)
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "ethrx_rpc_errors_total{") && strings.Contains(line, `method="starknet_call"`) {
+			t.Error("successful batch call counted as an error")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Kinds of failed RPC calls
+const (
+	ErrorTransport = "transport" // no response, e.g. all endpoints failed
+	ErrorHTTP      = "http"      // non-2xx response
+	ErrorRPC       = "rpc"       // JSON-RPC error object in the response
+)
+
+// Transport records every JSON-RPC call sent through next, including each call of a batch
+// request. Without metrics next is returned unchanged.
+func (m *Metrics) Transport(next http.RoundTripper) http.RoundTripper {
+	if m == nil {
+		return next
+	}
+	return &transport{next: next, metrics: m}
+}
+
+type transport struct {
+	next    http.RoundTripper
+	metrics *Metrics
+}
+
+// rpcMessage holds the fields of JSON-RPC requests and responses needed for metrics
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Error  json.RawMessage `json:"error"`
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	calls := decodeMessages(body)
+	if len(calls) == 0 {
+		calls = []rpcMessage{{Method: "unknown"}}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start)
+
+	if err != nil {
+		t.observe(calls, duration, func(rpcMessage) string { return ErrorTransport })
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		t.observe(calls, duration, func(rpcMessage) string { return ErrorHTTP })
+		return resp, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.observe(calls, duration, func(rpcMessage) string { return ErrorTransport })
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	failed := make(map[string]bool)
+	for _, response := range decodeMessages(data) {
+		if len(response.Error) > 0 && string(response.Error) != "null" {
+			failed[string(response.ID)] = true
+		}
+	}
+	t.observe(calls, duration, func(call rpcMessage) string {
+		if failed[string(call.ID)] {
+			return ErrorRPC
+		}
+		return ""
+	})
+	return resp, nil
+}
+
+// observe records each call with the error kind returned by kind
+func (t *transport) observe(calls []rpcMessage, duration time.Duration, kind func(rpcMessage) string) {
+	for _, call := range calls {
+		t.metrics.ObserveRPC(call.Method, duration, kind(call))
+	}
+}
+
+// decodeMessages decodes a single JSON-RPC message or a batch; invalid JSON gives none
+func decodeMessages(data []byte) []rpcMessage {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var batch []rpcMessage
+		if json.Unmarshal(data, &batch) != nil {
+			return nil
+		}
+		return batch
+	}
+	var single rpcMessage
+	if json.Unmarshal(data, &single) != nil {
+		return nil
+	}
+	return []rpcMessage{single}
+}
@@ -0,0 +1,411 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/metrics"
+)
+
+// SupportedSpecVersion is the Starknet JSON-RPC spec (major.minor) implemented by starknet.go
+const SupportedSpecVersion = "0.9"
+
+// ErrWriteUnconfirmed is returned when a transaction submission failed after reaching an
+// endpoint (timeout, HTTP 5xx, dropped connection). The node may have accepted it, so it
+// is not resent; callers check the transaction status by hash instead.
+var ErrWriteUnconfirmed = errors.New("transaction submission failed and may have been accepted")
+
+// writeMethods submit transactions. Resending one that already reached a node would
+// broadcast it twice or fail as a duplicate, so they only fail over when the endpoint
+// provably did not process them.
+var writeMethods = map[string]bool{
+	"starknet_addInvokeTransaction":        true,
+	"starknet_addDeclareTransaction":       true,
+	"starknet_addDeployAccountTransaction": true,
+}
+
+// rejectedError is a failed attempt the endpoint refused without processing it (rate
+// limits), which is safe to send elsewhere even for writes
+type rejectedError struct {
+	err error
+}
+
+func (e *rejectedError) Error() string { return e.err.Error() }
+func (e *rejectedError) Unwrap() error { return e.err }
+
+// Options configures an endpoint pool
+type Options struct {
+	// RequestsPerSecond limits requests sent to each endpoint; 0 disables rate limiting
+	RequestsPerSecond float64
+	// MaxRetries is the number of extra attempts for transient failures
+	MaxRetries int
+	// RetryBackoff is the pause before the first retry, doubled on each further retry
+	RetryBackoff time.Duration
+	// Cooldown is how long a failing endpoint is skipped before it is tried again
+	Cooldown time.Duration
+	// HealthCheckInterval is how often endpoints in cooldown are probed by Run
+	HealthCheckInterval time.Duration
+	// RequestTimeout bounds a single HTTP attempt
+	RequestTimeout time.Duration
+	// Metrics records the calls sent through HTTPClient; nil disables them
+	Metrics *metrics.Metrics
+}
+
+// DefaultOptions returns options suited to public RPC providers
+func DefaultOptions() Options {
+	return Options{
+		RequestsPerSecond:   10,
+		MaxRetries:          3,
+		RetryBackoff:        500 * time.Millisecond,
+		Cooldown:            30 * time.Second,
+		HealthCheckInterval: 15 * time.Second,
+		RequestTimeout:      30 * time.Second,
+	}
+}
+
+// endpoint is one RPC URL with its health and rate limiting state
+type endpoint struct {
+	url         *url.URL
+	specVersion string
+	limiter     *limiter
+
+	// guarded by Pool.mu
+	downUntil time.Time
+	lastErr   error
+}
+
+// Pool is an http.RoundTripper spreading JSON-RPC requests over several Starknet endpoints.
+// Requests go to the first healthy endpoint in priority order; transient failures (network
+// errors, HTTP 429/5xx, rate limit errors) put the endpoint in cooldown and the request is
+// retried on the next one. Transaction submissions are only retried when the endpoint
+// refused the connection or rate limited them, and fail with ErrWriteUnconfirmed otherwise.
+// Endpoints serving an unsupported spec version are never used.
+type Pool struct {
+	endpoints []*endpoint
+	opts      Options
+	transport http.RoundTripper
+	logger    *logrus.Logger
+
+	mu sync.Mutex
+}
+
+// NewPool detects the spec version of each endpoint and returns a pool of the compatible
+// ones. Unreachable endpoints are kept in cooldown and checked again by Run.
+func NewPool(ctx context.Context, urls []string, opts Options, logger *logrus.Logger) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no RPC endpoints configured")
+	}
+
+	p := &Pool{
+		opts:      opts,
+		transport: http.DefaultTransport,
+		logger:    logger,
+	}
+
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid RPC URL %q", raw)
+		}
+		ep := &endpoint{url: u, limiter: newLimiter(opts.RequestsPerSecond)}
+
+		version, err := p.specVersion(ctx, ep)
+		switch {
+		case err != nil:
+			logger.Warnf("⚠️  RPC endpoint %s unreachable, will retry: %s", redact(u), err)
+			ep.downUntil = time.Now().Add(opts.Cooldown)
+			ep.lastErr = err
+		case !compatible(version):
+			logger.Warnf("⚠️  Skipping RPC endpoint %s: serves spec %s, need %s.x", redact(u), version, SupportedSpecVersion)
+			continue
+		default:
+			logger.Debugf("📡 RPC endpoint %s (spec %s)", redact(u), version)
+			ep.specVersion = version
+		}
+		p.endpoints = append(p.endpoints, ep)
+	}
+
+	if len(p.endpoints) == 0 {
+		return nil, fmt.Errorf("no RPC endpoint serves spec version %s.x", SupportedSpecVersion)
+	}
+
+	return p, nil
+}
+
+// URL returns the primary endpoint URL. Requests are rewritten to the selected endpoint,
+// so any pool URL can be given to the RPC client.
+func (p *Pool) URL() string {
+	return p.endpoints[0].url.String()
+}
+
+// HTTPClient returns an HTTP client sending requests through the pool, recording
+// them in Options.Metrics
+func (p *Pool) HTTPClient() *http.Client {
+	return &http.Client{Transport: p.opts.Metrics.Transport(p)}
+}
+
+// Run probes endpoints in cooldown every HealthCheckInterval until ctx is cancelled
+func (p *Pool) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.opts.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkHealth(ctx)
+		}
+	}
+}
+
+// checkHealth probes endpoints in cooldown and brings back the ones that answer
+func (p *Pool) checkHealth(ctx context.Context) {
+	for _, ep := range p.endpoints {
+		p.mu.Lock()
+		down := time.Now().Before(ep.downUntil) || ep.specVersion == ""
+		p.mu.Unlock()
+		if !down {
+			continue
+		}
+
+		version, err := p.specVersion(ctx, ep)
+		if err == nil && !compatible(version) {
+			err = fmt.Errorf("unsupported spec version %s", version)
+		}
+
+		p.mu.Lock()
+		if err != nil {
+			ep.downUntil = time.Now().Add(p.opts.Cooldown)
+			ep.lastErr = err
+		} else {
+			ep.specVersion = version
+			ep.downUntil = time.Time{}
+			ep.lastErr = nil
+			p.logger.Infof("✅ RPC endpoint %s is healthy again", redact(ep.url))
+		}
+		p.mu.Unlock()
+	}
+}
+
+// RoundTrip sends the request to the first healthy endpoint, failing over on transient errors
+func (p *Pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	write := isWrite(body)
+	backoff := p.opts.RetryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= p.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleep(req.Context(), backoff); err != nil {
+				return nil, err
+			}
+			backoff *= 2
+		}
+
+		ep := p.pick()
+		if err := ep.limiter.wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		resp, err := p.send(req, ep, body)
+		if err == nil {
+			return resp, nil
+		}
+		if req.Context().Err() != nil {
+			return nil, req.Context().Err()
+		}
+
+		lastErr = err
+		p.markDown(ep, err)
+		if write && !unprocessed(err) {
+			return nil, fmt.Errorf("%w: %w", ErrWriteUnconfirmed, err)
+		}
+	}
+
+	return nil, fmt.Errorf("all RPC attempts failed: %w", lastErr)
+}
+
+// send performs one attempt and returns an error for transient failures
+func (p *Pool) send(req *http.Request, ep *endpoint, body []byte) (*http.Response, error) {
+	ctx := req.Context()
+	if p.opts.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.opts.RequestTimeout)
+		defer cancel()
+	}
+
+	attempt := req.Clone(ctx)
+	attempt.URL = ep.url
+	attempt.Host = ep.url.Host
+	attempt.Body = io.NopCloser(bytes.NewReader(body))
+	attempt.ContentLength = int64(len(body))
+
+	resp, err := p.transport.RoundTrip(attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the body now so the per-attempt timeout does not cut it short for the caller
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &rejectedError{fmt.Errorf("HTTP %d from %s", resp.StatusCode, redact(ep.url))}
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, redact(ep.url))
+	}
+	if code, ok := transientRPCError(data); ok {
+		return nil, &rejectedError{fmt.Errorf("JSON-RPC error %d from %s", code, redact(ep.url))}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return resp, nil
+}
+
+// pick returns the first endpoint not in cooldown, or the one recovering soonest
+func (p *Pool) pick() *endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	best := p.endpoints[0]
+	for _, ep := range p.endpoints {
+		if ep.specVersion != "" && !now.Before(ep.downUntil) {
+			return ep
+		}
+		if ep.downUntil.Before(best.downUntil) {
+			best = ep
+		}
+	}
+	return best
+}
+
+func (p *Pool) markDown(ep *endpoint, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ep.downUntil = time.Now().Add(p.opts.Cooldown)
+	ep.lastErr = err
+	p.logger.Warnf("⚠️  RPC endpoint %s failed, failing over: %s", redact(ep.url), err)
+}
+
+// specVersion queries starknet_specVersion on a single endpoint
+func (p *Pool) specVersion(ctx context.Context, ep *endpoint) (string, error) {
+	payload := []byte(`{"jsonrpc":"2.0","id":1,"method":"starknet_specVersion","params":[]}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.url.String(), bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.send(req, ep, payload)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid starknet_specVersion response: %w", err)
+	}
+	if result.Error != nil {
+		return "", errors.New(result.Error.Message)
+	}
+	return result.Result, nil
+}
+
+// compatible reports whether version has the supported major.minor
+func compatible(version string) bool {
+	return version == SupportedSpecVersion || strings.HasPrefix(version, SupportedSpecVersion+".")
+}
+
+// transientRPCError detects JSON-RPC errors that mean "try again elsewhere" (rate limits)
+func transientRPCError(data []byte) (int, bool) {
+	var response struct {
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &response) != nil || response.Error == nil {
+		return 0, false
+	}
+
+	switch response.Error.Code {
+	case -32005, // limit exceeded
+		429: // rate limited (some providers mirror the HTTP status)
+		return response.Error.Code, true
+	}
+	return 0, false
+}
+
+// isWrite reports whether a single or batch JSON-RPC request submits a transaction
+func isWrite(body []byte) bool {
+	type request struct {
+		Method string `json:"method"`
+	}
+	var batch []request
+	if json.Unmarshal(body, &batch) != nil {
+		var single request
+		if json.Unmarshal(body, &single) != nil {
+			return false
+		}
+		batch = []request{single}
+	}
+	return slices.ContainsFunc(batch, func(r request) bool { return writeMethods[r.Method] })
+}
+
+// unprocessed reports whether a failed attempt certainly never reached the node: the
+// connection was refused or the endpoint rate limited the request
+func unprocessed(err error) bool {
+	var rejected *rejectedError
+	var opErr *net.OpError
+	return errors.As(err, &rejected) || errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// redact hides API keys embedded in provider URLs from logs
+func redact(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
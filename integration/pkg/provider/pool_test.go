@@ -0,0 +1,272 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeNode is a JSON-RPC endpoint answering starknet_specVersion with version and
+// every other method with an empty result, or with status once it is set
+type fakeNode struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	version string
+	status  int
+	methods []string
+}
+
+func newFakeNode(t *testing.T, version string) *fakeNode {
+	t.Helper()
+	n := &fakeNode{version: version}
+	n.Server = httptest.NewServer(http.HandlerFunc(n.serve))
+	t.Cleanup(n.Close)
+	return n
+}
+
+func (n *fakeNode) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     int    `json:"id"`
+		Method string `json:"method"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	n.mu.Lock()
+	n.methods = append(n.methods, req.Method)
+	status, version := n.status, n.version
+	n.mu.Unlock()
+
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	result := `"0x1"`
+	if req.Method == "starknet_specVersion" {
+		result = fmt.Sprintf("%q", version)
+	}
+	fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, result)
+}
+
+func (n *fakeNode) setStatus(status int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.status = status
+}
+
+// calls counts the requests received for method
+func (n *fakeNode) calls(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	count := 0
+	for _, m := range n.methods {
+		if m == method {
+			count++
+		}
+	}
+	return count
+}
+
+func testOptions() Options {
+	return Options{
+		MaxRetries:     2,
+		RetryBackoff:   time.Millisecond,
+		Cooldown:       time.Minute,
+		RequestTimeout: 5 * time.Second,
+	}
+}
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func newTestPool(t *testing.T, nodes ...*fakeNode) *Pool {
+	t.Helper()
+	var urls []string
+	for _, n := range nodes {
+		urls = append(urls, n.URL)
+	}
+	pool, err := NewPool(t.Context(), urls, testOptions(), testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pool
+}
+
+// call sends a JSON-RPC request through the pool
+func call(t *testing.T, pool *Pool, method string) error {
+	t.Helper()
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","id":7,"method":%q,"params":[]}`, method)
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, pool.URL(), strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := pool.HTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func TestPoolFailsOverReads(t *testing.T) {
+	primary, fallback := newFakeNode(t, "0.9.0"), newFakeNode(t, "0.9.0")
+	pool := newTestPool(t, primary, fallback)
+
+	primary.setStatus(http.StatusServiceUnavailable)
+	for range 2 {
+		if err := call(t, pool, "starknet_blockNumber"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The primary is in cooldown after its first failure and is not asked again
+	if got := primary.calls("starknet_blockNumber"); got != 1 {
+		t.Errorf("primary calls = %d, want 1", got)
+	}
+	if got := fallback.calls("starknet_blockNumber"); got != 2 {
+		t.Errorf("fallback calls = %d, want 2", got)
+	}
+}
+
+func TestPoolDoesNotResendAmbiguousWrites(t *testing.T) {
+	for _, method := range []string{"starknet_addInvokeTransaction", "starknet_addDeclareTransaction"} {
+		t.Run(method, func(t *testing.T) {
+			primary, fallback := newFakeNode(t, "0.9.0"), newFakeNode(t, "0.9.0")
+			pool := newTestPool(t, primary, fallback)
+
+			primary.setStatus(http.StatusBadGateway)
+			err := call(t, pool, method)
+			if !errors.Is(err, ErrWriteUnconfirmed) {
+				t.Fatalf("err = %v, want ErrWriteUnconfirmed", err)
+			}
+			if got := fallback.calls(method); got != 0 {
+				t.Errorf("fallback received the submission %d times", got)
+			}
+		})
+	}
+}
+
+func TestPoolFailsOverUnprocessedWrites(t *testing.T) {
+	const method = "starknet_addInvokeTransaction"
+
+	t.Run("rate limited", func(t *testing.T) {
+		primary, fallback := newFakeNode(t, "0.9.0"), newFakeNode(t, "0.9.0")
+		pool := newTestPool(t, primary, fallback)
+
+		primary.setStatus(http.StatusTooManyRequests)
+		if err := call(t, pool, method); err != nil {
+			t.Fatal(err)
+		}
+		if got := fallback.calls(method); got != 1 {
+			t.Errorf("fallback calls = %d, want 1", got)
+		}
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		primary, fallback := newFakeNode(t, "0.9.0"), newFakeNode(t, "0.9.0")
+		pool := newTestPool(t, primary, fallback)
+
+		primary.Close()
+		if err := call(t, pool, method); err != nil {
+			t.Fatal(err)
+		}
+		if got := fallback.calls(method); got != 1 {
+			t.Errorf("fallback calls = %d, want 1", got)
+		}
+	})
+}
+
+func TestPoolGivesUpAfterRetries(t *testing.T) {
+	node := newFakeNode(t, "0.9.0")
+	pool := newTestPool(t, node)
+
+	node.setStatus(http.StatusInternalServerError)
+	if err := call(t, pool, "starknet_blockNumber"); err == nil || !strings.Contains(err.Error(), "all RPC attempts failed") {
+		t.Fatalf("err = %v", err)
+	}
+	if got := node.calls("starknet_blockNumber"); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+}
+
+func TestPoolSkipsIncompatibleEndpoints(t *testing.T) {
+	old, current := newFakeNode(t, "0.7.1"), newFakeNode(t, "0.9.0")
+	pool := newTestPool(t, old, current)
+
+	if len(pool.endpoints) != 1 || pool.URL() != current.URL {
+		t.Fatalf("endpoints = %d, primary = %s", len(pool.endpoints), pool.URL())
+	}
+	if err := call(t, pool, "starknet_blockNumber"); err != nil {
+		t.Fatal(err)
+	}
+	if got := old.calls("starknet_blockNumber"); got != 0 {
+		t.Errorf("incompatible endpoint received %d calls", got)
+	}
+
+	if _, err := NewPool(t.Context(), []string{old.URL}, testOptions(), testLogger()); err == nil {
+		t.Error("pool built without a compatible endpoint")
+	}
+}
+
+func TestPoolHealthCheckRestoresEndpoints(t *testing.T) {
+	primary, fallback := newFakeNode(t, "0.9.0"), newFakeNode(t, "0.9.0")
+	primary.setStatus(http.StatusServiceUnavailable)
+	pool := newTestPool(t, primary, fallback)
+
+	// The primary was unreachable at startup, so requests go to the fallback
+	if pool.pick().url.String() != fallback.URL {
+		t.Fatal("unreachable primary picked")
+	}
+
+	primary.setStatus(0)
+	pool.checkHealth(t.Context())
+	if pool.pick().url.String() != primary.URL {
+		t.Error("recovered primary not picked")
+	}
+	if err := call(t, pool, "starknet_blockNumber"); err != nil {
+		t.Fatal(err)
+	}
+	if got := primary.calls("starknet_blockNumber"); got != 1 {
+		t.Errorf("primary calls = %d, want 1", got)
+	}
+}
+
+func TestLimiterSpacesRequests(t *testing.T) {
+	l := newLimiter(100)
+	start := time.Now()
+	for range 5 {
+		if err := l.wait(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first request goes out immediately, the next four 10ms apart
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("5 requests at 100/s took %s", elapsed)
+	}
+
+	// A cancelled wait returns the context error
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	slow := newLimiter(0.1)
+	slow.wait(ctx)
+	if err := slow.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+
+	if err := newLimiter(0).wait(ctx); err != nil {
+		t.Errorf("disabled limiter: %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+package signer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/utils"
+)
+
+// ProtocolVersion is the version of the external signer wire protocol
+const ProtocolVersion = 1
+
+// externalRequest is one line of newline-delimited JSON sent to the signing service
+type externalRequest struct {
+	Version   int    `json:"version"`
+	ID        string `json:"id"`
+	PublicKey string `json:"public_key"`
+	*SignRequest
+}
+
+// externalResponse is the line of JSON returned by the signing service
+type externalResponse struct {
+	ID        string   `json:"id"`
+	Approved  bool     `json:"approved"`
+	Signature []string `json:"signature"`
+	Reason    string   `json:"reason,omitempty"`
+}
+
+// ExternalSigner delegates approval and signing to another process, either a command
+// spoken to over stdin/stdout or a service listening on a local Unix socket.
+// Every request is a single JSON line and expects a single JSON line in reply:
+//
+//	-> {"version":1,"id":"1","public_key":"0x..","type":"INVOKE","transaction_hash":"0x..","calls":[...]}
+//	<- {"id":"1","approved":true,"signature":["0x..","0x.."]}
+//	<- {"id":"1","approved":false,"reason":"rejected by security"}
+type ExternalSigner struct {
+	publicKey  string
+	command    []string
+	socketPath string
+	timeout    time.Duration
+
+	mu     sync.Mutex
+	nextID int
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// NewCommandSigner creates a signer that talks to a long-running command over stdin/stdout.
+// The command's stderr is passed through so it can prompt or log.
+func NewCommandSigner(publicKey string, command []string, timeout time.Duration) (*ExternalSigner, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("signer command is empty")
+	}
+	if publicKey == "" {
+		return nil, fmt.Errorf("public key is required for external signers")
+	}
+	return &ExternalSigner{
+		publicKey: publicKey,
+		command:   command,
+		timeout:   timeout,
+	}, nil
+}
+
+// NewSocketSigner creates a signer that sends each request to a Unix socket
+func NewSocketSigner(publicKey, socketPath string, timeout time.Duration) (*ExternalSigner, error) {
+	if socketPath == "" {
+		return nil, fmt.Errorf("signer socket path is empty")
+	}
+	if publicKey == "" {
+		return nil, fmt.Errorf("public key is required for external signers")
+	}
+	return &ExternalSigner{
+		publicKey:  publicKey,
+		socketPath: socketPath,
+		timeout:    timeout,
+	}, nil
+}
+
+// PublicKey returns the public key the external signer signs for
+func (s *ExternalSigner) PublicKey() string {
+	return s.publicKey
+}
+
+// SignTransaction sends the request for approval and returns the verified signature
+func (s *ExternalSigner) SignTransaction(ctx context.Context, req *SignRequest) ([]*felt.Felt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	request := externalRequest{
+		Version:     ProtocolVersion,
+		ID:          strconv.Itoa(s.nextID),
+		PublicKey:   s.publicKey,
+		SignRequest: req,
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sign request: %w", err)
+	}
+	payload = append(payload, '\n')
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var line []byte
+	if s.socketPath != "" {
+		line, err = s.roundTripSocket(ctx, payload)
+	} else {
+		line, err = s.roundTripCommand(ctx, payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var resp externalResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, fmt.Errorf("invalid signer response: %w", err)
+	}
+	if resp.ID != request.ID {
+		return nil, fmt.Errorf("signer response ID mismatch: expected %s, got %s", request.ID, resp.ID)
+	}
+	if !resp.Approved {
+		return nil, fmt.Errorf("signing request rejected: %s", resp.Reason)
+	}
+
+	signature, err := utils.HexArrToFelt(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature from signer: %w", err)
+	}
+
+	msgHash, err := utils.HexToFelt(req.TransactionHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction hash: %w", err)
+	}
+	if err := Verify(s.publicKey, msgHash, signature); err != nil {
+		return nil, err
+	}
+
+	return signature, nil
+}
+
+// roundTripSocket dials the Unix socket, writes one request and reads one response line
+func (s *ExternalSigner) roundTripSocket(ctx context.Context, payload []byte) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", s.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to signer socket %s: %w", s.socketPath, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write(payload); err != nil {
+		return nil, fmt.Errorf("failed to send sign request: %w", err)
+	}
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signer response: %w", err)
+	}
+	return line, nil
+}
+
+// roundTripCommand writes one request to the signer process and waits for its reply.
+// The process is started on first use and kept alive for subsequent requests.
+func (s *ExternalSigner) roundTripCommand(ctx context.Context, payload []byte) ([]byte, error) {
+	if s.cmd == nil {
+		if err := s.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.stdin.Write(payload); err != nil {
+		s.stop()
+		return nil, fmt.Errorf("failed to send sign request: %w", err)
+	}
+
+	type result struct {
+		line []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := s.stdout.ReadBytes('\n')
+		done <- result{line, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		// The reader goroutine is unblocked when the process is killed
+		s.stop()
+		return nil, fmt.Errorf("waiting for signer approval: %w", ctx.Err())
+	case res := <-done:
+		if res.err != nil {
+			s.stop()
+			return nil, fmt.Errorf("failed to read signer response: %w", res.err)
+		}
+		return res.line, nil
+	}
+}
+
+func (s *ExternalSigner) start() error {
+	cmd := exec.Command(s.command[0], s.command[1:]...)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open signer stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open signer stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start signer command %s: %w", s.command[0], err)
+	}
+
+	s.cmd = cmd
+	s.stdin = stdin
+	s.stdout = bufio.NewReader(stdout)
+	return nil
+}
+
+func (s *ExternalSigner) stop() error {
+	if s.cmd == nil {
+		return nil
+	}
+	s.stdin.Close()
+	s.cmd.Process.Kill()
+	err := s.cmd.Wait()
+	s.cmd = nil
+	return err
+}
+
+// Close terminates the signer process, if any
+func (s *ExternalSigner) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cmd == nil {
+		return nil
+	}
+	// Closing stdin lets well-behaved signers exit on their own
+	s.stdin.Close()
+	done := make(chan error, 1)
+	go func() { done <- s.cmd.Wait() }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		s.cmd.Process.Kill()
+		<-done
+	}
+	s.cmd = nil
+	return nil
+}
@@ -0,0 +1,209 @@
+package signer_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/starknet.go/curve"
+	"github.com/NethermindEth/starknet.go/utils"
+
+	"github.com/NovemberFork/etheracts/integration/pkg/signer"
+)
+
+// stubEnv makes the test binary act as an external signer instead of running the tests
+const stubEnv = "ETHRX_SIGNER_STUB"
+
+const (
+	stubPrivateKey = "0x1"
+	// hangHash is never answered, so the request times out
+	hangHash = "0xdead"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv(stubEnv) == "1" {
+		runStub(os.Stdin, os.Stdout, os.Args)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runStub answers sign requests line by line. Requests calling "rug" are rejected, requests
+// calling "unsigned" are approved without a signature and requests for hangHash are left
+// unanswered.
+func runStub(in *os.File, out *os.File, args []string) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if resp := stubResponse(scanner.Bytes(), args); resp != nil {
+			out.Write(resp)
+		}
+	}
+}
+
+func stubResponse(line []byte, args []string) []byte {
+	var req struct {
+		Version         int    `json:"version"`
+		ID              string `json:"id"`
+		TransactionHash string `json:"transaction_hash"`
+		Calls           []signer.Call
+	}
+	json.Unmarshal(line, &req)
+
+	resp := map[string]any{"id": req.ID, "approved": true}
+	switch {
+	case req.TransactionHash == hangHash:
+		return nil
+	case req.Version != signer.ProtocolVersion:
+		resp = map[string]any{"id": req.ID, "approved": false, "reason": fmt.Sprintf("unknown version %d", req.Version)}
+	case len(req.Calls) > 0 && req.Calls[0].Entrypoint == "rug":
+		resp = map[string]any{"id": req.ID, "approved": false, "reason": "rejected by security"}
+	case len(req.Calls) > 0 && req.Calls[0].Entrypoint == "unsigned":
+		resp["signature"] = []string{}
+	case !slices.Contains(args, "prod env") && len(args) > 1:
+		resp = map[string]any{"id": req.ID, "approved": false, "reason": fmt.Sprintf("argv split: %q", args)}
+	default:
+		hash, _ := new(big.Int).SetString(req.TransactionHash, 0)
+		key, _ := new(big.Int).SetString(stubPrivateKey, 0)
+		r, s, _ := curve.Sign(hash, key)
+		resp["signature"] = []string{utils.BigToHex(r), utils.BigToHex(s)}
+	}
+	data, _ := json.Marshal(resp)
+	return append(data, '\n')
+}
+
+func stubPublicKey(t *testing.T) string {
+	t.Helper()
+	local, err := signer.NewLocalSignerFromHex(stubPrivateKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return local.PublicKey()
+}
+
+// stubCommand runs the test binary as the signer, with an argument containing a space
+func stubCommand(t *testing.T) []string {
+	t.Helper()
+	t.Setenv(stubEnv, "1")
+	return []string{os.Args[0], "--profile", "prod env"}
+}
+
+func invokeRequest(entrypoint string) *signer.SignRequest {
+	return &signer.SignRequest{
+		Network:         "testnet",
+		Type:            signer.TxTypeInvoke,
+		TransactionHash: "0x1234",
+		Calls:           []signer.Call{{ContractAddress: "0x99", Entrypoint: entrypoint}},
+	}
+}
+
+func TestCommandSigner(t *testing.T) {
+	publicKey := stubPublicKey(t)
+	s, err := signer.NewCommandSigner(publicKey, stubCommand(t), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	signature, err := s.SignTransaction(t.Context(), invokeRequest("engrave"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, _ := utils.HexToFelt("0x1234")
+	if err := signer.Verify(publicKey, hash, signature); err != nil {
+		t.Errorf("signature: %v", err)
+	}
+
+	if _, err := s.SignTransaction(t.Context(), invokeRequest("rug")); err == nil || !strings.Contains(err.Error(), "rejected by security") {
+		t.Errorf("rejected request: err = %v", err)
+	}
+	if _, err := s.SignTransaction(t.Context(), invokeRequest("unsigned")); err == nil || !strings.Contains(err.Error(), "expected a 2 element (r, s) signature, got 0") {
+		t.Errorf("approval without a signature: err = %v", err)
+	}
+
+	// A request left unanswered times out and the process is restarted for the next one
+	hang := invokeRequest("engrave")
+	hang.TransactionHash = hangHash
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := s.SignTransaction(ctx, hang); err == nil || !strings.Contains(err.Error(), "waiting for signer approval") {
+		t.Errorf("unanswered request: err = %v", err)
+	}
+	if _, err := s.SignTransaction(t.Context(), invokeRequest("engrave")); err != nil {
+		t.Errorf("request after restart: %v", err)
+	}
+}
+
+func TestCommandSignerRejectsForeignSignature(t *testing.T) {
+	// The stub signs with another key than the one configured
+	other, err := signer.NewLocalSignerFromHex("0x2", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := signer.NewCommandSigner(other.PublicKey(), stubCommand(t), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if _, err := s.SignTransaction(t.Context(), invokeRequest("engrave")); err == nil || !strings.Contains(err.Error(), "does not match public key") {
+		t.Errorf("err = %v, want a signature mismatch", err)
+	}
+}
+
+func TestSocketSigner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signer.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	requests := make(chan map[string]any, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			line, _ := bufio.NewReader(conn).ReadBytes('\n')
+			var req map[string]any
+			json.Unmarshal(line, &req)
+			requests <- req
+			// Socket signers are spoken to once per connection
+			conn.Write(stubResponse(line, nil))
+			conn.Close()
+		}
+	}()
+
+	publicKey := stubPublicKey(t)
+	s, err := signer.NewSocketSigner(publicKey, path, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entrypoint := range []string{"engrave", "mint"} {
+		if _, err := s.SignTransaction(t.Context(), invokeRequest(entrypoint)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.SignTransaction(t.Context(), invokeRequest("rug")); err == nil {
+		t.Error("rejected request was signed")
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("requests = %d, want 3", len(requests))
+	}
+	first, second := <-requests, <-requests
+	if first["id"] == second["id"] || first["public_key"] != publicKey || first["type"] != "INVOKE" ||
+		first["transaction_hash"] != "0x1234" || first["version"] != float64(signer.ProtocolVersion) {
+		t.Errorf("requests = %v, %v", first, second)
+	}
+}
@@ -0,0 +1,78 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/curve"
+	"github.com/NethermindEth/starknet.go/utils"
+)
+
+// LocalSigner signs with a private key held in memory
+type LocalSigner struct {
+	privateKey *big.Int
+	publicKey  string
+}
+
+// NewLocalSigner creates a signer from a private key, deriving the public key when empty
+func NewLocalSigner(privateKey *big.Int, publicKey string) (*LocalSigner, error) {
+	pubX, _ := curve.PrivateKeyToPoint(privateKey)
+	derived := utils.BigIntToFelt(pubX)
+
+	if publicKey == "" {
+		publicKey = derived.String()
+	} else {
+		configured, err := utils.HexToFelt(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		if !configured.Equal(derived) {
+			return nil, fmt.Errorf("private key does not match the configured public key %s", publicKey)
+		}
+	}
+
+	return &LocalSigner{
+		privateKey: privateKey,
+		publicKey:  publicKey,
+	}, nil
+}
+
+// NewLocalSignerFromHex creates a signer from a hex encoded private key
+func NewLocalSignerFromHex(privateKey, publicKey string) (*LocalSigner, error) {
+	privKeyBI, ok := new(big.Int).SetString(privateKey, 0)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert private key to big.Int")
+	}
+	return NewLocalSigner(privKeyBI, publicKey)
+}
+
+// PublicKey returns the signer public key
+func (s *LocalSigner) PublicKey() string {
+	return s.publicKey
+}
+
+// SignTransaction signs the transaction hash with the in-memory key
+func (s *LocalSigner) SignTransaction(ctx context.Context, req *SignRequest) ([]*felt.Felt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	msgHash, err := utils.HexToFelt(req.TransactionHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction hash: %w", err)
+	}
+
+	r, sig, err := curve.Sign(msgHash.BigInt(new(big.Int)), s.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	return []*felt.Felt{utils.BigIntToFelt(r), utils.BigIntToFelt(sig)}, nil
+}
+
+// Close is a no-op for local signers
+func (s *LocalSigner) Close() error {
+	return nil
+}
@@ -0,0 +1,89 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/starknet.go/curve"
+	"github.com/NethermindEth/starknet.go/rpc"
+	"github.com/NethermindEth/starknet.go/utils"
+)
+
+// TxType identifies the kind of transaction being signed
+type TxType string
+
+const (
+	TxTypeInvoke  TxType = "INVOKE"
+	TxTypeDeclare TxType = "DECLARE"
+)
+
+// Call is a decoded entry of an invoke multicall
+type Call struct {
+	ContractAddress string   `json:"contract_address"`
+	Entrypoint      string   `json:"entrypoint"`
+	Selector        string   `json:"selector"`
+	Calldata        []string `json:"calldata"`
+}
+
+// SignRequest describes a transaction to be approved and signed
+type SignRequest struct {
+	Network         string `json:"network"`
+	ChainID         string `json:"chain_id"`
+	Account         string `json:"account"`
+	Type            TxType `json:"type"`
+	TransactionHash string `json:"transaction_hash"`
+	Nonce           string `json:"nonce,omitempty"`
+	Calls           []Call `json:"calls,omitempty"`
+	ClassHash       string `json:"class_hash,omitempty"`
+}
+
+// Signer signs transaction hashes on behalf of the deployer account.
+// Implementations may sign locally or delegate approval and signing to another process.
+type Signer interface {
+	// PublicKey returns the Stark public key matching the signatures produced
+	PublicKey() string
+
+	// SignTransaction signs req.TransactionHash and returns the account signature
+	SignTransaction(ctx context.Context, req *SignRequest) ([]*felt.Felt, error)
+
+	// Close releases any resources held by the signer
+	Close() error
+}
+
+// DecodeCalls converts invoke function calls into their signable, human readable form
+func DecodeCalls(calls []rpc.InvokeFunctionCall) []Call {
+	decoded := make([]Call, len(calls))
+	for i, call := range calls {
+		decoded[i] = Call{
+			ContractAddress: call.ContractAddress.String(),
+			Entrypoint:      call.FunctionName,
+			Selector:        utils.GetSelectorFromNameFelt(call.FunctionName).String(),
+			Calldata:        utils.FeltArrToStringArr(call.CallData),
+		}
+	}
+	return decoded
+}
+
+// Verify checks an (r, s) signature of msgHash against a Stark public key.
+// Signatures of any other length are rejected.
+func Verify(publicKey string, msgHash *felt.Felt, signature []*felt.Felt) error {
+	if len(signature) != 2 {
+		return fmt.Errorf("expected a 2 element (r, s) signature, got %d elements", len(signature))
+	}
+
+	pubKey, err := utils.HexToFelt(publicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	ok, err := curve.VerifyFelts(msgHash, signature[0], signature[1], pubKey)
+	if err != nil {
+		return fmt.Errorf("failed to verify signature: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("signature does not match public key %s", publicKey)
+	}
+
+	return nil
+}